@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"runtime"
 
 	"github.com/docker/cli/cli"
@@ -12,8 +13,11 @@ import (
 	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/cli/cli/config/credentials"
 	cliflags "github.com/docker/cli/cli/flags"
+	manifeststore "github.com/docker/cli/cli/manifest/store"
+	registryclient "github.com/docker/cli/cli/registry/client"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/api/types/versions"
 	"github.com/docker/docker/client"
 	dopts "github.com/docker/docker/opts"
@@ -41,6 +45,8 @@ type Cli interface {
 	SetIn(in *InStream)
 	ConfigFile() *configfile.ConfigFile
 	CredentialsStore(serverAddress string) credentials.Store
+	ManifestStore() manifeststore.Store
+	RegistryClient(insecure bool) registryclient.RegistryClient
 }
 
 // DockerCli is an instance the docker command line client.
@@ -142,6 +148,20 @@ func (cli *DockerCli) CredentialsStore(serverAddress string) credentials.Store {
 	return credentials.NewFileStore(cli.configFile)
 }
 
+// ManifestStore returns a store for local manifest lists
+func (cli *DockerCli) ManifestStore() manifeststore.Store {
+	return manifeststore.NewStore(filepath.Join(cliconfig.Dir(), "manifests"))
+}
+
+// RegistryClient returns a client for communicating with a Docker distribution
+// registry
+func (cli *DockerCli) RegistryClient(insecure bool) registryclient.RegistryClient {
+	resolver := func(ctx context.Context, index *registrytypes.IndexInfo) types.AuthConfig {
+		return ResolveAuthConfig(ctx, cli, index)
+	}
+	return registryclient.NewRegistryClient(resolver, UserAgent(), insecure)
+}
+
 // getConfiguredCredentialStore returns the credential helper configured for the
 // given registry, the default credsStore, or the empty string if neither are
 // configured.
@@ -8,6 +8,7 @@ import (
 	"github.com/docker/cli/cli/command/config"
 	"github.com/docker/cli/cli/command/container"
 	"github.com/docker/cli/cli/command/image"
+	"github.com/docker/cli/cli/command/manifest"
 	"github.com/docker/cli/cli/command/network"
 	"github.com/docker/cli/cli/command/node"
 	"github.com/docker/cli/cli/command/plugin"
@@ -33,11 +34,16 @@ func AddCommands(cmd *cobra.Command, dockerCli *command.DockerCli) {
 		// container
 		container.NewContainerCommand(dockerCli),
 		container.NewRunCommand(dockerCli),
+		container.NewReplayCommand(dockerCli),
+		container.NewProfileCommand(dockerCli),
 
 		// image
 		image.NewImageCommand(dockerCli),
 		image.NewBuildCommand(dockerCli),
 
+		// manifest
+		manifest.NewManifestCommand(dockerCli),
+
 		// node
 		node.NewNodeCommand(dockerCli),
 
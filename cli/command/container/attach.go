@@ -18,6 +18,7 @@ type attachOptions struct {
 	noStdin    bool
 	proxy      bool
 	detachKeys string
+	record     string
 
 	container string
 }
@@ -40,6 +41,7 @@ func NewAttachCommand(dockerCli *command.DockerCli) *cobra.Command {
 	flags.BoolVar(&opts.noStdin, "no-stdin", false, "Do not attach STDIN")
 	flags.BoolVar(&opts.proxy, "sig-proxy", true, "Proxy all received signals to the process")
 	flags.StringVar(&opts.detachKeys, "detach-keys", "", "Override the key sequence for detaching a container")
+	addRecordFlag(flags, &opts.record)
 	return cmd
 }
 
@@ -95,6 +97,12 @@ func runAttach(dockerCli *command.DockerCli, opts *attachOptions) error {
 	}
 	defer resp.Close()
 
+	recorder, err := startRecording(dockerCli, opts.record, "docker attach "+opts.container)
+	if err != nil {
+		return err
+	}
+	defer recorder.Close()
+
 	if c.Config.Tty && dockerCli.Out().IsTerminal() {
 		height, width := dockerCli.Out().GetTtySize()
 		// To handle the case where a user repeatedly attaches/detaches without resizing their
@@ -105,11 +113,11 @@ func runAttach(dockerCli *command.DockerCli, opts *attachOptions) error {
 
 		// After the above resizing occurs, the call to MonitorTtySize below will handle resetting back
 		// to the actual size.
-		if err := MonitorTtySize(ctx, dockerCli, opts.container, false); err != nil {
+		if err := monitorTtySize(ctx, dockerCli, opts.container, false, recorder.resize); err != nil {
 			logrus.Debugf("Error monitoring TTY size: %s", err)
 		}
 	}
-	if err := holdHijackedConnection(ctx, dockerCli, c.Config.Tty, in, dockerCli.Out(), dockerCli.Err(), resp); err != nil {
+	if err := holdHijackedConnection(ctx, dockerCli, c.Config.Tty, in, recorder.output(dockerCli.Out()), recorder.output(dockerCli.Err()), resp); err != nil {
 		return err
 	}
 
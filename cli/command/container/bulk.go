@@ -0,0 +1,118 @@
+package container
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/opts"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/net/context"
+)
+
+// defaultParallel is the default number of containers operated on at once.
+const defaultParallel = 50
+
+// bulkOptions are the options of the commands operating on several
+// containers, selected by name or with --filter.
+type bulkOptions struct {
+	filter      opts.FilterOpt
+	yes         bool
+	maxParallel int
+}
+
+func newBulkOptions() bulkOptions {
+	return bulkOptions{filter: opts.NewFilterOpt()}
+}
+
+// addBulkFlags adds the flags selecting containers with a filter, and
+// bounding the number of containers operated on at once.
+func addBulkFlags(flags *pflag.FlagSet, opts *bulkOptions) {
+	flags.Var(&opts.filter, "filter", "Filter containers based on conditions provided")
+	flags.BoolVarP(&opts.yes, "yes", "y", false, "Do not prompt for confirmation of the containers matching --filter")
+	flags.IntVar(&opts.maxParallel, "max-parallel", defaultParallel, "Maximum number of containers to operate on at once")
+}
+
+// requiresContainers returns an Args validator requiring at least one
+// container, unless containers are selected with --filter.
+func (o *bulkOptions) requiresContainers() cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if o.filter.Value().Len() == 0 {
+			return cli.RequiresMinArgs(1)(cmd, args)
+		}
+		return nil
+	}
+}
+
+// selectContainers returns the containers given by name, followed by those
+// matching --filter. The containers matching the filter are listed and must
+// be confirmed, unless --yes is set; action describes the operation in the
+// confirmation prompt. Stopped containers are only matched if all is set.
+// An empty list is returned if the operation was not confirmed.
+func (o *bulkOptions) selectContainers(ctx context.Context, dockerCli command.Cli, names []string, all bool, action string) ([]string, error) {
+	if o.maxParallel < 1 {
+		return nil, errors.Errorf("invalid value %d for --max-parallel: must be at least 1", o.maxParallel)
+	}
+	if o.filter.Value().Len() == 0 {
+		return names, nil
+	}
+
+	list, err := dockerCli.Client().ContainerList(ctx, types.ContainerListOptions{
+		All:     all,
+		Filters: o.filter.Value(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	selected := append([]string{}, names...)
+	seen := make(map[string]bool)
+	for _, name := range names {
+		seen[name] = true
+	}
+	var matched []types.Container
+	for _, c := range list {
+		name := listedContainerName(c)
+		if seen[name] || seen[c.ID] {
+			continue
+		}
+		seen[name] = true
+		selected = append(selected, name)
+		matched = append(matched, c)
+	}
+
+	if len(matched) == 0 || o.yes {
+		return selected, nil
+	}
+	if !command.PromptForConfirmation(dockerCli.In(), dockerCli.Out(), confirmationMessage(action, matched)) {
+		return nil, nil
+	}
+	return selected, nil
+}
+
+// confirmationMessage lists the containers matching a filter, and asks for
+// the confirmation of the operation on them.
+func confirmationMessage(action string, containers []types.Container) string {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "WARNING! This will %s the following containers matching the filter:\n", action)
+	for _, c := range containers {
+		fmt.Fprintf(&message, "  %s  %s (%s)\n", stringid.TruncateID(c.ID), listedContainerName(c), c.Status)
+	}
+	message.WriteString("Are you sure you want to continue?")
+	return message.String()
+}
+
+// listedContainerName returns the name of a container returned by
+// ContainerList, or its ID if it has none.
+func listedContainerName(c types.Container) string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
@@ -0,0 +1,118 @@
+package container
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func newTestBulkOptions(t *testing.T, filters ...string) bulkOptions {
+	opts := newBulkOptions()
+	opts.maxParallel = defaultParallel
+	for _, f := range filters {
+		require.NoError(t, opts.filter.Set(f))
+	}
+	return opts
+}
+
+func TestSelectContainersWithoutFilter(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{}, new(bytes.Buffer))
+	opts := newTestBulkOptions(t)
+
+	containers, err := opts.selectContainers(context.Background(), cli, []string{"web", "db"}, false, "stop")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web", "db"}, containers)
+
+	opts.maxParallel = 0
+	_, err = opts.selectContainers(context.Background(), cli, []string{"web"}, false, "stop")
+	assert.EqualError(t, err, "invalid value 0 for --max-parallel: must be at least 1")
+}
+
+func TestSelectContainersWithFilter(t *testing.T) {
+	client := &fakeClient{
+		containerListFunc: func(options types.ContainerListOptions) ([]types.Container, error) {
+			assert.True(t, options.All)
+			assert.Equal(t, []string{"app=web"}, options.Filters.Get("label"))
+			return []types.Container{
+				{ID: "1111111111111111", Names: []string{"/web"}, Status: "Up 2 hours"},
+				{ID: "2222222222222222", Names: []string{"/web-2"}, Status: "Exited (0) 1 hour ago"},
+			}, nil
+		},
+	}
+
+	testCases := []struct {
+		answer   string
+		yes      bool
+		expected []string
+		prompted bool
+	}{
+		{answer: "y\n", expected: []string{"web", "web-2"}, prompted: true},
+		{answer: "n\n", prompted: true},
+		{yes: true, expected: []string{"web", "web-2"}},
+	}
+	for _, tc := range testCases {
+		out := new(bytes.Buffer)
+		cli := test.NewFakeCli(client, out)
+		cli.SetIn(command.NewInStream(ioutil.NopCloser(strings.NewReader(tc.answer))))
+		opts := newTestBulkOptions(t, "label=app=web")
+		opts.yes = tc.yes
+
+		containers, err := opts.selectContainers(context.Background(), cli, []string{"web"}, true, "remove")
+		require.NoError(t, err)
+		if tc.expected == nil {
+			assert.Empty(t, containers)
+		} else {
+			assert.Equal(t, tc.expected, containers)
+		}
+		if tc.prompted {
+			// Containers given by name are not confirmed.
+			assert.Contains(t, out.String(), "WARNING! This will remove the following containers matching the filter:\n  222222222222  web-2 (Exited (0) 1 hour ago)\n")
+			assert.NotContains(t, out.String(), "111111111111")
+		} else {
+			assert.Empty(t, out.String())
+		}
+	}
+}
+
+func TestParallelOperationMaxParallel(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		running int
+		max     int
+	)
+	op := func(ctx context.Context, container string) error {
+		mu.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if container == "c" {
+			return assert.AnError
+		}
+		return nil
+	}
+
+	containers := []string{"a", "b", "c", "d", "e"}
+	errChan := parallelOperation(context.Background(), containers, 2, op)
+	var errs []error
+	for range containers {
+		errs = append(errs, <-errChan)
+	}
+	assert.Equal(t, []error{nil, nil, assert.AnError, nil, nil}, errs)
+	assert.Equal(t, 2, max)
+}
@@ -0,0 +1,74 @@
+package container
+
+import (
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/client"
+	"golang.org/x/net/context"
+)
+
+type fakeClient struct {
+	client.Client
+	containerListFunc     func(options types.ContainerListOptions) ([]types.Container, error)
+	containerStatPathFunc func(container, path string) (types.ContainerPathStat, error)
+	copyFromContainerFunc func(container, srcPath string) (io.ReadCloser, types.ContainerPathStat, error)
+	copyToContainerFunc   func(container, path string, content io.Reader, options types.CopyToContainerOptions) error
+	containerInspectFunc  func(container string) (types.ContainerJSON, error)
+	eventsFunc            func(options types.EventsOptions) (<-chan events.Message, <-chan error)
+	containerLogsFunc     func(container string, options types.ContainerLogsOptions) (io.ReadCloser, error)
+}
+
+func (cli *fakeClient) ContainerList(_ context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	if cli.containerListFunc != nil {
+		return cli.containerListFunc(options)
+	}
+	return nil, nil
+}
+
+func (cli *fakeClient) ContainerStatPath(_ context.Context, container, path string) (types.ContainerPathStat, error) {
+	if cli.containerStatPathFunc != nil {
+		return cli.containerStatPathFunc(container, path)
+	}
+	return types.ContainerPathStat{}, nil
+}
+
+func (cli *fakeClient) CopyFromContainer(_ context.Context, container, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+	if cli.copyFromContainerFunc != nil {
+		return cli.copyFromContainerFunc(container, srcPath)
+	}
+	return nil, types.ContainerPathStat{}, nil
+}
+
+func (cli *fakeClient) CopyToContainer(_ context.Context, container, path string, content io.Reader, options types.CopyToContainerOptions) error {
+	if cli.copyToContainerFunc != nil {
+		return cli.copyToContainerFunc(container, path, content, options)
+	}
+	return nil
+}
+
+func (cli *fakeClient) ContainerInspect(_ context.Context, container string) (types.ContainerJSON, error) {
+	if cli.containerInspectFunc != nil {
+		return cli.containerInspectFunc(container)
+	}
+	return types.ContainerJSON{}, nil
+}
+
+func (cli *fakeClient) Events(_ context.Context, options types.EventsOptions) (<-chan events.Message, <-chan error) {
+	if cli.eventsFunc != nil {
+		return cli.eventsFunc(options)
+	}
+	return nil, nil
+}
+
+func (cli *fakeClient) ContainerLogs(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	// Like the requests of the real client, fail if the context is done.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if cli.containerLogsFunc != nil {
+		return cli.containerLogsFunc(container, options)
+	}
+	return nil, nil
+}
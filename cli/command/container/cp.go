@@ -2,6 +2,7 @@ package container
 
 import (
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/fileutils"
 	"github.com/docker/docker/pkg/system"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -21,6 +23,8 @@ type copyOptions struct {
 	destination string
 	followLink  bool
 	copyUIDGID  bool
+	excludes    []string
+	dryRun      bool
 }
 
 type copyDirection int
@@ -33,22 +37,31 @@ const (
 
 type cpConfig struct {
 	followLink bool
+	excludes   *fileutils.PatternMatcher
+	dryRun     bool
+	progress   *copyProgress
 }
 
 // NewCopyCommand creates a new `docker cp` command
-func NewCopyCommand(dockerCli *command.DockerCli) *cobra.Command {
+func NewCopyCommand(dockerCli command.Cli) *cobra.Command {
 	var opts copyOptions
 
 	cmd := &cobra.Command{
 		Use: `cp [OPTIONS] CONTAINER:SRC_PATH DEST_PATH|-
-	docker cp [OPTIONS] SRC_PATH|- CONTAINER:DEST_PATH`,
+	docker cp [OPTIONS] SRC_PATH|- CONTAINER:DEST_PATH
+	docker cp [OPTIONS] CONTAINER:SRC_PATH CONTAINER:DEST_PATH`,
 		Short: "Copy files/folders between a container and the local filesystem",
 		Long: strings.Join([]string{
-			"Copy files/folders between a container and the local filesystem\n",
+			"Copy files/folders between a container and the local filesystem,\n",
+			"or directly between two containers\n",
 			"\nUse '-' as the source to read a tar archive from stdin\n",
 			"and extract it to a directory destination in a container.\n",
 			"Use '-' as the destination to stream a tar archive of a\n",
-			"container source to stdout.",
+			"container source to stdout.\n",
+			"\nThe last element of a container SRC_PATH may contain wildcards\n",
+			"to copy all the matching files/folders to a directory. As the API\n",
+			"cannot list a directory, the whole parent directory of the pattern\n",
+			"is read from the container to find the matches.",
 		}, ""),
 		Args: cli.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -68,11 +81,13 @@ func NewCopyCommand(dockerCli *command.DockerCli) *cobra.Command {
 
 	flags.BoolVarP(&opts.followLink, "follow-link", "L", false, "Always follow symbol link in SRC_PATH")
 	flags.BoolVarP(&opts.copyUIDGID, "archive", "a", false, "Archive mode (copy all uid/gid information)")
+	flags.StringArrayVar(&opts.excludes, "exclude", nil, "Exclude files matching a pattern, as in .dockerignore")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "List the files that would be copied, without copying them")
 
 	return cmd
 }
 
-func runCopy(dockerCli *command.DockerCli, opts copyOptions) error {
+func runCopy(dockerCli command.Cli, opts copyOptions) error {
 	srcContainer, srcPath := splitCpArg(opts.source)
 	dstContainer, dstPath := splitCpArg(opts.destination)
 
@@ -86,25 +101,51 @@ func runCopy(dockerCli *command.DockerCli, opts copyOptions) error {
 
 	cpParam := &cpConfig{
 		followLink: opts.followLink,
+		dryRun:     opts.dryRun,
+	}
+	if len(opts.excludes) > 0 {
+		excludes, err := fileutils.NewPatternMatcher(opts.excludes)
+		if err != nil {
+			return errors.Wrap(err, "invalid exclude pattern")
+		}
+		cpParam.excludes = excludes
+	}
+	// Report progress unless stdout is used for the archive itself.
+	if !opts.dryRun && dstPath != "-" && dockerCli.Out().IsTerminal() {
+		cpParam.progress = newCopyProgress(dockerCli.Out())
 	}
 
 	ctx := context.Background()
 
+	var err error
 	switch direction {
 	case fromContainer:
-		return copyFromContainer(ctx, dockerCli, srcContainer, srcPath, dstPath, cpParam)
+		err = copyFromContainer(ctx, dockerCli, srcContainer, srcPath, dstPath, cpParam)
 	case toContainer:
-		return copyToContainer(ctx, dockerCli, srcPath, dstContainer, dstPath, cpParam, opts.copyUIDGID)
+		err = copyToContainer(ctx, dockerCli, srcPath, dstContainer, dstPath, cpParam, opts.copyUIDGID)
 	case acrossContainers:
-		// Copying between containers isn't supported.
-		return errors.New("copying between containers is not supported")
+		err = copyAcrossContainers(ctx, dockerCli, srcContainer, srcPath, dstContainer, dstPath, cpParam, opts.copyUIDGID)
 	default:
 		// User didn't specify any container.
 		return errors.New("must specify at least one container source")
 	}
+
+	if cpParam.progress != nil {
+		cpParam.progress.finish(err)
+	}
+	return err
 }
 
-func statContainerPath(ctx context.Context, dockerCli *command.DockerCli, containerName, path string) (types.ContainerPathStat, error) {
+// filterArchive applies the exclusions and progress reporting of the copy to
+// the archive content, whose entries are rooted at root.
+func (c *cpConfig) filterArchive(content io.Reader, root string) io.ReadCloser {
+	if c.excludes == nil && c.progress == nil {
+		return ioutil.NopCloser(content)
+	}
+	return rewriteArchive(content, excludeFilter(root, c.excludes), c.progress)
+}
+
+func statContainerPath(ctx context.Context, dockerCli command.Cli, containerName, path string) (types.ContainerPathStat, error) {
 	return dockerCli.Client().ContainerStatPath(ctx, containerName, path)
 }
 
@@ -116,7 +157,61 @@ func resolveLocalPath(localPath string) (absPath string, err error) {
 	return archive.PreserveTrailingDotOrSeparator(absPath, localPath), nil
 }
 
-func copyFromContainer(ctx context.Context, dockerCli *command.DockerCli, srcContainer, srcPath, dstPath string, cpParam *cpConfig) (err error) {
+// resolveContainerSourcePath returns the path to copy from a container. If
+// followLink is set and srcPath is a symbolic link, the link target is
+// returned along with the name the copied resource must be rebased to.
+func resolveContainerSourcePath(ctx context.Context, dockerCli command.Cli, srcContainer, srcPath string, followLink bool) (resolvedPath, rebaseName string) {
+	if !followLink {
+		return srcPath, ""
+	}
+	srcStat, err := statContainerPath(ctx, dockerCli, srcContainer, srcPath)
+
+	// If the source is a symbolic link, we should follow it.
+	if err == nil && srcStat.Mode&os.ModeSymlink != 0 {
+		linkTarget := srcStat.LinkTarget
+		if !system.IsAbs(linkTarget) {
+			// Join with the parent directory.
+			srcParent, _ := archive.SplitPathDirEntry(srcPath)
+			linkTarget = filepath.Join(srcParent, linkTarget)
+		}
+
+		return archive.GetRebaseName(srcPath, linkTarget)
+	}
+	return srcPath, ""
+}
+
+// containerDestinationInfo returns the copy info of a destination path in a
+// container, evaluating it if it is a symbolic link.
+func containerDestinationInfo(ctx context.Context, dockerCli command.Cli, dstContainer, dstPath string) archive.CopyInfo {
+	dstInfo := archive.CopyInfo{Path: dstPath}
+	dstStat, err := statContainerPath(ctx, dockerCli, dstContainer, dstPath)
+
+	// If the destination is a symbolic link, we should evaluate it.
+	if err == nil && dstStat.Mode&os.ModeSymlink != 0 {
+		linkTarget := dstStat.LinkTarget
+		if !system.IsAbs(linkTarget) {
+			// Join with the parent directory.
+			dstParent, _ := archive.SplitPathDirEntry(dstPath)
+			linkTarget = filepath.Join(dstParent, linkTarget)
+		}
+
+		dstInfo.Path = linkTarget
+		dstStat, err = statContainerPath(ctx, dockerCli, dstContainer, linkTarget)
+	}
+
+	// Ignore any error and assume that the parent directory of the destination
+	// path exists, in which case the copy may still succeed. If there is any
+	// type of conflict (e.g., non-directory overwriting an existing directory
+	// or vice versa) the extraction will fail. If the destination simply did
+	// not exist, but the parent directory does, the extraction will still
+	// succeed.
+	if err == nil {
+		dstInfo.Exists, dstInfo.IsDir = true, dstStat.Mode.IsDir()
+	}
+	return dstInfo
+}
+
+func copyFromContainer(ctx context.Context, dockerCli command.Cli, srcContainer, srcPath, dstPath string, cpParam *cpConfig) (err error) {
 	if dstPath != "-" {
 		// Get an absolute destination path.
 		dstPath, err = resolveLocalPath(dstPath)
@@ -125,33 +220,28 @@ func copyFromContainer(ctx context.Context, dockerCli *command.DockerCli, srcCon
 		}
 	}
 
-	// if client requests to follow symbol link, then must decide target file to be copied
-	var rebaseName string
-	if cpParam.followLink {
-		srcStat, err := statContainerPath(ctx, dockerCli, srcContainer, srcPath)
-
-		// If the destination is a symbolic link, we should follow it.
-		if err == nil && srcStat.Mode&os.ModeSymlink != 0 {
-			linkTarget := srcStat.LinkTarget
-			if !system.IsAbs(linkTarget) {
-				// Join with the parent directory.
-				srcParent, _ := archive.SplitPathDirEntry(srcPath)
-				linkTarget = filepath.Join(srcParent, linkTarget)
-			}
-
-			linkTarget, rebaseName = archive.GetRebaseName(srcPath, linkTarget)
-			srcPath = linkTarget
-		}
-
+	if isContainerPattern(ctx, dockerCli, srcContainer, srcPath) {
+		return copyMatchesFromContainer(ctx, dockerCli, srcContainer, srcPath, "", dstPath, cpParam, false)
 	}
 
-	content, stat, err := dockerCli.Client().CopyFromContainer(ctx, srcContainer, srcPath)
+	// if client requests to follow symbol link, then must decide target file to be copied
+	srcPath, rebaseName := resolveContainerSourcePath(ctx, dockerCli, srcContainer, srcPath, cpParam.followLink)
+
+	response, stat, err := dockerCli.Client().CopyFromContainer(ctx, srcContainer, srcPath)
 	if err != nil {
 		return err
 	}
+	defer response.Close()
+
+	_, srcBase := archive.SplitPathDirEntry(srcPath)
+	content := cpParam.filterArchive(response, srcBase)
 	defer content.Close()
 
 	if dstPath == "-" {
+		if cpParam.dryRun {
+			return listArchive(dockerCli.Out(), content, "", "")
+		}
+
 		// Send the response to STDOUT.
 		_, err = io.Copy(os.Stdout, content)
 
@@ -166,18 +256,31 @@ func copyFromContainer(ctx context.Context, dockerCli *command.DockerCli, srcCon
 		RebaseName: rebaseName,
 	}
 
-	preArchive := content
+	var preArchive io.ReadCloser = content
 	if len(srcInfo.RebaseName) != 0 {
-		_, srcBase := archive.SplitPathDirEntry(srcInfo.Path)
 		preArchive = archive.RebaseArchiveEntries(content, srcBase, srcInfo.RebaseName)
 	}
+
+	if cpParam.dryRun {
+		dstInfo, err := archive.CopyInfoDestinationPath(dstPath)
+		if err != nil {
+			return err
+		}
+		dstDir, copyArchive, err := archive.PrepareArchiveCopy(preArchive, srcInfo, dstInfo)
+		if err != nil {
+			return err
+		}
+		defer copyArchive.Close()
+		return listArchive(dockerCli.Out(), copyArchive, "", dstDir)
+	}
+
 	// See comments in the implementation of `archive.CopyTo` for exactly what
 	// goes into deciding how and whether the source archive needs to be
 	// altered for the correct copy behavior.
 	return archive.CopyTo(preArchive, srcInfo, dstPath)
 }
 
-func copyToContainer(ctx context.Context, dockerCli *command.DockerCli, srcPath, dstContainer, dstPath string, cpParam *cpConfig, copyUIDGID bool) (err error) {
+func copyToContainer(ctx context.Context, dockerCli command.Cli, srcPath, dstContainer, dstPath string, cpParam *cpConfig, copyUIDGID bool) (err error) {
 	if srcPath != "-" {
 		// Get an absolute source path.
 		srcPath, err = resolveLocalPath(srcPath)
@@ -192,31 +295,7 @@ func copyToContainer(ctx context.Context, dockerCli *command.DockerCli, srcPath,
 	// destination to be more informed about exactly what the destination is.
 
 	// Prepare destination copy info by stat-ing the container path.
-	dstInfo := archive.CopyInfo{Path: dstPath}
-	dstStat, err := statContainerPath(ctx, dockerCli, dstContainer, dstPath)
-
-	// If the destination is a symbolic link, we should evaluate it.
-	if err == nil && dstStat.Mode&os.ModeSymlink != 0 {
-		linkTarget := dstStat.LinkTarget
-		if !system.IsAbs(linkTarget) {
-			// Join with the parent directory.
-			dstParent, _ := archive.SplitPathDirEntry(dstPath)
-			linkTarget = filepath.Join(dstParent, linkTarget)
-		}
-
-		dstInfo.Path = linkTarget
-		dstStat, err = statContainerPath(ctx, dockerCli, dstContainer, linkTarget)
-	}
-
-	// Ignore any error and assume that the parent directory of the destination
-	// path exists, in which case the copy may still succeed. If there is any
-	// type of conflict (e.g., non-directory overwriting an existing directory
-	// or vice versa) the extraction will fail. If the destination simply did
-	// not exist, but the parent directory does, the extraction will still
-	// succeed.
-	if err == nil {
-		dstInfo.Exists, dstInfo.IsDir = true, dstStat.Mode.IsDir()
-	}
+	dstInfo := containerDestinationInfo(ctx, dockerCli, dstContainer, dstPath)
 
 	var (
 		content         io.Reader
@@ -225,7 +304,10 @@ func copyToContainer(ctx context.Context, dockerCli *command.DockerCli, srcPath,
 
 	if srcPath == "-" {
 		// Use STDIN.
-		content = os.Stdin
+		stdin := cpParam.filterArchive(os.Stdin, "")
+		defer stdin.Close()
+
+		content = stdin
 		resolvedDstPath = dstInfo.Path
 		if !dstInfo.IsDir {
 			return errors.Errorf("destination \"%s:%s\" must be a directory", dstContainer, dstPath)
@@ -237,10 +319,17 @@ func copyToContainer(ctx context.Context, dockerCli *command.DockerCli, srcPath,
 			return err
 		}
 
-		srcArchive, err := archive.TarResource(srcInfo)
+		tarResource, err := archive.TarResource(srcInfo)
 		if err != nil {
 			return err
 		}
+		defer tarResource.Close()
+
+		srcRoot := srcInfo.RebaseName
+		if srcRoot == "" {
+			_, srcRoot = archive.SplitPathDirEntry(srcInfo.Path)
+		}
+		srcArchive := cpParam.filterArchive(tarResource, srcRoot)
 		defer srcArchive.Close()
 
 		// With the stat info about the local source as well as the
@@ -265,6 +354,10 @@ func copyToContainer(ctx context.Context, dockerCli *command.DockerCli, srcPath,
 		content = preparedArchive
 	}
 
+	if cpParam.dryRun {
+		return listArchive(dockerCli.Out(), content, dstContainer, resolvedDstPath)
+	}
+
 	options := types.CopyToContainerOptions{
 		AllowOverwriteDirWithFile: false,
 		CopyUIDGID:                copyUIDGID,
@@ -273,6 +366,153 @@ func copyToContainer(ctx context.Context, dockerCli *command.DockerCli, srcPath,
 	return dockerCli.Client().CopyToContainer(ctx, dstContainer, resolvedDstPath, content, options)
 }
 
+// copyAcrossContainers streams the archive of a path in the source container
+// straight into the destination container, without storing it locally.
+func copyAcrossContainers(ctx context.Context, dockerCli command.Cli, srcContainer, srcPath, dstContainer, dstPath string, cpParam *cpConfig, copyUIDGID bool) error {
+	if isContainerPattern(ctx, dockerCli, srcContainer, srcPath) {
+		return copyMatchesFromContainer(ctx, dockerCli, srcContainer, srcPath, dstContainer, dstPath, cpParam, copyUIDGID)
+	}
+
+	srcPath, rebaseName := resolveContainerSourcePath(ctx, dockerCli, srcContainer, srcPath, cpParam.followLink)
+	dstInfo := containerDestinationInfo(ctx, dockerCli, dstContainer, dstPath)
+
+	response, stat, err := dockerCli.Client().CopyFromContainer(ctx, srcContainer, srcPath)
+	if err != nil {
+		return err
+	}
+	defer response.Close()
+
+	_, srcBase := archive.SplitPathDirEntry(srcPath)
+	content := cpParam.filterArchive(response, srcBase)
+	defer content.Close()
+
+	srcInfo := archive.CopyInfo{
+		Path:       srcPath,
+		Exists:     true,
+		IsDir:      stat.Mode.IsDir(),
+		RebaseName: rebaseName,
+	}
+
+	// The archive is rewritten on the fly so that, once extracted in the
+	// destination container, it has the same layout as when copying through
+	// the local filesystem.
+	var preArchive io.ReadCloser = content
+	if len(srcInfo.RebaseName) != 0 {
+		preArchive = archive.RebaseArchiveEntries(content, srcBase, srcInfo.RebaseName)
+	}
+	dstDir, preparedArchive, err := archive.PrepareArchiveCopy(preArchive, srcInfo, dstInfo)
+	if err != nil {
+		return err
+	}
+	defer preparedArchive.Close()
+
+	if cpParam.dryRun {
+		return listArchive(dockerCli.Out(), preparedArchive, dstContainer, dstDir)
+	}
+
+	options := types.CopyToContainerOptions{
+		AllowOverwriteDirWithFile: false,
+		CopyUIDGID:                copyUIDGID,
+	}
+
+	return dockerCli.Client().CopyToContainer(ctx, dstContainer, dstDir, preparedArchive, options)
+}
+
+// isContainerPattern returns whether srcPath is a wildcard pattern, rather
+// than the literal name of a file in the container.
+func isContainerPattern(ctx context.Context, dockerCli command.Cli, srcContainer, srcPath string) bool {
+	if !strings.ContainsAny(srcPath, "*?[") {
+		return false
+	}
+	_, err := statContainerPath(ctx, dockerCli, srcContainer, srcPath)
+	return err != nil
+}
+
+// copyMatchesFromContainer copies the files/folders of a directory in the
+// source container whose name matches the pattern in the last element of
+// srcPattern into the directory dstPath, which is in container dstContainer
+// if it is set.
+func copyMatchesFromContainer(ctx context.Context, dockerCli command.Cli, srcContainer, srcPattern, dstContainer, dstPath string, cpParam *cpConfig, copyUIDGID bool) error {
+	srcDir, pattern := archive.SplitPathDirEntry(srcPattern)
+	if strings.ContainsAny(srcDir, "*?[") {
+		return errors.Errorf("wildcards are only supported in the last element of the source path: %s", srcPattern)
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return errors.Wrapf(err, "invalid source pattern %q", pattern)
+	}
+
+	// The parent directory may be a symbolic link, in which case the
+	// archive of its target is needed.
+	srcDir, _ = resolveContainerSourcePath(ctx, dockerCli, srcContainer, srcDir, true)
+	srcStat, err := statContainerPath(ctx, dockerCli, srcContainer, srcDir)
+	if err != nil {
+		return err
+	}
+	if !srcStat.Mode.IsDir() {
+		return errors.Errorf("\"%s:%s\" is not a directory", srcContainer, srcDir)
+	}
+
+	switch {
+	case dstContainer != "":
+		dstInfo := containerDestinationInfo(ctx, dockerCli, dstContainer, dstPath)
+		if !dstInfo.IsDir {
+			return errors.Errorf("destination \"%s:%s\" must be a directory", dstContainer, dstPath)
+		}
+		dstPath = dstInfo.Path
+	case dstPath != "-":
+		dstStat, err := os.Stat(dstPath)
+		if err != nil {
+			return err
+		}
+		if !dstStat.IsDir() {
+			return errors.Errorf("destination %q must be a directory", dstPath)
+		}
+	}
+
+	// The API can stat a path, but not list a directory: the matches can
+	// only be found in the archive of the whole directory. Listing them
+	// first, to then copy each of them, would read this archive once more,
+	// so the matches are copied as they are found in it instead.
+	response, _, err := dockerCli.Client().CopyFromContainer(ctx, srcContainer, srcDir)
+	if err != nil {
+		return err
+	}
+	defer response.Close()
+
+	var matched int
+	_, srcBase := archive.SplitPathDirEntry(srcDir)
+	content := rewriteArchive(response, matchFilter(srcBase, pattern, cpParam.excludes, &matched), cpParam.progress)
+	defer content.Close()
+
+	switch {
+	case cpParam.dryRun:
+		if dstPath == "-" {
+			dstPath = ""
+		}
+		err = listArchive(dockerCli.Out(), content, dstContainer, dstPath)
+	case dstContainer != "":
+		options := types.CopyToContainerOptions{
+			AllowOverwriteDirWithFile: false,
+			CopyUIDGID:                copyUIDGID,
+		}
+		err = dockerCli.Client().CopyToContainer(ctx, dstContainer, dstPath, content, options)
+	case dstPath == "-":
+		_, err = io.Copy(os.Stdout, content)
+	default:
+		err = archive.Untar(content, dstPath, &archive.TarOptions{
+			NoLchown:             true,
+			NoOverwriteDirNonDir: true,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return errors.Errorf("no such file or directory: \"%s:%s\"", srcContainer, srcPattern)
+	}
+	return nil
+}
+
 // We use `:` as a delimiter between CONTAINER and PATH, but `:` could also be
 // in a valid LOCALPATH, like `file:name.txt`. We can resolve this ambiguity by
 // requiring a LOCALPATH with a `:` to be made explicit with a relative or
@@ -0,0 +1,229 @@
+package container
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/docker/pkg/fileutils"
+	units "github.com/docker/go-units"
+)
+
+// progressInterval is the minimum interval between two updates of the copy
+// progress.
+const progressInterval = 100 * time.Millisecond
+
+// entryFilter decides whether an archive entry is copied, and may rename it.
+type entryFilter func(hdr *tar.Header) (bool, error)
+
+// rewriteArchive returns an archive with the entries of content that are
+// kept by filter. The entries are counted in progress, if not nil.
+func rewriteArchive(content io.Reader, filter entryFilter, progress *copyProgress) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		tr := tar.NewReader(content)
+		tw := tar.NewWriter(pw)
+		err := func() error {
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					return tw.Close()
+				}
+				if err != nil {
+					return err
+				}
+				keep, err := filter(hdr)
+				if err != nil {
+					return err
+				}
+				if !keep {
+					continue
+				}
+				if err := tw.WriteHeader(hdr); err != nil {
+					return err
+				}
+				var w io.Writer = tw
+				if progress != nil {
+					// Only regular files are counted, as by listArchive.
+					if hdr.Typeflag == tar.TypeReg || hdr.Typeflag == tar.TypeRegA {
+						progress.addFile()
+					}
+					w = &progressWriter{Writer: tw, progress: progress}
+				}
+				if _, err := io.Copy(w, tr); err != nil {
+					return err
+				}
+			}
+		}()
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// archiveRelativePath returns the path of an archive entry relative to root,
+// the name of the copied resource in the archive. The root itself has an
+// empty relative path.
+func archiveRelativePath(name, root string) string {
+	name = strings.TrimSuffix(name, "/")
+	if root == "" || root == "/" || root == "." {
+		return name
+	}
+	if name == root {
+		return ""
+	}
+	return strings.TrimPrefix(name, root+"/")
+}
+
+func isExcluded(excludes *fileutils.PatternMatcher, relPath string) (bool, error) {
+	if excludes == nil || relPath == "" {
+		return false, nil
+	}
+	return excludes.Matches(relPath)
+}
+
+// excludeFilter skips the entries under root matching excludes.
+func excludeFilter(root string, excludes *fileutils.PatternMatcher) entryFilter {
+	return func(hdr *tar.Header) (bool, error) {
+		excluded, err := isExcluded(excludes, archiveRelativePath(hdr.Name, root))
+		return !excluded, err
+	}
+}
+
+// matchFilter keeps the entries of the directory root whose name matches
+// pattern, and that are not excluded, and moves them to the top of the
+// archive.
+func matchFilter(root, pattern string, excludes *fileutils.PatternMatcher, matched *int) entryFilter {
+	return func(hdr *tar.Header) (bool, error) {
+		relPath := archiveRelativePath(hdr.Name, root)
+		if relPath == "" {
+			return false, nil
+		}
+		entry := strings.SplitN(relPath, "/", 2)[0]
+		if ok, err := filepath.Match(pattern, entry); !ok || err != nil {
+			return false, err
+		}
+		if excluded, err := isExcluded(excludes, relPath); excluded || err != nil {
+			return false, err
+		}
+		if entry == relPath {
+			*matched++
+		}
+
+		if strings.HasSuffix(hdr.Name, "/") {
+			relPath += "/"
+		}
+		hdr.Name = relPath
+		if hdr.Typeflag == tar.TypeLink {
+			hdr.Linkname = archiveRelativePath(hdr.Linkname, root)
+		}
+		return true, nil
+	}
+}
+
+// copyProgress reports the number of files and bytes copied on a terminal.
+type copyProgress struct {
+	mu         sync.Mutex
+	out        io.Writer
+	files      int
+	bytes      int64
+	lastUpdate time.Time
+}
+
+func newCopyProgress(out io.Writer) *copyProgress {
+	return &copyProgress{out: out}
+}
+
+func (p *copyProgress) addFile() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files++
+	p.update()
+}
+
+func (p *copyProgress) addBytes(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bytes += int64(n)
+	p.update()
+}
+
+func (p *copyProgress) update() {
+	if time.Since(p.lastUpdate) < progressInterval {
+		return
+	}
+	p.lastUpdate = time.Now()
+	fmt.Fprintf(p.out, "\r\033[2KCopying: %s", p.summary())
+}
+
+// finish prints the final number of files and bytes copied, or the number
+// copied before the copy failed with err.
+func (p *copyProgress) finish(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		fmt.Fprintf(p.out, "\r\033[2KCopy failed after %s\n", p.summary())
+		return
+	}
+	fmt.Fprintf(p.out, "\r\033[2KCopied %s\n", p.summary())
+}
+
+func (p *copyProgress) summary() string {
+	return fmt.Sprintf("%d files, %s", p.files, units.HumanSize(float64(p.bytes)))
+}
+
+type progressWriter struct {
+	io.Writer
+	progress *copyProgress
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	n, err := w.Writer.Write(b)
+	w.progress.addBytes(n)
+	return n, err
+}
+
+// listArchive prints the entries of content as they would be extracted to
+// dstDir, in container dstContainer if it is set, instead of copying them.
+func listArchive(out io.Writer, content io.Reader, dstContainer, dstDir string) error {
+	var (
+		files int
+		size  int64
+	)
+
+	w := tabwriter.NewWriter(out, 20, 1, 3, ' ', 0)
+	tr := tar.NewReader(content)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		dst := path.Join(dstDir, hdr.Name)
+		if dstContainer != "" {
+			dst = dstContainer + ":" + dst
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			fmt.Fprintf(w, "%s/\n", dst)
+		case tar.TypeReg, tar.TypeRegA:
+			files++
+			size += hdr.Size
+			fmt.Fprintf(w, "%s\t%s\n", dst, units.HumanSize(float64(hdr.Size)))
+		default:
+			fmt.Fprintf(w, "%s\n", dst)
+		}
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "%d files, %s would be copied\n", files, units.HumanSize(float64(size)))
+	return nil
+}
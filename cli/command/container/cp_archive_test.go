@@ -0,0 +1,97 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testArchive(t *testing.T, names ...string) io.Reader {
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, name := range names {
+		hdr := &tar.Header{Name: name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(name))}
+		if name[len(name)-1] == '/' {
+			hdr.Typeflag, hdr.Mode, hdr.Size = tar.TypeDir, 0755, 0
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		if hdr.Typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(name))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	return buf
+}
+
+func archiveNames(t *testing.T, content io.Reader) []string {
+	var names []string
+	tr := tar.NewReader(content)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return names
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+}
+
+func TestRewriteArchiveExcludes(t *testing.T) {
+	excludes, err := fileutils.NewPatternMatcher([]string{"*.gz", "cache"})
+	require.NoError(t, err)
+
+	content := testArchive(t, "log/", "log/syslog", "log/syslog.1.gz", "log/cache/", "log/cache/a", "log/apt/", "log/apt/history.log")
+	progress := newCopyProgress(new(bytes.Buffer))
+	filtered := rewriteArchive(content, excludeFilter("log", excludes), progress)
+	defer filtered.Close()
+
+	assert.Equal(t, []string{"log/", "log/syslog", "log/apt/", "log/apt/history.log"}, archiveNames(t, filtered))
+	assert.Equal(t, 2, progress.files)
+	assert.Equal(t, int64(len("log/syslog")+len("log/apt/history.log")), progress.bytes)
+}
+
+func TestCopyProgressFinish(t *testing.T) {
+	out := new(bytes.Buffer)
+	progress := newCopyProgress(out)
+	progress.lastUpdate = time.Now()
+	progress.addFile()
+	progress.addBytes(11)
+	progress.finish(nil)
+	assert.Equal(t, "\r\033[2KCopied 1 files, 11B\n", out.String())
+
+	out.Reset()
+	progress.finish(errors.New("no space left on device"))
+	assert.Equal(t, "\r\033[2KCopy failed after 1 files, 11B\n", out.String())
+}
+
+func TestRewriteArchiveMatches(t *testing.T) {
+	excludes, err := fileutils.NewPatternMatcher([]string{"old.log"})
+	require.NoError(t, err)
+
+	var matched int
+	content := testArchive(t, "log/", "log/a.log", "log/b.txt", "log/old.log", "log/c.log/", "log/c.log/d")
+	filtered := rewriteArchive(content, matchFilter("log", "*.log", excludes, &matched), nil)
+	defer filtered.Close()
+
+	assert.Equal(t, []string{"a.log", "c.log/", "c.log/d"}, archiveNames(t, filtered))
+	assert.Equal(t, 2, matched)
+}
+
+func TestListArchive(t *testing.T) {
+	out := new(bytes.Buffer)
+	content := testArchive(t, "app/", "app/main.go")
+	require.NoError(t, listArchive(out, content, "tools", "/src"))
+
+	expected := "tools:/src/app/\n" +
+		"tools:/src/app/main.go   11B\n" +
+		"1 files, 11B would be copied\n"
+	assert.Equal(t, expected, out.String())
+}
@@ -0,0 +1,124 @@
+package container
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// containerFiles fakes the files of containers, by "container:path".
+type containerFiles struct {
+	stats    map[string]types.ContainerPathStat
+	archives map[string][]string
+
+	// copied records the entries of the archives copied to containers, and
+	// whether uid/gid were copied.
+	copied     map[string][]string
+	copyUIDGID bool
+}
+
+func newContainerFiles() *containerFiles {
+	return &containerFiles{
+		stats:    make(map[string]types.ContainerPathStat),
+		archives: make(map[string][]string),
+		copied:   make(map[string][]string),
+	}
+}
+
+func (f *containerFiles) addDir(path string, entries ...string) {
+	f.stats[path] = types.ContainerPathStat{Mode: os.ModeDir | 0755}
+	f.archives[path] = entries
+}
+
+func (f *containerFiles) addFile(path string, entry string) {
+	f.stats[path] = types.ContainerPathStat{Mode: 0644, Size: int64(len(entry))}
+	f.archives[path] = []string{entry}
+}
+
+func (f *containerFiles) addLink(path, target string) {
+	f.stats[path] = types.ContainerPathStat{Mode: os.ModeSymlink | 0777, LinkTarget: target}
+}
+
+func (f *containerFiles) client(t *testing.T) *fakeClient {
+	return &fakeClient{
+		containerStatPathFunc: func(container, path string) (types.ContainerPathStat, error) {
+			stat, ok := f.stats[container+":"+path]
+			if !ok {
+				return stat, errors.Errorf("Could not find the file %s in container %s", path, container)
+			}
+			return stat, nil
+		},
+		copyFromContainerFunc: func(container, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+			entries, ok := f.archives[container+":"+srcPath]
+			if !ok {
+				return nil, types.ContainerPathStat{}, errors.Errorf("Could not find the file %s in container %s", srcPath, container)
+			}
+			return ioutil.NopCloser(testArchive(t, entries...)), f.stats[container+":"+srcPath], nil
+		},
+		copyToContainerFunc: func(container, path string, content io.Reader, options types.CopyToContainerOptions) error {
+			if stat, ok := f.stats[container+":"+path]; !ok || !stat.Mode.IsDir() {
+				return errors.Errorf("Could not find the file %s in container %s", path, container)
+			}
+			f.copied[container+":"+path] = archiveNames(t, content)
+			f.copyUIDGID = options.CopyUIDGID
+			return nil
+		},
+	}
+}
+
+func runCopyCommand(t *testing.T, files *containerFiles, args ...string) error {
+	cmd := NewCopyCommand(test.NewFakeCli(files.client(t), new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs(args)
+	return cmd.Execute()
+}
+
+func TestCopyAcrossContainersFile(t *testing.T) {
+	files := newContainerFiles()
+	files.addFile("web:/etc/hosts", "hosts")
+	files.addDir("backup:/tmp")
+
+	require.NoError(t, runCopyCommand(t, files, "-a", "web:/etc/hosts", "backup:/tmp"))
+	assert.Equal(t, map[string][]string{"backup:/tmp": {"hosts"}}, files.copied)
+	assert.True(t, files.copyUIDGID)
+}
+
+func TestCopyAcrossContainersDirectory(t *testing.T) {
+	files := newContainerFiles()
+	files.addDir("web:/app", "app/", "app/main.go")
+	files.addDir("backup:/srv")
+
+	// The destination does not exist: the directory is copied with its
+	// name.
+	require.NoError(t, runCopyCommand(t, files, "web:/app", "backup:/srv/copy"))
+	assert.Equal(t, map[string][]string{"backup:/srv": {"copy/", "copy/main.go"}}, files.copied)
+	assert.False(t, files.copyUIDGID)
+}
+
+func TestCopyAcrossContainersFollowLink(t *testing.T) {
+	files := newContainerFiles()
+	files.addLink("web:/current", "releases/v2")
+	files.addDir("web:/releases/v2", "v2/", "v2/app.bin")
+	files.addDir("backup:/srv")
+
+	require.NoError(t, runCopyCommand(t, files, "--follow-link", "web:/current", "backup:/srv"))
+	assert.Equal(t, map[string][]string{"backup:/srv": {"current/", "current/app.bin"}}, files.copied)
+}
+
+func TestCopyAcrossContainersMissingDestinationParent(t *testing.T) {
+	files := newContainerFiles()
+	files.addFile("web:/etc/hosts", "hosts")
+
+	err := runCopyCommand(t, files, "web:/etc/hosts", "backup:/missing/dir/hosts")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Could not find the file /missing/dir in container backup")
+	assert.Empty(t, files.copied)
+}
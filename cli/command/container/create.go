@@ -20,8 +20,17 @@ import (
 	"golang.org/x/net/context"
 )
 
+// Pull policies of `docker create` and `docker run`
+const (
+	PullImageAlways  = "always"
+	PullImageMissing = "missing"
+	PullImageNever   = "never"
+)
+
 type createOptions struct {
-	name string
+	name    string
+	pull    string
+	profile string
 }
 
 // NewCreateCommand creates a new cobra.Command for `docker create`
@@ -46,6 +55,8 @@ func NewCreateCommand(dockerCli command.Cli) *cobra.Command {
 	flags.SetInterspersed(false)
 
 	flags.StringVar(&opts.name, "name", "", "Assign a name to the container")
+	addPullFlag(flags, &opts.pull)
+	addProfileFlag(flags, &opts.profile)
 
 	// Add an explicit help that doesn't have a `-h` to prevent the conflict
 	// with hostname
@@ -57,12 +68,15 @@ func NewCreateCommand(dockerCli command.Cli) *cobra.Command {
 }
 
 func runCreate(dockerCli command.Cli, flags *pflag.FlagSet, opts *createOptions, copts *containerOptions) error {
+	if err := applyProfile(dockerCli, opts.profile, flags); err != nil {
+		return err
+	}
 	containerConfig, err := parse(flags, copts)
 	if err != nil {
 		reportError(dockerCli.Err(), "create", err.Error(), true)
 		return cli.StatusError{StatusCode: 125}
 	}
-	response, err := createContainer(context.Background(), dockerCli, containerConfig, opts.name)
+	response, err := createContainer(context.Background(), dockerCli, containerConfig, opts)
 	if err != nil {
 		return err
 	}
@@ -70,6 +84,26 @@ func runCreate(dockerCli command.Cli, flags *pflag.FlagSet, opts *createOptions,
 	return nil
 }
 
+func addPullFlag(flags *pflag.FlagSet, pull *string) {
+	flags.StringVar(pull, "pull", "", `Pull image before creating the container ("`+PullImageAlways+`"|"`+PullImageMissing+`"|"`+PullImageNever+`")`)
+}
+
+// pullPolicy returns the pull policy set with --pull, or else in the
+// configuration file, or else PullImageMissing.
+func pullPolicy(dockerCli command.Cli, opts *createOptions) (string, error) {
+	policy := opts.pull
+	if policy == "" {
+		policy = dockerCli.ConfigFile().PullPolicy
+	}
+	switch policy {
+	case "":
+		return PullImageMissing, nil
+	case PullImageAlways, PullImageMissing, PullImageNever:
+		return policy, nil
+	}
+	return "", errors.Errorf("invalid pull policy %q: must be %q, %q or %q", policy, PullImageAlways, PullImageMissing, PullImageNever)
+}
+
 func pullImage(ctx context.Context, dockerCli command.Cli, image string, out io.Writer) error {
 	ref, err := reference.ParseNormalizedNamed(image)
 	if err != nil {
@@ -146,12 +180,18 @@ func newCIDFile(path string) (*cidFile, error) {
 	return &cidFile{path: path, file: f}, nil
 }
 
-func createContainer(ctx context.Context, dockerCli command.Cli, containerConfig *containerConfig, name string) (*container.ContainerCreateCreatedBody, error) {
+// nolint: gocyclo
+func createContainer(ctx context.Context, dockerCli command.Cli, containerConfig *containerConfig, opts *createOptions) (*container.ContainerCreateCreatedBody, error) {
 	config := containerConfig.Config
 	hostConfig := containerConfig.HostConfig
 	networkingConfig := containerConfig.NetworkingConfig
 	stderr := dockerCli.Err()
 
+	policy, err := pullPolicy(dockerCli, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	var (
 		containerIDFile *cidFile
 		trustedRef      reference.Canonical
@@ -184,29 +224,44 @@ func createContainer(ctx context.Context, dockerCli command.Cli, containerConfig
 		}
 	}
 
+	pullAndTagImage := func() error {
+		// we don't want to write to stdout anything apart from container.ID
+		if err := pullImage(ctx, dockerCli, config.Image, stderr); err != nil {
+			return err
+		}
+		// With content trust, config.Image is the digest of the trusted
+		// tag, which must be tagged again after pulling it.
+		if taggedRef, ok := namedRef.(reference.NamedTagged); ok && trustedRef != nil {
+			return image.TagTrusted(ctx, dockerCli, trustedRef, taggedRef)
+		}
+		return nil
+	}
+
+	if policy == PullImageAlways && namedRef != nil {
+		if err := pullAndTagImage(); err != nil {
+			return nil, err
+		}
+	}
+
 	//create the container
-	response, err := dockerCli.Client().ContainerCreate(ctx, config, hostConfig, networkingConfig, name)
+	response, err := dockerCli.Client().ContainerCreate(ctx, config, hostConfig, networkingConfig, opts.name)
 
 	//if image not found try to pull it
 	if err != nil {
-		if apiclient.IsErrImageNotFound(err) && namedRef != nil {
+		if apiclient.IsErrImageNotFound(err) && namedRef != nil && policy == PullImageMissing {
 			fmt.Fprintf(stderr, "Unable to find image '%s' locally\n", reference.FamiliarString(namedRef))
 
-			// we don't want to write to stdout anything apart from container.ID
-			if err = pullImage(ctx, dockerCli, config.Image, stderr); err != nil {
+			if err := pullAndTagImage(); err != nil {
 				return nil, err
 			}
-			if taggedRef, ok := namedRef.(reference.NamedTagged); ok && trustedRef != nil {
-				if err := image.TagTrusted(ctx, dockerCli, trustedRef, taggedRef); err != nil {
-					return nil, err
-				}
-			}
 			// Retry
 			var retryErr error
-			response, retryErr = dockerCli.Client().ContainerCreate(ctx, config, hostConfig, networkingConfig, name)
+			response, retryErr = dockerCli.Client().ContainerCreate(ctx, config, hostConfig, networkingConfig, opts.name)
 			if retryErr != nil {
 				return nil, retryErr
 			}
+		} else if apiclient.IsErrImageNotFound(err) && policy == PullImageNever {
+			return nil, errors.Errorf("Unable to find image '%s' locally, and the pull policy is %q", config.Image, PullImageNever)
 		} else {
 			return nil, err
 		}
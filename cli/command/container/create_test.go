@@ -0,0 +1,32 @@
+package container
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullPolicy(t *testing.T) {
+	cli := test.NewFakeCli(nil, ioutil.Discard)
+	cli.SetConfigfile(&configfile.ConfigFile{})
+
+	policy, err := pullPolicy(cli, &createOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, PullImageMissing, policy)
+
+	cli.SetConfigfile(&configfile.ConfigFile{PullPolicy: PullImageAlways})
+	policy, err = pullPolicy(cli, &createOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, PullImageAlways, policy)
+
+	policy, err = pullPolicy(cli, &createOptions{pull: PullImageNever})
+	require.NoError(t, err)
+	assert.Equal(t, PullImageNever, policy)
+
+	_, err = pullPolicy(cli, &createOptions{pull: "sometimes"})
+	assert.EqualError(t, err, `invalid pull policy "sometimes": must be "always", "missing" or "never"`)
+}
@@ -11,6 +11,7 @@ import (
 	apiclient "github.com/docker/docker/client"
 	options "github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/promise"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/context"
 )
@@ -22,6 +23,7 @@ type execOptions struct {
 	detach      bool
 	user        string
 	privileged  bool
+	record      string
 	env         *options.ListOpts
 }
 
@@ -58,6 +60,7 @@ func NewExecCommand(dockerCli *command.DockerCli) *cobra.Command {
 	flags.BoolVarP(&opts.privileged, "privileged", "", false, "Give extended privileges to the command")
 	flags.VarP(opts.env, "env", "e", "Set environment variables")
 	flags.SetAnnotation("env", "version", []string{"1.25"})
+	addRecordFlag(flags, &opts.record)
 
 	return cmd
 }
@@ -91,8 +94,16 @@ func runExec(dockerCli *command.DockerCli, opts *execOptions, container string,
 		if err := dockerCli.In().CheckTty(execConfig.AttachStdin, execConfig.Tty); err != nil {
 			return err
 		}
+	} else if opts.record != "" {
+		return errors.New("Conflicting options: --record and -d")
 	}
 
+	recorder, err := startRecording(dockerCli, opts.record, "docker exec "+container)
+	if err != nil {
+		return err
+	}
+	defer recorder.Close()
+
 	response, err := client.ContainerExecCreate(ctx, container, *execConfig)
 	if err != nil {
 		return err
@@ -140,6 +151,8 @@ func runExec(dockerCli *command.DockerCli, opts *execOptions, container string,
 		}
 	}
 
+	out, stderr = recorder.output(out), recorder.output(stderr)
+
 	resp, err := client.ContainerExecAttach(ctx, execID, *execConfig)
 	if err != nil {
 		return err
@@ -150,7 +163,7 @@ func runExec(dockerCli *command.DockerCli, opts *execOptions, container string,
 	})
 
 	if execConfig.Tty && dockerCli.In().IsTerminal() {
-		if err := MonitorTtySize(ctx, dockerCli, execID, true); err != nil {
+		if err := monitorTtySize(ctx, dockerCli, execID, true, recorder.resize); err != nil {
 			fmt.Fprintln(dockerCli.Err(), "Error monitoring TTY size:", err)
 		}
 	}
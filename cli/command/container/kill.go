@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -12,6 +11,7 @@ import (
 )
 
 type killOptions struct {
+	bulkOptions
 	signal string
 
 	containers []string
@@ -19,12 +19,12 @@ type killOptions struct {
 
 // NewKillCommand creates a new cobra.Command for `docker kill`
 func NewKillCommand(dockerCli *command.DockerCli) *cobra.Command {
-	var opts killOptions
+	opts := killOptions{bulkOptions: newBulkOptions()}
 
 	cmd := &cobra.Command{
 		Use:   "kill [OPTIONS] CONTAINER [CONTAINER...]",
 		Short: "Kill one or more running containers",
-		Args:  cli.RequiresMinArgs(1),
+		Args:  opts.requiresContainers(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.containers = args
 			return runKill(dockerCli, &opts)
@@ -33,16 +33,23 @@ func NewKillCommand(dockerCli *command.DockerCli) *cobra.Command {
 
 	flags := cmd.Flags()
 	flags.StringVarP(&opts.signal, "signal", "s", "KILL", "Signal to send to the container")
+	addBulkFlags(flags, &opts.bulkOptions)
 	return cmd
 }
 
 func runKill(dockerCli *command.DockerCli, opts *killOptions) error {
 	var errs []string
 	ctx := context.Background()
-	errChan := parallelOperation(ctx, opts.containers, func(ctx context.Context, container string) error {
+
+	containers, err := opts.selectContainers(ctx, dockerCli, opts.containers, false, "kill")
+	if err != nil {
+		return err
+	}
+
+	errChan := parallelOperation(ctx, containers, opts.maxParallel, func(ctx context.Context, container string) error {
 		return dockerCli.Client().ContainerKill(ctx, container, opts.signal)
 	})
-	for _, name := range opts.containers {
+	for _, name := range containers {
 		if err := <-errChan; err != nil {
 			errs = append(errs, err.Error())
 		} else {
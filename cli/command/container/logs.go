@@ -1,36 +1,61 @@
 package container
 
 import (
+	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	timetypes "github.com/docker/docker/api/types/time"
+	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/context"
 )
 
+// untilGrace is how long log streams are still followed after the time set
+// with --until, for the lines logged before it to be received.
+const untilGrace = time.Second
+
 type logsOptions struct {
 	follow     bool
 	since      string
+	until      string
 	timestamps bool
 	details    bool
 	tail       string
+	filter     opts.FilterOpt
 
-	container string
+	containers []string
 }
 
 // NewLogsCommand creates a new cobra.Command for `docker logs`
-func NewLogsCommand(dockerCli *command.DockerCli) *cobra.Command {
-	var opts logsOptions
+func NewLogsCommand(dockerCli command.Cli) *cobra.Command {
+	opts := logsOptions{filter: opts.NewFilterOpt()}
 
 	cmd := &cobra.Command{
-		Use:   "logs [OPTIONS] CONTAINER",
-		Short: "Fetch the logs of a container",
-		Args:  cli.ExactArgs(1),
+		Use:   "logs [OPTIONS] [CONTAINER...]",
+		Short: "Fetch the logs of one or more containers",
+		Long: "Fetch the logs of one or more containers.\n\n" +
+			"When more than one container is selected, by name or with --filter, the\n" +
+			"lines of all the containers are merged in timestamp order and prefixed\n" +
+			"with the name of their container.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if opts.filter.Value().Len() == 0 {
+				return cli.RequiresMinArgs(1)(cmd, args)
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts.container = args[0]
+			opts.containers = args
 			return runLogs(dockerCli, &opts)
 		},
 	}
@@ -38,13 +63,19 @@ func NewLogsCommand(dockerCli *command.DockerCli) *cobra.Command {
 	flags := cmd.Flags()
 	flags.BoolVarP(&opts.follow, "follow", "f", false, "Follow log output")
 	flags.StringVar(&opts.since, "since", "", "Show logs since timestamp (e.g. 2013-01-02T13:23:37) or relative (e.g. 42m for 42 minutes)")
+	flags.StringVar(&opts.until, "until", "", "Show logs before timestamp (e.g. 2013-01-02T13:23:37) or relative (e.g. 42m for 42 minutes)")
 	flags.BoolVarP(&opts.timestamps, "timestamps", "t", false, "Show timestamps")
 	flags.BoolVar(&opts.details, "details", false, "Show extra details provided to logs")
 	flags.StringVar(&opts.tail, "tail", "all", "Number of lines to show from the end of the logs")
+	flags.Var(&opts.filter, "filter", "Filter containers based on conditions provided")
 	return cmd
 }
 
-func runLogs(dockerCli *command.DockerCli, opts *logsOptions) error {
+func runLogs(dockerCli command.Cli, opts *logsOptions) error {
+	if len(opts.containers) > 1 || opts.filter.Value().Len() > 0 || opts.until != "" {
+		return runMergedLogs(dockerCli, opts)
+	}
+
 	ctx := context.Background()
 
 	options := types.ContainerLogsOptions{
@@ -56,13 +87,13 @@ func runLogs(dockerCli *command.DockerCli, opts *logsOptions) error {
 		Tail:       opts.tail,
 		Details:    opts.details,
 	}
-	responseBody, err := dockerCli.Client().ContainerLogs(ctx, opts.container, options)
+	responseBody, err := dockerCli.Client().ContainerLogs(ctx, opts.containers[0], options)
 	if err != nil {
 		return err
 	}
 	defer responseBody.Close()
 
-	c, err := dockerCli.Client().ContainerInspect(ctx, opts.container)
+	c, err := dockerCli.Client().ContainerInspect(ctx, opts.containers[0])
 	if err != nil {
 		return err
 	}
@@ -74,3 +105,280 @@ func runLogs(dockerCli *command.DockerCli, opts *logsOptions) error {
 	}
 	return err
 }
+
+// mergedLogs streams the logs of several containers to a logMerger.
+type mergedLogs struct {
+	dockerCli command.Cli
+	opts      *logsOptions
+	until     time.Time
+	prefixed  bool
+	width     int
+
+	messages chan logMessage
+	wg       sync.WaitGroup
+
+	mu        sync.Mutex
+	following map[string]bool
+	colors    int
+}
+
+// runMergedLogs prints the logs of all the containers selected by name or
+// with --filter, merged in timestamp order. The daemon does not support
+// --until, so lines logged after it are dropped here.
+func runMergedLogs(dockerCli command.Cli, opts *logsOptions) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := &mergedLogs{
+		dockerCli: dockerCli,
+		opts:      opts,
+		prefixed:  len(opts.containers) > 1 || opts.filter.Value().Len() > 0,
+		messages:  make(chan logMessage),
+		following: make(map[string]bool),
+	}
+	if opts.until != "" {
+		ts, err := timetypes.GetTimestamp(opts.until, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "invalid value for --until")
+		}
+		sec, nsec, err := timetypes.ParseTimestamps(ts, 0)
+		if err != nil {
+			return errors.Wrap(err, "invalid value for --until")
+		}
+		m.until = time.Unix(sec, nsec)
+		if opts.follow {
+			if m.until.After(time.Now()) {
+				ctx, cancel = context.WithDeadline(ctx, m.until.Add(untilGrace))
+				defer cancel()
+			} else {
+				// Nothing can be logged before --until anymore.
+				opts.follow = false
+			}
+		}
+	}
+
+	containers, err := m.listContainers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		if name := containerName(c); len(name) > m.width {
+			m.width = len(name)
+		}
+	}
+
+	// Subscribe to events before opening the logs of the existing
+	// containers, so that none that is started in between is missed.
+	var eventq <-chan events.Message
+	var errq <-chan error
+	if opts.follow && opts.filter.Value().Len() > 0 {
+		f := filters.NewArgs()
+		f.Add("type", "container")
+		f.Add("event", "start")
+		eventq, errq = dockerCli.Client().Events(ctx, types.EventsOptions{Filters: f})
+	}
+
+	var streams []*logStream
+	for _, c := range containers {
+		s, err := m.openLogs(ctx, c, opts.since)
+		if err != nil {
+			// Stop the streams already opened, and discard their output.
+			cancel()
+			go func() {
+				m.wg.Wait()
+				close(m.messages)
+			}()
+			for range m.messages {
+			}
+			return err
+		}
+		streams = append(streams, s...)
+	}
+
+	if eventq != nil {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.followStartedContainers(ctx, eventq, errq)
+		}()
+	}
+
+	go func() {
+		m.wg.Wait()
+		close(m.messages)
+	}()
+
+	merger := newLogMerger(dockerCli.Out(), dockerCli.Err(), opts.timestamps, streams)
+	err = merger.run(m.messages)
+	if ctx.Err() == context.DeadlineExceeded {
+		// Following ended because --until was reached.
+		return nil
+	}
+	return err
+}
+
+// listContainers returns the containers selected by name or with --filter.
+func (m *mergedLogs) listContainers(ctx context.Context) ([]types.ContainerJSON, error) {
+	var containers []types.ContainerJSON
+	seen := make(map[string]bool)
+	for _, name := range m.opts.containers {
+		c, err := m.dockerCli.Client().ContainerInspect(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[c.ID] {
+			seen[c.ID] = true
+			containers = append(containers, c)
+		}
+	}
+
+	if m.opts.filter.Value().Len() == 0 {
+		return containers, nil
+	}
+	list, err := m.dockerCli.Client().ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: m.opts.filter.Value(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, container := range list {
+		if seen[container.ID] {
+			continue
+		}
+		c, err := m.dockerCli.Client().ContainerInspect(ctx, container.ID)
+		if err != nil {
+			return nil, err
+		}
+		seen[c.ID] = true
+		containers = append(containers, c)
+	}
+	return containers, nil
+}
+
+// followStartedContainers opens the logs of the containers matching the
+// filter that are started while following.
+func (m *mergedLogs) followStartedContainers(ctx context.Context, eventq <-chan events.Message, errq <-chan error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-errq:
+			return
+		case event := <-eventq:
+			if !m.matches(ctx, event.ID) {
+				continue
+			}
+			c, err := m.dockerCli.Client().ContainerInspect(ctx, event.ID)
+			if err != nil {
+				continue
+			}
+			// Only show the logs written since the container was started,
+			// in case it was restarted.
+			since := strconv.FormatInt(event.Time, 10)
+			if event.TimeNano != 0 {
+				since = fmt.Sprintf("%d.%09d", event.TimeNano/int64(time.Second), event.TimeNano%int64(time.Second))
+			}
+			m.openLogs(ctx, c, since)
+		}
+	}
+}
+
+// matches returns whether a container matches the filter.
+func (m *mergedLogs) matches(ctx context.Context, containerID string) bool {
+	param, err := filters.ToParam(m.opts.filter.Value())
+	if err != nil {
+		return false
+	}
+	f, err := filters.FromParam(param)
+	if err != nil {
+		return false
+	}
+	f.Add("id", containerID)
+	list, err := m.dockerCli.Client().ContainerList(ctx, types.ContainerListOptions{All: true, Filters: f})
+	return err == nil && len(list) > 0
+}
+
+// openLogs starts streaming the logs of a container, unless they are
+// already being streamed, and returns its streams.
+func (m *mergedLogs) openLogs(ctx context.Context, c types.ContainerJSON, since string) ([]*logStream, error) {
+	m.mu.Lock()
+	if m.following[c.ID] {
+		m.mu.Unlock()
+		return nil, nil
+	}
+	m.following[c.ID] = true
+	color := m.colors
+	m.colors++
+	m.mu.Unlock()
+
+	options := types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Since:      since,
+		Timestamps: true,
+		Follow:     m.opts.follow,
+		Tail:       m.opts.tail,
+		Details:    m.opts.details,
+	}
+	body, err := m.dockerCli.Client().ContainerLogs(ctx, c.ID, options)
+	if err != nil {
+		m.mu.Lock()
+		delete(m.following, c.ID)
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	var prefix string
+	if m.prefixed {
+		prefix = logPrefix(containerName(c), m.width, color, m.dockerCli.Out().IsTerminal())
+	}
+	stdout := &logStream{prefix: prefix}
+	streams := []*logStream{stdout}
+	readers := []io.Reader{body}
+	if !c.Config.Tty {
+		stderr := &logStream{prefix: prefix, stderr: true}
+		outReader, outWriter := io.Pipe()
+		errReader, errWriter := io.Pipe()
+		go func() {
+			_, err := stdcopy.StdCopy(outWriter, errWriter, body)
+			outWriter.CloseWithError(err)
+			errWriter.CloseWithError(err)
+		}()
+		streams = append(streams, stderr)
+		readers = []io.Reader{outReader, errReader}
+	}
+
+	var streamsWg sync.WaitGroup
+	for i := range streams {
+		stream, r := streams[i], readers[i]
+		m.wg.Add(1)
+		streamsWg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			defer streamsWg.Done()
+			m.messages <- logMessage{stream: stream}
+			err := readLogStream(r, stream, m.until, m.messages)
+			if err != nil && ctx.Err() != nil {
+				// Following was stopped on purpose.
+				err = nil
+			}
+			if err != nil {
+				err = errors.Wrapf(err, "error reading logs of %s", containerName(c))
+			}
+			m.messages <- logMessage{stream: stream, done: true, err: err}
+		}()
+	}
+	go func() {
+		streamsWg.Wait()
+		body.Close()
+		m.mu.Lock()
+		delete(m.following, c.ID)
+		m.mu.Unlock()
+	}()
+	return streams, nil
+}
+
+func containerName(c types.ContainerJSON) string {
+	return strings.TrimPrefix(c.Name, "/")
+}
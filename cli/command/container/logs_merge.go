@@ -0,0 +1,223 @@
+package container
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// mergeWindow is how long a log line is held back, waiting for lines of
+// other streams that may precede it, before it is printed anyway.
+const mergeWindow = 500 * time.Millisecond
+
+var logColors = []string{
+	"\033[36m", // cyan
+	"\033[33m", // yellow
+	"\033[32m", // green
+	"\033[35m", // magenta
+	"\033[34m", // blue
+	"\033[31m", // red
+}
+
+const resetColor = "\033[0m"
+
+// logStream is the stdout or stderr log stream of a container.
+type logStream struct {
+	prefix string
+	stderr bool
+}
+
+// logLine is a line of a log stream. The daemon is always asked for
+// timestamps, so that lines of different streams can be merged.
+type logLine struct {
+	stream    *logStream
+	timestamp time.Time
+	rawTime   string
+	text      string
+	received  time.Time
+	seq       int
+}
+
+// logMessage is sent by the readers of log streams to the merger. A message
+// without line and not done announces a new stream.
+type logMessage struct {
+	stream *logStream
+	line   *logLine
+	done   bool
+	err    error
+}
+
+func parseLogLine(stream *logStream, text string) *logLine {
+	line := &logLine{stream: stream, text: text, received: time.Now()}
+	if i := strings.IndexByte(text, ' '); i > 0 {
+		if ts, err := time.Parse(time.RFC3339Nano, text[:i]); err == nil {
+			line.timestamp, line.rawTime, line.text = ts, text[:i], text[i+1:]
+		}
+	}
+	return line
+}
+
+// readLogStream sends the lines read from r to messages. Lines after until,
+// if set, are discarded.
+func readLogStream(r io.Reader, stream *logStream, until time.Time, messages chan<- logMessage) error {
+	br := bufio.NewReader(r)
+	for {
+		text, err := br.ReadString('\n')
+		if text != "" {
+			line := parseLogLine(stream, text)
+			if until.IsZero() || !line.timestamp.After(until) {
+				messages <- logMessage{stream: stream, line: line}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type logLineHeap []*logLine
+
+func (h logLineHeap) Len() int { return len(h) }
+func (h logLineHeap) Less(i, j int) bool {
+	if h[i].timestamp.Equal(h[j].timestamp) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].timestamp.Before(h[j].timestamp)
+}
+func (h logLineHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *logLineHeap) Push(x interface{}) { *h = append(*h, x.(*logLine)) }
+func (h *logLineHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// logMerger prints the lines of several log streams ordered by timestamp.
+// A line is printed once every other stream has a later line pending or
+// has ended, or once it has been held back for mergeWindow.
+type logMerger struct {
+	out        io.Writer
+	errOut     io.Writer
+	timestamps bool
+
+	pending map[*logStream]int
+	lines   logLineHeap
+	seq     int
+	errs    []string
+}
+
+func newLogMerger(out, errOut io.Writer, timestamps bool, streams []*logStream) *logMerger {
+	m := &logMerger{
+		out:        out,
+		errOut:     errOut,
+		timestamps: timestamps,
+		pending:    make(map[*logStream]int),
+	}
+	for _, stream := range streams {
+		m.pending[stream] = 0
+	}
+	return m
+}
+
+// run prints the lines received on messages until it is closed, and returns
+// the errors reported by the streams.
+func (m *logMerger) run(messages <-chan logMessage) error {
+	ticker := time.NewTicker(mergeWindow / 5)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				m.flush(true)
+				if len(m.errs) > 0 {
+					return errors.New(strings.Join(m.errs, "\n"))
+				}
+				return nil
+			}
+			m.handle(msg)
+		case <-ticker.C:
+		}
+		m.flush(false)
+	}
+}
+
+func (m *logMerger) handle(msg logMessage) {
+	switch {
+	case msg.line != nil:
+		m.seq++
+		msg.line.seq = m.seq
+		heap.Push(&m.lines, msg.line)
+		m.pending[msg.stream]++
+	case msg.done:
+		delete(m.pending, msg.stream)
+		if msg.err != nil {
+			m.errs = append(m.errs, msg.err.Error())
+		}
+	default:
+		m.pending[msg.stream] = 0
+	}
+}
+
+// flush prints the lines that can no longer be preceded by a line of
+// another stream, or all of them if all is set.
+func (m *logMerger) flush(all bool) {
+	for m.lines.Len() > 0 {
+		next := m.lines[0]
+		if !all && !m.ready(next) {
+			return
+		}
+		heap.Pop(&m.lines)
+		if _, ok := m.pending[next.stream]; ok {
+			m.pending[next.stream]--
+		}
+		m.print(next)
+	}
+}
+
+func (m *logMerger) ready(next *logLine) bool {
+	if time.Since(next.received) >= mergeWindow {
+		return true
+	}
+	for _, count := range m.pending {
+		if count == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *logMerger) print(line *logLine) {
+	out := m.out
+	if line.stream.stderr {
+		out = m.errOut
+	}
+	text := line.text
+	if m.timestamps && line.rawTime != "" {
+		text = line.rawTime + " " + text
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	fmt.Fprint(out, line.stream.prefix+text)
+}
+
+// logPrefix returns the prefix of the lines of a container, padded to width
+// and coloured with the n-th color if color is set.
+func logPrefix(name string, width, n int, color bool) string {
+	prefix := fmt.Sprintf("%-*s |", width, name)
+	if color {
+		prefix = logColors[n%len(logColors)] + prefix + resetColor
+	}
+	return prefix + " "
+}
@@ -0,0 +1,83 @@
+package container
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogMergerOrdersByTimestamp(t *testing.T) {
+	web := &logStream{prefix: logPrefix("web", 5, 0, false)}
+	db := &logStream{prefix: logPrefix("db", 5, 1, false)}
+	dbErr := &logStream{prefix: db.prefix, stderr: true}
+
+	messages := make(chan logMessage)
+	go func() {
+		send := func(stream *logStream, text string) {
+			messages <- logMessage{stream: stream, line: parseLogLine(stream, text)}
+		}
+		send(web, "2017-06-01T12:00:01.000000000Z web started\n")
+		send(web, "2017-06-01T12:00:03.000000000Z web ready\n")
+		send(db, "2017-06-01T12:00:02.000000000Z db started\n")
+		messages <- logMessage{stream: db, done: true}
+		send(dbErr, "2017-06-01T12:00:00.000000000Z db warning")
+		messages <- logMessage{stream: dbErr, done: true}
+		messages <- logMessage{stream: web, done: true}
+		close(messages)
+	}()
+
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	merger := newLogMerger(out, errOut, false, []*logStream{web, db, dbErr})
+	require.NoError(t, merger.run(messages))
+
+	expected := []string{
+		"web   | web started",
+		"db    | db started",
+		"web   | web ready",
+	}
+	assert.Equal(t, strings.Join(expected, "\n")+"\n", out.String())
+	assert.Equal(t, "db    | db warning\n", errOut.String())
+}
+
+func TestLogMergerTimestampsAndErrors(t *testing.T) {
+	stream := &logStream{}
+	messages := make(chan logMessage, 3)
+	messages <- logMessage{stream: stream, line: parseLogLine(stream, "2017-06-01T12:00:01Z hello\n")}
+	messages <- logMessage{stream: stream, done: true, err: assert.AnError}
+	close(messages)
+
+	out := new(bytes.Buffer)
+	merger := newLogMerger(out, out, true, []*logStream{stream})
+	assert.EqualError(t, merger.run(messages), assert.AnError.Error())
+	assert.Equal(t, "2017-06-01T12:00:01Z hello\n", out.String())
+}
+
+func TestParseLogLine(t *testing.T) {
+	line := parseLogLine(nil, "2017-06-01T12:00:01.5Z some text\n")
+	assert.Equal(t, time.Date(2017, 6, 1, 12, 0, 1, 5e8, time.UTC), line.timestamp)
+	assert.Equal(t, "some text\n", line.text)
+
+	line = parseLogLine(nil, "no timestamp\n")
+	assert.True(t, line.timestamp.IsZero())
+	assert.Equal(t, "no timestamp\n", line.text)
+}
+
+func TestReadLogStreamUntil(t *testing.T) {
+	stream := &logStream{}
+	input := "2017-06-01T12:00:01Z one\n2017-06-01T12:00:02Z two\n2017-06-01T12:00:03Z three\n"
+	until := time.Date(2017, 6, 1, 12, 0, 2, 0, time.UTC)
+
+	messages := make(chan logMessage, 3)
+	require.NoError(t, readLogStream(strings.NewReader(input), stream, until, messages))
+	close(messages)
+
+	var texts []string
+	for msg := range messages {
+		texts = append(texts, msg.line.text)
+	}
+	assert.Equal(t, []string{"one\n", "two\n"}, texts)
+}
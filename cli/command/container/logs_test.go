@@ -0,0 +1,41 @@
+package container
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogsFollowUntilInThePast(t *testing.T) {
+	var follow bool
+	now := time.Now().UTC()
+	buf := new(bytes.Buffer)
+	cmd := NewLogsCommand(test.NewFakeCli(&fakeClient{
+		containerInspectFunc: func(name string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{ID: name + "-id", Name: "/" + name},
+				Config:            &container.Config{Tty: true},
+			}, nil
+		},
+		containerLogsFunc: func(container string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+			follow = options.Follow
+			return ioutil.NopCloser(strings.NewReader(
+				now.Add(-20*time.Minute).Format(time.RFC3339Nano) + " before\n" +
+					now.Add(-5*time.Minute).Format(time.RFC3339Nano) + " after\n")), nil
+		},
+	}, buf))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--follow", "--until", "10m", "c1"})
+	require.NoError(t, cmd.Execute())
+	assert.False(t, follow)
+	assert.Equal(t, "before\n", buf.String())
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -12,30 +11,40 @@ import (
 )
 
 type pauseOptions struct {
+	bulkOptions
+
 	containers []string
 }
 
 // NewPauseCommand creates a new cobra.Command for `docker pause`
 func NewPauseCommand(dockerCli *command.DockerCli) *cobra.Command {
-	var opts pauseOptions
+	opts := pauseOptions{bulkOptions: newBulkOptions()}
 
-	return &cobra.Command{
-		Use:   "pause CONTAINER [CONTAINER...]",
+	cmd := &cobra.Command{
+		Use:   "pause [OPTIONS] CONTAINER [CONTAINER...]",
 		Short: "Pause all processes within one or more containers",
-		Args:  cli.RequiresMinArgs(1),
+		Args:  opts.requiresContainers(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.containers = args
 			return runPause(dockerCli, &opts)
 		},
 	}
+
+	addBulkFlags(cmd.Flags(), &opts.bulkOptions)
+	return cmd
 }
 
 func runPause(dockerCli *command.DockerCli, opts *pauseOptions) error {
 	ctx := context.Background()
 
+	containers, err := opts.selectContainers(ctx, dockerCli, opts.containers, false, "pause")
+	if err != nil {
+		return err
+	}
+
 	var errs []string
-	errChan := parallelOperation(ctx, opts.containers, dockerCli.Client().ContainerPause)
-	for _, container := range opts.containers {
+	errChan := parallelOperation(ctx, containers, opts.maxParallel, dockerCli.Client().ContainerPause)
+	for _, container := range containers {
 		if err := <-errChan; err != nil {
 			errs = append(errs, err.Error())
 			continue
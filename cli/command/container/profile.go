@@ -0,0 +1,238 @@
+package container
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/inspect"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// listFlagTypes are the types of the flags that can be repeated, whose
+// values in a profile are appended to those given on the command line.
+var listFlagTypes = map[string]bool{
+	"list":        true,
+	"map":         true,
+	"mount":       true,
+	"ulimit":      true,
+	"stringSlice": true,
+}
+
+// profile is a run profile, as shown by `docker profile inspect`.
+type profile struct {
+	Name    string
+	Options configfile.RunProfile
+}
+
+func addProfileFlag(flags *pflag.FlagSet, name *string) {
+	flags.StringVar(name, "profile", "", "Apply the options of a profile saved with 'docker profile save'")
+}
+
+// applyProfile sets the flags of the profile saved in the configuration file
+// under name. Flags set on the command line take precedence, except for
+// list flags to which the values of the profile are appended.
+func applyProfile(dockerCli command.Cli, name string, flags *pflag.FlagSet) error {
+	if name == "" {
+		return nil
+	}
+	options, ok := dockerCli.ConfigFile().Profiles[name]
+	if !ok {
+		return errors.Errorf("profile %q not found", name)
+	}
+	for _, flagName := range sortedFlagNames(options) {
+		flag := flags.Lookup(flagName)
+		if flag == nil {
+			return errors.Errorf("invalid profile %q: unknown flag --%s", name, flagName)
+		}
+		if flag.Changed && !listFlagTypes[flag.Value.Type()] {
+			continue
+		}
+		for _, value := range options[flagName] {
+			if err := flags.Set(flagName, value); err != nil {
+				return errors.Wrapf(err, "invalid profile %q: invalid value %q for --%s", name, value, flagName)
+			}
+		}
+	}
+	return nil
+}
+
+func sortedFlagNames(options configfile.RunProfile) []string {
+	var names []string
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatProfile returns the options of a profile as command line flags.
+func formatProfile(options configfile.RunProfile) string {
+	var flags []string
+	for _, name := range sortedFlagNames(options) {
+		for _, value := range options[name] {
+			if strings.ContainsAny(value, " \t\"'") {
+				value = strconv.Quote(value)
+			}
+			flags = append(flags, "--"+name+"="+value)
+		}
+	}
+	return strings.Join(flags, " ")
+}
+
+// NewProfileCommand returns a cobra command for `profile` subcommands
+func NewProfileCommand(dockerCli command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage run profiles",
+		Args:  cli.NoArgs,
+		RunE:  command.ShowHelp(dockerCli.Err()),
+	}
+	cmd.AddCommand(
+		newProfileListCommand(dockerCli),
+		newProfileInspectCommand(dockerCli),
+		newProfileSaveCommand(dockerCli),
+	)
+	return cmd
+}
+
+func newProfileListCommand(dockerCli command.Cli) *cobra.Command {
+	var quiet bool
+
+	cmd := &cobra.Command{
+		Use:     "ls [OPTIONS]",
+		Aliases: []string{"list"},
+		Short:   "List run profiles",
+		Args:    cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProfileList(dockerCli, quiet)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Only display profile names")
+	return cmd
+}
+
+func runProfileList(dockerCli command.Cli, quiet bool) error {
+	profiles := dockerCli.ConfigFile().Profiles
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if quiet {
+		for _, name := range names {
+			fmt.Fprintln(dockerCli.Out(), name)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(dockerCli.Out(), 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tOPTIONS")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%s\n", name, formatProfile(profiles[name]))
+	}
+	return w.Flush()
+}
+
+func newProfileInspectCommand(dockerCli command.Cli) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "inspect [OPTIONS] PROFILE [PROFILE...]",
+		Short: "Display detailed information on one or more run profiles",
+		Args:  cli.RequiresMinArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProfileInspect(dockerCli, format, args)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "", "Format the output using the given Go template")
+	return cmd
+}
+
+func runProfileInspect(dockerCli command.Cli, format string, names []string) error {
+	getRef := func(name string) (interface{}, []byte, error) {
+		options, ok := dockerCli.ConfigFile().Profiles[name]
+		if !ok {
+			return nil, nil, errors.Errorf("profile %q not found", name)
+		}
+		return profile{Name: name, Options: options}, nil, nil
+	}
+	return inspect.Inspect(dockerCli.Out(), names, format, getRef)
+}
+
+func newProfileSaveCommand(dockerCli command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "save PROFILE [RUN OPTIONS] [IMAGE [COMMAND] [ARG...]]",
+		Short: "Save the options of an example run command as a profile",
+		Long: "Save the options of an example run command as a profile, replacing any\n" +
+			"profile with the same name. The image and command of the example are not\n" +
+			"saved.\n\n" +
+			"Example: docker profile save tools --network host -v /src:/src alpine",
+		Args: cli.RequiresMinArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProfileSave(dockerCli, args[0], args[1:])
+		},
+	}
+
+	cmd.Flags().SetInterspersed(false)
+	return cmd
+}
+
+// profileValue records the values set on a flag in a profile.
+type profileValue struct {
+	pflag.Value
+	name    string
+	options configfile.RunProfile
+}
+
+func (v *profileValue) Set(value string) error {
+	if err := v.Value.Set(value); err != nil {
+		return err
+	}
+	v.options[v.name] = append(v.options[v.name], value)
+	return nil
+}
+
+func runProfileSave(dockerCli command.Cli, name string, args []string) error {
+	flags := pflag.NewFlagSet("run", pflag.ContinueOnError)
+	flags.SetInterspersed(false)
+	flags.SetOutput(ioutil.Discard)
+	copts := addFlags(flags)
+
+	options := configfile.RunProfile{}
+	flags.VisitAll(func(flag *pflag.Flag) {
+		flag.Value = &profileValue{Value: flag.Value, name: flag.Name, options: options}
+	})
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(options) == 0 {
+		return errors.New("no run options given for the profile")
+	}
+	copts.Image = flags.Arg(0)
+	if _, err := parse(flags, copts); err != nil {
+		return errors.Wrap(err, "invalid run options")
+	}
+
+	configFile := dockerCli.ConfigFile()
+	if configFile.Profiles == nil {
+		configFile.Profiles = make(map[string]configfile.RunProfile)
+	}
+	configFile.Profiles[name] = options
+	if err := configFile.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintln(dockerCli.Out(), name)
+	return nil
+}
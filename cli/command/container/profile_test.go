@@ -0,0 +1,79 @@
+package container
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyProfile(t *testing.T) {
+	cli := test.NewFakeCli(nil, ioutil.Discard)
+	cli.SetConfigfile(&configfile.ConfigFile{
+		Profiles: map[string]configfile.RunProfile{
+			"tools": {
+				"network": {"host"},
+				"volume":  {"/src:/src", "/cache:/cache"},
+				"cap-add": {"SYS_PTRACE"},
+				"mount":   {"type=volume,source=cache,target=/cache"},
+			},
+			"broken": {"no-such-flag": {"value"}},
+		},
+	})
+
+	flags := pflag.NewFlagSet("run", pflag.ContinueOnError)
+	copts := addFlags(flags)
+	require.NoError(t, flags.Parse([]string{"--network", "bridge", "-v", "/data:/data", "--mount", "type=bind,source=/src,target=/src", "busybox"}))
+	require.NoError(t, applyProfile(cli, "tools", flags))
+
+	assert.Equal(t, "bridge", copts.netMode)
+	assert.Equal(t, []string{"/data:/data", "/src:/src", "/cache:/cache"}, copts.volumes.GetAll())
+	assert.Equal(t, []string{"SYS_PTRACE"}, copts.capAdd.GetAll())
+	mounts := copts.mounts.Value()
+	require.Len(t, mounts, 2)
+	assert.Equal(t, "/src", mounts[0].Target)
+	assert.Equal(t, "/cache", mounts[1].Target)
+
+	assert.NoError(t, applyProfile(cli, "", flags))
+	assert.EqualError(t, applyProfile(cli, "missing", flags), `profile "missing" not found`)
+	assert.EqualError(t, applyProfile(cli, "broken", flags), `invalid profile "broken": unknown flag --no-such-flag`)
+}
+
+func TestProfileSaveAndList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	out := new(bytes.Buffer)
+	cli := test.NewFakeCli(nil, out)
+	configFile := &configfile.ConfigFile{Filename: filepath.Join(dir, "config.json")}
+	cli.SetConfigfile(configFile)
+
+	args := []string{"--network", "host", "-v", "/src:/src", "-v", "/cache:/cache", "-e", "A=b c", "alpine", "sh"}
+	require.NoError(t, runProfileSave(cli, "tools", args))
+	assert.Equal(t, configfile.RunProfile{
+		"network": {"host"},
+		"volume":  {"/src:/src", "/cache:/cache"},
+		"env":     {"A=b c"},
+	}, configFile.Profiles["tools"])
+
+	saved, err := ioutil.ReadFile(configFile.Filename)
+	require.NoError(t, err)
+	assert.Contains(t, string(saved), `"profiles"`)
+
+	assert.EqualError(t, runProfileSave(cli, "empty", []string{"alpine"}), "no run options given for the profile")
+	assert.Error(t, runProfileSave(cli, "invalid", []string{"--memory", "lots"}))
+
+	out.Reset()
+	require.NoError(t, runProfileList(cli, false))
+	expected := "NAME                OPTIONS\n" +
+		`tools               --env="A=b c" --network=host --volume=/src:/src --volume=/cache:/cache` + "\n"
+	assert.Equal(t, expected, out.String())
+}
@@ -0,0 +1,192 @@
+package container
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/pflag"
+)
+
+const (
+	asciicastVersion = 2
+
+	asciicastOutput = "o"
+	asciicastResize = "r"
+
+	defaultRecordWidth  = 80
+	defaultRecordHeight = 24
+)
+
+// asciicastHeader is the first line of an asciicast v2 recording.
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     uint              `json:"width"`
+	Height    uint              `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// sessionRecorder writes the output of an interactive session, and the
+// resizes of its terminal, as an asciicast v2 recording. The input of the
+// session is not recorded, as it may contain secrets. A nil recorder records
+// nothing.
+type sessionRecorder struct {
+	mu      sync.Mutex
+	file    io.Closer
+	w       *bufio.Writer
+	start   time.Time
+	height  uint
+	width   uint
+	partial []byte
+	err     error
+}
+
+// addRecordFlag adds the --record flag of the commands attaching to a
+// session.
+func addRecordFlag(flags *pflag.FlagSet, path *string) {
+	flags.StringVar(path, "record", "", "Record the output of the session to a file, in asciicast v2 format")
+}
+
+// startRecording creates a recording at path, with the size of the terminal
+// of dockerCli. It returns a nil recorder if path is empty.
+func startRecording(dockerCli command.Cli, path, title string) (*sessionRecorder, error) {
+	if path == "" {
+		return nil, nil
+	}
+	height, width := dockerCli.Out().GetTtySize()
+	return newSessionRecorder(path, title, height, width)
+}
+
+// newSessionRecorder creates the recording file and writes its header.
+func newSessionRecorder(path, title string, height, width uint) (*sessionRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := newSessionRecorderTo(file, title, height, width, time.Now())
+	if r.err != nil {
+		file.Close()
+		return nil, r.err
+	}
+	return r, nil
+}
+
+func newSessionRecorderTo(w io.WriteCloser, title string, height, width uint, start time.Time) *sessionRecorder {
+	if height == 0 || width == 0 {
+		height, width = defaultRecordHeight, defaultRecordWidth
+	}
+	r := &sessionRecorder{file: w, w: bufio.NewWriter(w), start: start, height: height, width: width}
+	r.writeLine(asciicastHeader{
+		Version:   asciicastVersion,
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	})
+	return r
+}
+
+func (r *sessionRecorder) writeLine(v interface{}) {
+	if r.err != nil {
+		return
+	}
+	line, err := json.Marshal(v)
+	if err == nil {
+		line = append(line, '\n')
+		_, err = r.w.Write(line)
+	}
+	r.err = err
+}
+
+func (r *sessionRecorder) event(eventType, data string) {
+	elapsed := float64(time.Since(r.start)/time.Microsecond) / 1e6
+	r.writeLine([]interface{}{elapsed, eventType, data})
+}
+
+// recordOutput records the output of the session. Multi-byte characters
+// split over several writes are recorded once complete.
+func (r *sessionRecorder) recordOutput(p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data := append(r.partial, p...)
+	data, r.partial = splitIncompleteRune(data)
+	if len(data) > 0 {
+		r.event(asciicastOutput, string(data))
+	}
+}
+
+// resize records a resize of the terminal, if its size changed.
+func (r *sessionRecorder) resize(height, width uint) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if height == r.height && width == r.width {
+		return
+	}
+	r.height, r.width = height, width
+	r.event(asciicastResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+// output returns a writer that writes to w and records what is written.
+func (r *sessionRecorder) output(w io.Writer) io.Writer {
+	if r == nil || w == nil {
+		return w
+	}
+	return &recordingWriter{Writer: w, recorder: r}
+}
+
+// Close flushes the recording and closes its file.
+func (r *sessionRecorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.partial) > 0 {
+		r.event(asciicastOutput, string(r.partial))
+		r.partial = nil
+	}
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	if err := r.file.Close(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+type recordingWriter struct {
+	io.Writer
+	recorder *sessionRecorder
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.recorder.recordOutput(p[:n])
+	return n, err
+}
+
+// splitIncompleteRune splits b before a trailing incomplete UTF-8 sequence.
+func splitIncompleteRune(b []byte) (complete, rest []byte) {
+	for i := 1; i <= utf8.UTFMax && i <= len(b); i++ {
+		if !utf8.RuneStart(b[len(b)-i]) {
+			continue
+		}
+		if !utf8.FullRune(b[len(b)-i:]) {
+			return b[:len(b)-i], append([]byte(nil), b[len(b)-i:]...)
+		}
+		break
+	}
+	return b, nil
+}
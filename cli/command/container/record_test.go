@@ -0,0 +1,91 @@
+package container
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nopWriteCloser struct {
+	*bytes.Buffer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestRecordAndReplaySession(t *testing.T) {
+	buf := nopWriteCloser{new(bytes.Buffer)}
+	recorder := newSessionRecorderTo(buf, "docker run busybox", 24, 80, time.Now())
+
+	out := new(bytes.Buffer)
+	w := recorder.output(out)
+	_, err := w.Write([]byte("hello\r\n"))
+	require.NoError(t, err)
+	recorder.resize(24, 80)
+	recorder.resize(40, 120)
+	// "é" split over two writes is recorded with the second one.
+	w.Write([]byte{'c', 'a', 'f', 0xc3})
+	w.Write([]byte{0xa9, '\n'})
+	require.NoError(t, recorder.Close())
+	assert.Equal(t, "hello\r\ncafé\n", out.String())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Contains(t, lines[0], `"version":2,"width":80,"height":24`)
+	assert.Contains(t, lines[0], `"title":"docker run busybox"`)
+	assert.Contains(t, lines[1], `"o","hello\r\n"]`)
+	assert.Contains(t, lines[2], `"r","120x40"]`)
+	assert.Contains(t, lines[3], `"o","caf"]`)
+	assert.Contains(t, lines[4], `"o","é\n"]`)
+
+	replayed := new(bytes.Buffer)
+	require.NoError(t, replaySession(strings.NewReader(buf.String()), replayed, 1, 0, func(time.Duration) {}))
+	assert.Equal(t, "hello\r\ncafé\n", replayed.String())
+}
+
+func TestReplaySessionTiming(t *testing.T) {
+	recording := `{"version":2,"width":80,"height":24}
+[0.5,"o","one"]
+[1.0,"r","100x30"]
+[3.5,"o","two"]
+[13.5,"o","three"]
+`
+	var pauses []time.Duration
+	sleep := func(d time.Duration) { pauses = append(pauses, d) }
+
+	out := new(bytes.Buffer)
+	require.NoError(t, replaySession(strings.NewReader(recording), out, 2, 4*time.Second, sleep))
+	assert.Equal(t, "onetwothree", out.String())
+	assert.Equal(t, []time.Duration{250 * time.Millisecond, 1500 * time.Millisecond, 4 * time.Second}, pauses)
+}
+
+func TestReplaySessionInvalid(t *testing.T) {
+	testCases := []struct {
+		recording string
+		expected  string
+	}{
+		{"", "empty recording"},
+		{"not json\n", "invalid recording header"},
+		{`{"version":1}` + "\n", "unsupported recording version 1"},
+		{`{"version":2}` + "\n" + `[0.5,"o"]` + "\n", "invalid event on line 2 of recording"},
+	}
+	for _, tc := range testCases {
+		err := replaySession(strings.NewReader(tc.recording), ioutil.Discard, 1, 0, func(time.Duration) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tc.expected)
+	}
+}
+
+func TestSplitIncompleteRune(t *testing.T) {
+	complete, rest := splitIncompleteRune([]byte("ab\xe2\x82"))
+	assert.Equal(t, []byte("ab"), complete)
+	assert.Equal(t, []byte("\xe2\x82"), rest)
+
+	complete, rest = splitIncompleteRune([]byte("ab€"))
+	assert.Equal(t, []byte("ab€"), complete)
+	assert.Nil(t, rest)
+}
@@ -0,0 +1,126 @@
+package container
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// maxRecordLine is the maximum size of a line of a recording.
+const maxRecordLine = 16 * 1024 * 1024
+
+type replayOptions struct {
+	speed     float64
+	idleLimit time.Duration
+
+	file string
+}
+
+// NewReplayCommand creates a new cobra.Command for `docker replay`
+func NewReplayCommand(dockerCli *command.DockerCli) *cobra.Command {
+	var opts replayOptions
+
+	cmd := &cobra.Command{
+		Use:   "replay [OPTIONS] FILE",
+		Short: "Replay a session recorded with --record",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.file = args[0]
+			return runReplay(dockerCli, &opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.Float64Var(&opts.speed, "speed", 1, "Playback speed factor")
+	flags.DurationVar(&opts.idleLimit, "idle-limit", 0, "Maximum pause between two outputs (0 for no limit)")
+	return cmd
+}
+
+func runReplay(dockerCli *command.DockerCli, opts *replayOptions) error {
+	if opts.speed <= 0 {
+		return errors.Errorf("invalid speed %v: must be positive", opts.speed)
+	}
+
+	f, err := os.Open(opts.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return replaySession(f, dockerCli.Out(), opts.speed, opts.idleLimit, time.Sleep)
+}
+
+// replaySession writes the output events of an asciicast v2 recording to
+// out, pausing between them as during the recording.
+func replaySession(r io.Reader, out io.Writer, speed float64, idleLimit time.Duration, sleep func(time.Duration)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxRecordLine)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return errors.New("empty recording")
+	}
+	var header asciicastHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return errors.Wrap(err, "invalid recording header")
+	}
+	if header.Version != asciicastVersion {
+		return errors.Errorf("unsupported recording version %d", header.Version)
+	}
+
+	var previous float64
+	for line := 2; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var (
+			event     []json.RawMessage
+			elapsed   float64
+			eventType string
+			data      string
+		)
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || len(event) != 3 {
+			return errors.Errorf("invalid event on line %d of recording", line)
+		}
+		if err := unmarshalAll(event, &elapsed, &eventType, &data); err != nil {
+			return errors.Wrapf(err, "invalid event on line %d of recording", line)
+		}
+
+		if eventType != asciicastOutput {
+			// Input is not replayed, and the terminal cannot be resized.
+			continue
+		}
+
+		pause := time.Duration((elapsed - previous) / speed * float64(time.Second))
+		if idleLimit > 0 && pause > idleLimit {
+			pause = idleLimit
+		}
+		if pause > 0 {
+			sleep(pause)
+		}
+		previous = elapsed
+
+		if _, err := io.WriteString(out, data); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func unmarshalAll(raw []json.RawMessage, values ...interface{}) error {
+	for i, v := range values {
+		if err := json.Unmarshal(raw[i], v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -13,6 +12,8 @@ import (
 )
 
 type restartOptions struct {
+	bulkOptions
+	waitHealthyOptions
 	nSeconds        int
 	nSecondsChanged bool
 
@@ -21,12 +22,12 @@ type restartOptions struct {
 
 // NewRestartCommand creates a new cobra.Command for `docker restart`
 func NewRestartCommand(dockerCli *command.DockerCli) *cobra.Command {
-	var opts restartOptions
+	opts := restartOptions{bulkOptions: newBulkOptions()}
 
 	cmd := &cobra.Command{
 		Use:   "restart [OPTIONS] CONTAINER [CONTAINER...]",
 		Short: "Restart one or more containers",
-		Args:  cli.RequiresMinArgs(1),
+		Args:  opts.requiresContainers(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.containers = args
 			opts.nSecondsChanged = cmd.Flags().Changed("time")
@@ -36,6 +37,8 @@ func NewRestartCommand(dockerCli *command.DockerCli) *cobra.Command {
 
 	flags := cmd.Flags()
 	flags.IntVarP(&opts.nSeconds, "time", "t", 10, "Seconds to wait for stop before killing the container")
+	addWaitHealthyFlags(flags, &opts.waitHealthyOptions)
+	addBulkFlags(flags, &opts.bulkOptions)
 	return cmd
 }
 
@@ -48,12 +51,29 @@ func runRestart(dockerCli *command.DockerCli, opts *restartOptions) error {
 		timeout = &timeoutValue
 	}
 
-	for _, name := range opts.containers {
-		if err := dockerCli.Client().ContainerRestart(ctx, name, timeout); err != nil {
+	containers, err := opts.selectContainers(ctx, dockerCli, opts.containers, true, "restart")
+	if err != nil {
+		return err
+	}
+
+	var restarted []string
+	errChan := parallelOperation(ctx, containers, opts.maxParallel, func(ctx context.Context, container string) error {
+		return dockerCli.Client().ContainerRestart(ctx, container, timeout)
+	})
+	for _, name := range containers {
+		if err := <-errChan; err != nil {
 			errs = append(errs, err.Error())
 			continue
 		}
 		fmt.Fprintln(dockerCli.Out(), name)
+		restarted = append(restarted, name)
+	}
+	if opts.wait {
+		for _, name := range restarted {
+			if err := waitHealthy(ctx, dockerCli, name, opts.waitTimeout); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
 	}
 	if len(errs) > 0 {
 		return errors.New(strings.Join(errs, "\n"))
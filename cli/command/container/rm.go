@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/docker/api/types"
 	"github.com/pkg/errors"
@@ -13,6 +12,7 @@ import (
 )
 
 type rmOptions struct {
+	bulkOptions
 	rmVolumes bool
 	rmLink    bool
 	force     bool
@@ -22,12 +22,12 @@ type rmOptions struct {
 
 // NewRmCommand creates a new cobra.Command for `docker rm`
 func NewRmCommand(dockerCli *command.DockerCli) *cobra.Command {
-	var opts rmOptions
+	opts := rmOptions{bulkOptions: newBulkOptions()}
 
 	cmd := &cobra.Command{
 		Use:   "rm [OPTIONS] CONTAINER [CONTAINER...]",
 		Short: "Remove one or more containers",
-		Args:  cli.RequiresMinArgs(1),
+		Args:  opts.requiresContainers(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.containers = args
 			return runRm(dockerCli, &opts)
@@ -38,6 +38,7 @@ func NewRmCommand(dockerCli *command.DockerCli) *cobra.Command {
 	flags.BoolVarP(&opts.rmVolumes, "volumes", "v", false, "Remove the volumes associated with the container")
 	flags.BoolVarP(&opts.rmLink, "link", "l", false, "Remove the specified link")
 	flags.BoolVarP(&opts.force, "force", "f", false, "Force the removal of a running container (uses SIGKILL)")
+	addBulkFlags(flags, &opts.bulkOptions)
 	return cmd
 }
 
@@ -51,7 +52,12 @@ func runRm(dockerCli *command.DockerCli, opts *rmOptions) error {
 		Force:         opts.force,
 	}
 
-	errChan := parallelOperation(ctx, opts.containers, func(ctx context.Context, container string) error {
+	containers, err := opts.selectContainers(ctx, dockerCli, opts.containers, true, "remove")
+	if err != nil {
+		return err
+	}
+
+	errChan := parallelOperation(ctx, containers, opts.maxParallel, func(ctx context.Context, container string) error {
 		container = strings.Trim(container, "/")
 		if container == "" {
 			return errors.New("Container name cannot be empty")
@@ -59,7 +65,7 @@ func runRm(dockerCli *command.DockerCli, opts *rmOptions) error {
 		return dockerCli.Client().ContainerRemove(ctx, container, options)
 	})
 
-	for _, name := range opts.containers {
+	for _, name := range containers {
 		if err := <-errChan; err != nil {
 			errs = append(errs, err.Error())
 			continue
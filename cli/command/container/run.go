@@ -24,10 +24,12 @@ import (
 )
 
 type runOptions struct {
+	createOptions
+	waitHealthyOptions
 	detach     bool
 	sigProxy   bool
-	name       string
 	detachKeys string
+	record     string
 }
 
 // NewRunCommand create a new `docker run` command
@@ -55,7 +57,11 @@ func NewRunCommand(dockerCli *command.DockerCli) *cobra.Command {
 	flags.BoolVarP(&opts.detach, "detach", "d", false, "Run container in background and print container ID")
 	flags.BoolVar(&opts.sigProxy, "sig-proxy", true, "Proxy received signals to the process")
 	flags.StringVar(&opts.name, "name", "", "Assign a name to the container")
+	addPullFlag(flags, &opts.pull)
+	addProfileFlag(flags, &opts.profile)
 	flags.StringVar(&opts.detachKeys, "detach-keys", "", "Override the key sequence for detaching a container")
+	addWaitHealthyFlags(flags, &opts.waitHealthyOptions)
+	addRecordFlag(flags, &opts.record)
 
 	// Add an explicit help that doesn't have a `-h` to prevent the conflict
 	// with hostname
@@ -84,6 +90,9 @@ func warnOnLocalhostDNS(hostConfig container.HostConfig, stderr io.Writer) {
 }
 
 func runRun(dockerCli *command.DockerCli, flags *pflag.FlagSet, opts *runOptions, copts *containerOptions) error {
+	if err := applyProfile(dockerCli, opts.profile, flags); err != nil {
+		return err
+	}
 	containerConfig, err := parse(flags, copts)
 	// just in case the parse does not exit
 	if err != nil {
@@ -109,6 +118,12 @@ func runContainer(dockerCli *command.DockerCli, opts *runOptions, copts *contain
 	config.ArgsEscaped = false
 
 	if !opts.detach {
+		if opts.wait {
+			return errors.New("Conflicting options: --wait requires -d")
+		}
+		if opts.record != "" && !config.AttachStdout && !config.AttachStderr {
+			return errors.New("Conflicting options: --record requires attaching to STDOUT or STDERR")
+		}
 		if err := dockerCli.In().CheckTty(config.AttachStdin, config.Tty); err != nil {
 			return err
 		}
@@ -116,6 +131,9 @@ func runContainer(dockerCli *command.DockerCli, opts *runOptions, copts *contain
 		if copts.attach.Len() != 0 {
 			return errors.New("Conflicting options: -a and -d")
 		}
+		if opts.record != "" {
+			return errors.New("Conflicting options: --record and -d")
+		}
 
 		config.AttachStdin = false
 		config.AttachStdout = false
@@ -135,9 +153,15 @@ func runContainer(dockerCli *command.DockerCli, opts *runOptions, copts *contain
 		hostConfig.ConsoleSize[0], hostConfig.ConsoleSize[1] = dockerCli.Out().GetTtySize()
 	}
 
+	recorder, err := startRecording(dockerCli, opts.record, "docker run "+config.Image)
+	if err != nil {
+		return err
+	}
+	defer recorder.Close()
+
 	ctx, cancelFun := context.WithCancel(context.Background())
 
-	createResponse, err := createContainer(ctx, dockerCli, containerConfig, opts.name)
+	createResponse, err := createContainer(ctx, dockerCli, containerConfig, &opts.createOptions)
 	if err != nil {
 		reportError(stderr, cmdPath, err.Error(), true)
 		return runStartContainerErr(err)
@@ -164,7 +188,7 @@ func runContainer(dockerCli *command.DockerCli, opts *runOptions, copts *contain
 			dockerCli.ConfigFile().DetachKeys = opts.detachKeys
 		}
 
-		close, err := attachContainer(ctx, dockerCli, &errCh, config, createResponse.ID)
+		close, err := attachContainer(ctx, dockerCli, &errCh, config, createResponse.ID, recorder)
 		defer close()
 		if err != nil {
 			return err
@@ -192,7 +216,7 @@ func runContainer(dockerCli *command.DockerCli, opts *runOptions, copts *contain
 	}
 
 	if (config.AttachStdin || config.AttachStdout || config.AttachStderr) && config.Tty && dockerCli.Out().IsTerminal() {
-		if err := MonitorTtySize(ctx, dockerCli, createResponse.ID, false); err != nil {
+		if err := monitorTtySize(ctx, dockerCli, createResponse.ID, false, recorder.resize); err != nil {
 			fmt.Fprintln(stderr, "Error monitoring TTY size:", err)
 		}
 	}
@@ -208,6 +232,9 @@ func runContainer(dockerCli *command.DockerCli, opts *runOptions, copts *contain
 	if !config.AttachStdout && !config.AttachStderr {
 		// Detached mode
 		<-waitDisplayID
+		if opts.wait {
+			return waitHealthy(ctx, dockerCli, createResponse.ID, opts.waitTimeout)
+		}
 		return nil
 	}
 
@@ -224,6 +251,7 @@ func attachContainer(
 	errCh *chan error,
 	config *container.Config,
 	containerID string,
+	recorder *sessionRecorder,
 ) (func(), error) {
 	stdout, stderr := dockerCli.Out(), dockerCli.Err()
 	var (
@@ -243,6 +271,7 @@ func attachContainer(
 			cerr = stderr
 		}
 	}
+	out, cerr = recorder.output(out), recorder.output(cerr)
 
 	options := types.ContainerAttachOptions{
 		Stream:     true,
@@ -17,6 +17,7 @@ import (
 )
 
 type startOptions struct {
+	waitHealthyOptions
 	attach        bool
 	openStdin     bool
 	detachKeys    string
@@ -44,6 +45,7 @@ func NewStartCommand(dockerCli *command.DockerCli) *cobra.Command {
 	flags.BoolVarP(&opts.attach, "attach", "a", false, "Attach STDOUT/STDERR and forward signals")
 	flags.BoolVarP(&opts.openStdin, "interactive", "i", false, "Attach container's STDIN")
 	flags.StringVar(&opts.detachKeys, "detach-keys", "", "Override the key sequence for detaching a container")
+	addWaitHealthyFlags(flags, &opts.waitHealthyOptions)
 
 	flags.StringVar(&opts.checkpoint, "checkpoint", "", "Restore from this checkpoint")
 	flags.SetAnnotation("checkpoint", "experimental", nil)
@@ -62,6 +64,9 @@ func runStart(dockerCli *command.DockerCli, opts *startOptions) error {
 		if len(opts.containers) > 1 {
 			return errors.New("you cannot start and attach multiple containers at once")
 		}
+		if opts.wait {
+			return errors.New("Conflicting options: --wait and --attach or --interactive")
+		}
 
 		// 2. Attach to the container.
 		container := opts.containers[0]
@@ -151,19 +156,27 @@ func runStart(dockerCli *command.DockerCli, opts *startOptions) error {
 			CheckpointID:  opts.checkpoint,
 			CheckpointDir: opts.checkpointDir,
 		}
-		return dockerCli.Client().ContainerStart(ctx, container, startOptions)
+		if err := dockerCli.Client().ContainerStart(ctx, container, startOptions); err != nil {
+			return err
+		}
+		if opts.wait {
+			return waitHealthy(ctx, dockerCli, container, opts.waitTimeout)
+		}
 
 	} else {
 		// We're not going to attach to anything.
 		// Start as many containers as we want.
-		return startContainersWithoutAttachments(ctx, dockerCli, opts.containers)
+		return startContainersWithoutAttachments(ctx, dockerCli, opts.containers, &opts.waitHealthyOptions)
 	}
 
 	return nil
 }
 
-func startContainersWithoutAttachments(ctx context.Context, dockerCli *command.DockerCli, containers []string) error {
-	var failedContainers []string
+func startContainersWithoutAttachments(ctx context.Context, dockerCli *command.DockerCli, containers []string, waitOpts *waitHealthyOptions) error {
+	var (
+		failedContainers  []string
+		startedContainers []string
+	)
 	for _, container := range containers {
 		if err := dockerCli.Client().ContainerStart(ctx, container, types.ContainerStartOptions{}); err != nil {
 			fmt.Fprintln(dockerCli.Err(), err)
@@ -171,6 +184,16 @@ func startContainersWithoutAttachments(ctx context.Context, dockerCli *command.D
 			continue
 		}
 		fmt.Fprintln(dockerCli.Out(), container)
+		startedContainers = append(startedContainers, container)
+	}
+
+	if waitOpts.wait {
+		for _, container := range startedContainers {
+			if err := waitHealthy(ctx, dockerCli, container, waitOpts.waitTimeout); err != nil {
+				fmt.Fprintln(dockerCli.Err(), err)
+				failedContainers = append(failedContainers, container)
+			}
+		}
 	}
 
 	if len(failedContainers) > 0 {
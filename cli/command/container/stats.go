@@ -23,6 +23,10 @@ type statsOptions struct {
 	noStream   bool
 	format     string
 	containers []string
+	interval   time.Duration
+	count      int
+	output     string
+	listen     string
 }
 
 // NewStatsCommand creates a new cobra.Command for `docker stats`
@@ -35,6 +39,9 @@ func NewStatsCommand(dockerCli *command.DockerCli) *cobra.Command {
 		Args:  cli.RequiresMinArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.containers = args
+			if err := validateStatsOptions(&opts); err != nil {
+				return err
+			}
 			return runStats(dockerCli, &opts)
 		},
 	}
@@ -42,10 +49,35 @@ func NewStatsCommand(dockerCli *command.DockerCli) *cobra.Command {
 	flags := cmd.Flags()
 	flags.BoolVarP(&opts.all, "all", "a", false, "Show all containers (default shows just running)")
 	flags.BoolVar(&opts.noStream, "no-stream", false, "Disable streaming stats and only pull the first result")
-	flags.StringVar(&opts.format, "format", "", "Pretty-print images using a Go template")
+	flags.StringVar(&opts.format, "format", "", "Pretty-print images using a Go template, or 'json' to print one JSON object per container and sample")
+	flags.DurationVar(&opts.interval, "interval", 500*time.Millisecond, "Interval between two samples")
+	flags.IntVar(&opts.count, "count", 0, "Number of samples to print before exiting (0 for no limit)")
+	flags.StringVar(&opts.output, "output", "", "Output format of the samples (csv)")
+	flags.StringVar(&opts.listen, "listen", "", "Serve the statistics on the given address for Prometheus to scrape, e.g. ':9101'")
 	return cmd
 }
 
+func validateStatsOptions(opts *statsOptions) error {
+	if opts.interval <= 0 {
+		return errors.Errorf("invalid interval %s: must be positive", opts.interval)
+	}
+	if opts.count < 0 {
+		return errors.Errorf("invalid count %d: must not be negative", opts.count)
+	}
+	switch opts.output {
+	case "", statsOutputCSV:
+	default:
+		return errors.Errorf("invalid output %q: only %q is supported", opts.output, statsOutputCSV)
+	}
+	if opts.output != "" && opts.format != "" {
+		return errors.New("--output and --format cannot be combined")
+	}
+	if opts.listen != "" && (opts.output != "" || opts.format != "" || opts.noStream || opts.count != 0) {
+		return errors.New("--listen cannot be combined with --format, --output, --no-stream or --count")
+	}
+	return nil
+}
+
 // runStats displays a live stream of resource usage statistics for one or more containers.
 // This shows real-time information on CPU usage, memory usage, and network I/O.
 // nolint: gocyclo
@@ -199,31 +231,54 @@ func runStats(dockerCli *command.DockerCli, opts *statsOptions) error {
 		Format: formatter.NewStatsFormat(format, daemonOSType),
 	}
 	cleanScreen := func() {
-		if !opts.noStream {
+		if !opts.noStream && opts.count != 1 {
 			fmt.Fprint(dockerCli.Out(), "\033[2J")
 			fmt.Fprint(dockerCli.Out(), "\033[H")
 		}
 	}
 
-	var err error
-	for range time.Tick(500 * time.Millisecond) {
-		cleanScreen()
+	var serveErr <-chan error
+	if opts.listen != "" {
+		serveErr = serveStatsMetrics(opts.listen, &cStats)
+		fmt.Fprintf(dockerCli.Out(), "Serving container statistics on http://%s/metrics\n", opts.listen)
+	}
+	csvWriter := newStatsCSVWriter(dockerCli.Out())
+
+	var (
+		err     error
+		samples int
+	)
+	for range time.Tick(opts.interval) {
 		ccstats := []formatter.StatsEntry{}
 		cStats.mu.Lock()
 		for _, c := range cStats.cs {
 			ccstats = append(ccstats, c.GetStatistics())
 		}
 		cStats.mu.Unlock()
-		if err = formatter.ContainerStatsWrite(statsCtx, ccstats, daemonOSType); err != nil {
+		switch {
+		case opts.listen != "":
+			// The statistics are only served on request.
+		case format == statsFormatJSON:
+			err = writeStatsJSON(dockerCli.Out(), newStatsSamples(time.Now(), ccstats))
+		case opts.output == statsOutputCSV:
+			err = csvWriter.write(newStatsSamples(time.Now(), ccstats))
+		default:
+			cleanScreen()
+			err = formatter.ContainerStatsWrite(statsCtx, ccstats, daemonOSType)
+		}
+		if err != nil {
 			break
 		}
+		samples++
 		if len(cStats.cs) == 0 && !showAll {
 			break
 		}
-		if opts.noStream {
+		if opts.noStream || (opts.count > 0 && samples >= opts.count) {
 			break
 		}
 		select {
+		case err := <-serveErr:
+			return err
 		case err, ok := <-closeChan:
 			if ok {
 				if err != nil {
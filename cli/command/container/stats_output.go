@@ -0,0 +1,189 @@
+package container
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/cli/cli/command/formatter"
+)
+
+const (
+	statsFormatJSON = "json"
+	statsOutputCSV  = "csv"
+)
+
+// statsSample is a sample of the statistics of a container, as written by
+// `docker stats --format json` and `docker stats --output csv`.
+type statsSample struct {
+	Time             time.Time `json:"time"`
+	Container        string    `json:"container"`
+	Name             string    `json:"name"`
+	ID               string    `json:"id"`
+	CPUPercentage    float64   `json:"cpu_percent"`
+	Memory           float64   `json:"memory_usage"`
+	MemoryLimit      float64   `json:"memory_limit"`
+	MemoryPercentage float64   `json:"memory_percent"`
+	NetworkRx        float64   `json:"network_rx"`
+	NetworkTx        float64   `json:"network_tx"`
+	BlockRead        float64   `json:"block_read"`
+	BlockWrite       float64   `json:"block_write"`
+	PidsCurrent      uint64    `json:"pids"`
+}
+
+var statsCSVHeader = []string{
+	"time", "container", "name", "id", "cpu_percent", "memory_usage", "memory_limit", "memory_percent",
+	"network_rx", "network_tx", "block_read", "block_write", "pids",
+}
+
+// newStatsSamples returns the samples of the valid statistics in entries.
+func newStatsSamples(now time.Time, entries []formatter.StatsEntry) []statsSample {
+	samples := make([]statsSample, 0, len(entries))
+	for _, s := range entries {
+		if s.IsInvalid {
+			continue
+		}
+		samples = append(samples, statsSample{
+			Time:             now,
+			Container:        s.Container,
+			Name:             strings.TrimPrefix(s.Name, "/"),
+			ID:               s.ID,
+			CPUPercentage:    s.CPUPercentage,
+			Memory:           s.Memory,
+			MemoryLimit:      s.MemoryLimit,
+			MemoryPercentage: s.MemoryPercentage,
+			NetworkRx:        s.NetworkRx,
+			NetworkTx:        s.NetworkTx,
+			BlockRead:        s.BlockRead,
+			BlockWrite:       s.BlockWrite,
+			PidsCurrent:      s.PidsCurrent,
+		})
+	}
+	return samples
+}
+
+// writeStatsJSON writes one JSON object per sample, on its own line.
+func writeStatsJSON(out io.Writer, samples []statsSample) error {
+	enc := json.NewEncoder(out)
+	for _, sample := range samples {
+		if err := enc.Encode(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statsCSVWriter writes samples as CSV records, preceded by a header.
+type statsCSVWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func newStatsCSVWriter(out io.Writer) *statsCSVWriter {
+	return &statsCSVWriter{w: csv.NewWriter(out)}
+}
+
+func (c *statsCSVWriter) write(samples []statsSample) error {
+	if !c.headerWritten {
+		if err := c.w.Write(statsCSVHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+	for _, s := range samples {
+		record := []string{
+			s.Time.Format(time.RFC3339Nano),
+			s.Container,
+			s.Name,
+			s.ID,
+			formatFloat(s.CPUPercentage),
+			formatFloat(s.Memory),
+			formatFloat(s.MemoryLimit),
+			formatFloat(s.MemoryPercentage),
+			formatFloat(s.NetworkRx),
+			formatFloat(s.NetworkTx),
+			formatFloat(s.BlockRead),
+			formatFloat(s.BlockWrite),
+			strconv.FormatUint(s.PidsCurrent, 10),
+		}
+		if err := c.w.Write(record); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// statsMetric describes a metric exported by `docker stats --listen`.
+type statsMetric struct {
+	name       string
+	metricType string
+	help       string
+	value      func(s statsSample) float64
+}
+
+var statsMetrics = []statsMetric{
+	{"docker_container_cpu_usage_percent", "gauge", "CPU usage of the container, in percent of one CPU.", func(s statsSample) float64 { return s.CPUPercentage }},
+	{"docker_container_memory_usage_bytes", "gauge", "Memory usage of the container.", func(s statsSample) float64 { return s.Memory }},
+	{"docker_container_memory_limit_bytes", "gauge", "Memory limit of the container.", func(s statsSample) float64 { return s.MemoryLimit }},
+	{"docker_container_memory_usage_percent", "gauge", "Memory usage of the container, in percent of its limit.", func(s statsSample) float64 { return s.MemoryPercentage }},
+	{"docker_container_network_receive_bytes_total", "counter", "Bytes received by the container over the network.", func(s statsSample) float64 { return s.NetworkRx }},
+	{"docker_container_network_transmit_bytes_total", "counter", "Bytes sent by the container over the network.", func(s statsSample) float64 { return s.NetworkTx }},
+	{"docker_container_block_read_bytes_total", "counter", "Bytes read by the container from block devices.", func(s statsSample) float64 { return s.BlockRead }},
+	{"docker_container_block_write_bytes_total", "counter", "Bytes written by the container to block devices.", func(s statsSample) float64 { return s.BlockWrite }},
+	{"docker_container_pids", "gauge", "Number of processes in the container.", func(s statsSample) float64 { return float64(s.PidsCurrent) }},
+}
+
+// writeStatsMetrics writes samples in the Prometheus text exposition format.
+func writeStatsMetrics(out io.Writer, samples []statsSample) error {
+	buf := new(bytes.Buffer)
+	for _, metric := range statsMetrics {
+		fmt.Fprintf(buf, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(buf, "# TYPE %s %s\n", metric.name, metric.metricType)
+		for _, s := range samples {
+			fmt.Fprintf(buf, "%s{id=%s,name=%s} %s\n", metric.name, quoteLabel(s.ID), quoteLabel(s.Name), formatFloat(metric.value(s)))
+		}
+	}
+	_, err := buf.WriteTo(out)
+	return err
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+// serveStatsMetrics serves the statistics of the containers in cStats on
+// addr, for Prometheus to scrape. Errors serving are sent on the returned
+// channel.
+func serveStatsMetrics(addr string, cStats *stats) <-chan error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		var entries []formatter.StatsEntry
+		cStats.mu.Lock()
+		for _, c := range cStats.cs {
+			entries = append(entries, c.GetStatistics())
+		}
+		cStats.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeStatsMetrics(w, newStatsSamples(time.Now(), entries))
+	})
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- http.ListenAndServe(addr, mux)
+	}()
+	return errc
+}
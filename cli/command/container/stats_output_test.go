@@ -0,0 +1,85 @@
+package container
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStatsSamples() []statsSample {
+	now := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	return newStatsSamples(now, []formatter.StatsEntry{
+		{
+			Container:        "web",
+			Name:             "/web",
+			ID:               "abcdef",
+			CPUPercentage:    12.5,
+			Memory:           1024,
+			MemoryLimit:      4096,
+			MemoryPercentage: 25,
+			NetworkRx:        10,
+			NetworkTx:        20,
+			BlockRead:        30,
+			BlockWrite:       40,
+			PidsCurrent:      3,
+		},
+		{Container: "db", IsInvalid: true},
+	})
+}
+
+func TestWriteStatsJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeStatsJSON(buf, testStatsSamples()))
+
+	expected := `{"time":"2017-06-01T12:00:00Z","container":"web","name":"web","id":"abcdef",` +
+		`"cpu_percent":12.5,"memory_usage":1024,"memory_limit":4096,"memory_percent":25,` +
+		`"network_rx":10,"network_tx":20,"block_read":30,"block_write":40,"pids":3}` + "\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestStatsCSVWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := newStatsCSVWriter(buf)
+	require.NoError(t, w.write(testStatsSamples()))
+	require.NoError(t, w.write(testStatsSamples()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, strings.Join(statsCSVHeader, ","), lines[0])
+	assert.Equal(t, "2017-06-01T12:00:00Z,web,web,abcdef,12.5,1024,4096,25,10,20,30,40,3", lines[1])
+}
+
+func TestWriteStatsMetrics(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeStatsMetrics(buf, testStatsSamples()))
+
+	output := buf.String()
+	assert.Contains(t, output, "# TYPE docker_container_cpu_usage_percent gauge\n")
+	assert.Contains(t, output, `docker_container_cpu_usage_percent{id="abcdef",name="web"} 12.5`+"\n")
+	assert.Contains(t, output, "# TYPE docker_container_network_receive_bytes_total counter\n")
+	assert.Contains(t, output, `docker_container_pids{id="abcdef",name="web"} 3`+"\n")
+}
+
+func TestQuoteLabel(t *testing.T) {
+	assert.Equal(t, `"a\"b\\c\nd"`, quoteLabel("a\"b\\c\nd"))
+}
+
+func TestValidateStatsOptions(t *testing.T) {
+	valid := statsOptions{interval: time.Second}
+	assert.NoError(t, validateStatsOptions(&valid))
+
+	for _, opts := range []statsOptions{
+		{interval: 0},
+		{interval: time.Second, count: -1},
+		{interval: time.Second, output: "xml"},
+		{interval: time.Second, output: "csv", format: "json"},
+		{interval: time.Second, listen: ":9101", count: 1},
+	} {
+		assert.Error(t, validateStatsOptions(&opts))
+	}
+}
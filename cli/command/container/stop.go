@@ -5,7 +5,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -13,6 +12,7 @@ import (
 )
 
 type stopOptions struct {
+	bulkOptions
 	time        int
 	timeChanged bool
 
@@ -21,12 +21,12 @@ type stopOptions struct {
 
 // NewStopCommand creates a new cobra.Command for `docker stop`
 func NewStopCommand(dockerCli *command.DockerCli) *cobra.Command {
-	var opts stopOptions
+	opts := stopOptions{bulkOptions: newBulkOptions()}
 
 	cmd := &cobra.Command{
 		Use:   "stop [OPTIONS] CONTAINER [CONTAINER...]",
 		Short: "Stop one or more running containers",
-		Args:  cli.RequiresMinArgs(1),
+		Args:  opts.requiresContainers(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.containers = args
 			opts.timeChanged = cmd.Flags().Changed("time")
@@ -36,6 +36,7 @@ func NewStopCommand(dockerCli *command.DockerCli) *cobra.Command {
 
 	flags := cmd.Flags()
 	flags.IntVarP(&opts.time, "time", "t", 10, "Seconds to wait for stop before killing it")
+	addBulkFlags(flags, &opts.bulkOptions)
 	return cmd
 }
 
@@ -48,12 +49,17 @@ func runStop(dockerCli *command.DockerCli, opts *stopOptions) error {
 		timeout = &timeoutValue
 	}
 
+	containers, err := opts.selectContainers(ctx, dockerCli, opts.containers, false, "stop")
+	if err != nil {
+		return err
+	}
+
 	var errs []string
 
-	errChan := parallelOperation(ctx, opts.containers, func(ctx context.Context, id string) error {
+	errChan := parallelOperation(ctx, containers, opts.maxParallel, func(ctx context.Context, id string) error {
 		return dockerCli.Client().ContainerStop(ctx, id, timeout)
 	})
-	for _, container := range opts.containers {
+	for _, container := range containers {
 		if err := <-errChan; err != nil {
 			errs = append(errs, err.Error())
 			continue
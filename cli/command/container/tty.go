@@ -40,9 +40,18 @@ func resizeTtyTo(ctx context.Context, client client.ContainerAPIClient, id strin
 
 // MonitorTtySize updates the container tty size when the terminal tty changes size
 func MonitorTtySize(ctx context.Context, cli *command.DockerCli, id string, isExec bool) error {
+	return monitorTtySize(ctx, cli, id, isExec, nil)
+}
+
+// monitorTtySize is MonitorTtySize, also calling onResize, if set, when the
+// terminal tty changes size.
+func monitorTtySize(ctx context.Context, cli *command.DockerCli, id string, isExec bool, onResize func(height, width uint)) error {
 	resizeTty := func() {
 		height, width := cli.Out().GetTtySize()
 		resizeTtyTo(ctx, cli.Client(), id, height, width, isExec)
+		if onResize != nil && height != 0 && width != 0 {
+			onResize(height, width)
+		}
 	}
 
 	resizeTty()
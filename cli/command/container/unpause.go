@@ -35,7 +35,7 @@ func runUnpause(dockerCli *command.DockerCli, opts *unpauseOptions) error {
 	ctx := context.Background()
 
 	var errs []string
-	errChan := parallelOperation(ctx, opts.containers, dockerCli.Client().ContainerUnpause)
+	errChan := parallelOperation(ctx, opts.containers, defaultParallel, dockerCli.Client().ContainerUnpause)
 	for _, container := range opts.containers {
 		if err := <-errChan; err != nil {
 			errs = append(errs, err.Error())
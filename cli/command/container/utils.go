@@ -2,6 +2,8 @@ package container
 
 import (
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/cli/cli/command"
@@ -10,6 +12,8 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/versions"
 	clientapi "github.com/docker/docker/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
 	"golang.org/x/net/context"
 )
 
@@ -109,12 +113,13 @@ func getExitCode(ctx context.Context, dockerCli *command.DockerCli, containerID
 	return c.State.Running, c.State.ExitCode, nil
 }
 
-func parallelOperation(ctx context.Context, containers []string, op func(ctx context.Context, container string) error) chan error {
+// parallelOperation runs op on the containers, at most maxParallel at once,
+// and returns their errors in the order of the containers.
+func parallelOperation(ctx context.Context, containers []string, maxParallel int, op func(ctx context.Context, container string) error) chan error {
 	if len(containers) == 0 {
 		return nil
 	}
-	const defaultParallel int = 50
-	sem := make(chan struct{}, defaultParallel)
+	sem := make(chan struct{}, maxParallel)
 	errChan := make(chan error)
 
 	// make sure result is printed in correct order
@@ -140,3 +145,89 @@ func parallelOperation(ctx context.Context, containers []string, op func(ctx con
 	}()
 	return errChan
 }
+
+// waitHealthyOptions are the options of the commands that can wait for
+// containers to be healthy after starting them.
+type waitHealthyOptions struct {
+	wait        bool
+	waitTimeout time.Duration
+}
+
+func addWaitHealthyFlags(flags *pflag.FlagSet, opts *waitHealthyOptions) {
+	flags.BoolVar(&opts.wait, "wait", false, "Wait for the container to be healthy, or running if it has no healthcheck")
+	flags.DurationVar(&opts.waitTimeout, "wait-timeout", 0, "Maximum time to wait with --wait (0 for no limit)")
+}
+
+// waitHealthy waits until the healthcheck of a container reports it
+// healthy, or until it is running if it has no healthcheck. It fails if the
+// container becomes unhealthy or exits, or if timeout is reached.
+func waitHealthy(ctx context.Context, dockerCli command.Cli, containerID string, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Subscribe to events before inspecting the container, so that no
+	// change of its state is missed.
+	eventCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	f := filters.NewArgs()
+	f.Add("type", "container")
+	f.Add("container", containerID)
+	eventq, errq := dockerCli.Client().Events(eventCtx, types.EventsOptions{Filters: f})
+
+	c, err := dockerCli.Client().ContainerInspect(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !c.State.Running:
+		return errors.Errorf("container %s exited with code %d%s", containerID, c.State.ExitCode, lastHealthcheckOutput(c))
+	case c.State.Health == nil:
+		return nil
+	case c.State.Health.Status == types.Healthy:
+		return nil
+	case c.State.Health.Status == types.Unhealthy:
+		return errors.Errorf("container %s is unhealthy%s", containerID, lastHealthcheckOutput(c))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return errors.Errorf("timed out waiting for container %s to be healthy", containerID)
+			}
+			return ctx.Err()
+		case err := <-errq:
+			return errors.Wrap(err, "error getting events from daemon")
+		case e := <-eventq:
+			switch e.Status {
+			case "health_status: " + types.Healthy:
+				return nil
+			case "health_status: " + types.Unhealthy:
+				c, _ := dockerCli.Client().ContainerInspect(ctx, containerID)
+				return errors.Errorf("container %s is unhealthy%s", containerID, lastHealthcheckOutput(c))
+			case "die":
+				c, _ := dockerCli.Client().ContainerInspect(ctx, containerID)
+				return errors.Errorf("container %s exited with code %s%s", containerID, e.Actor.Attributes["exitCode"], lastHealthcheckOutput(c))
+			case "destroy":
+				return errors.Errorf("container %s was removed", containerID)
+			}
+		}
+	}
+}
+
+// lastHealthcheckOutput returns the output of the last healthcheck of a
+// container, formatted to be appended to an error message.
+func lastHealthcheckOutput(c types.ContainerJSON) string {
+	if c.ContainerJSONBase == nil || c.State == nil || c.State.Health == nil || len(c.State.Health.Log) == 0 {
+		return ""
+	}
+	last := c.State.Health.Log[len(c.State.Health.Log)-1]
+	output := strings.TrimSpace(last.Output)
+	if output == "" {
+		return ""
+	}
+	return ": last healthcheck output: " + output
+}
@@ -0,0 +1,121 @@
+package container
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestLastHealthcheckOutput(t *testing.T) {
+	c := types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{}}}
+	assert.Equal(t, "", lastHealthcheckOutput(c))
+
+	c.State.Health = &types.Health{
+		Status: types.Unhealthy,
+		Log: []*types.HealthcheckResult{
+			{ExitCode: 0, Output: "ok"},
+			{ExitCode: 1, Output: "connection refused\n"},
+		},
+	}
+	assert.Equal(t, ": last healthcheck output: connection refused", lastHealthcheckOutput(c))
+}
+
+func containerWithHealth(running bool, health *types.Health) types.ContainerJSON {
+	return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+		State: &types.ContainerState{Running: running, Health: health},
+	}}
+}
+
+// healthClient is a fake client of a container whose successive inspections
+// return states, the last one being repeated, and whose events are sent to
+// messages.
+func healthClient(messages []events.Message, states ...types.ContainerJSON) *fakeClient {
+	return &fakeClient{
+		containerInspectFunc: func(container string) (types.ContainerJSON, error) {
+			c := states[0]
+			if len(states) > 1 {
+				states = states[1:]
+			}
+			return c, nil
+		},
+		eventsFunc: func(options types.EventsOptions) (<-chan events.Message, <-chan error) {
+			eventq := make(chan events.Message, len(messages))
+			for _, m := range messages {
+				eventq <- m
+			}
+			return eventq, make(chan error)
+		},
+	}
+}
+
+func TestWaitHealthy(t *testing.T) {
+	starting := &types.Health{Status: types.Starting}
+	unhealthy := &types.Health{
+		Status: types.Unhealthy,
+		Log:    []*types.HealthcheckResult{{ExitCode: 1, Output: "connection refused\n"}},
+	}
+	testCases := []struct {
+		doc           string
+		client        *fakeClient
+		timeout       time.Duration
+		expectedError string
+	}{
+		{
+			doc:    "no healthcheck",
+			client: healthClient(nil, containerWithHealth(true, nil)),
+		},
+		{
+			doc:    "already healthy",
+			client: healthClient(nil, containerWithHealth(true, &types.Health{Status: types.Healthy})),
+		},
+		{
+			doc: "healthy",
+			client: healthClient(
+				[]events.Message{{Status: "exec_start: curl"}, {Status: "health_status: healthy"}},
+				containerWithHealth(true, starting),
+			),
+		},
+		{
+			doc: "unhealthy",
+			client: healthClient(
+				[]events.Message{{Status: "health_status: unhealthy"}},
+				containerWithHealth(true, starting), containerWithHealth(true, unhealthy),
+			),
+			expectedError: "container web is unhealthy: last healthcheck output: connection refused",
+		},
+		{
+			doc:           "already unhealthy",
+			client:        healthClient(nil, containerWithHealth(true, unhealthy)),
+			expectedError: "container web is unhealthy: last healthcheck output: connection refused",
+		},
+		{
+			doc: "exited",
+			client: healthClient(
+				[]events.Message{{Status: "die", Actor: events.Actor{Attributes: map[string]string{"exitCode": "3"}}}},
+				containerWithHealth(true, starting), containerWithHealth(false, unhealthy),
+			),
+			expectedError: "container web exited with code 3: last healthcheck output: connection refused",
+		},
+		{
+			doc:           "timeout",
+			client:        healthClient(nil, containerWithHealth(true, starting)),
+			timeout:       10 * time.Millisecond,
+			expectedError: "timed out waiting for container web to be healthy",
+		},
+	}
+	for _, tc := range testCases {
+		err := waitHealthy(context.Background(), test.NewFakeCli(tc.client, new(bytes.Buffer)), "web", tc.timeout)
+		if tc.expectedError == "" {
+			assert.NoError(t, err, tc.doc)
+			continue
+		}
+		testutil.ErrorContains(t, err, tc.expectedError)
+	}
+}
@@ -0,0 +1,77 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	registry "github.com/docker/docker/api/types/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchContextDescription(t *testing.T) {
+	result := registry.SearchResult{Description: "Official build of Nginx,\nthe web server and reverse proxy, load balancer"}
+	ctx := searchContext{s: result, trunc: true}
+	assert.Equal(t, "Official build of Nginx, the web server an...", ctx.Description())
+	ctx = searchContext{s: result}
+	assert.Equal(t, "Official build of Nginx, the web server and reverse proxy, load balancer", ctx.Description())
+}
+
+func TestSearchContextWrite(t *testing.T) {
+	cases := []struct {
+		context  Context
+		expected string
+	}{
+		{
+			Context{Format: NewSearchFormat("table")},
+			`NAME                DESCRIPTION         STARS               OFFICIAL            AUTOMATED
+nginx               Web server          9000                [OK]                
+user/nginx          Custom nginx        3                                       [OK]
+`,
+		},
+		{
+			Context{Format: NewSearchFormat("table {{.Name}}\t{{.StarCount}}")},
+			`NAME                STARS
+nginx               9000
+user/nginx          3
+`,
+		},
+		{
+			Context{Format: NewSearchFormat("{{.Name}}")},
+			`nginx
+user/nginx
+`,
+		},
+	}
+
+	for _, testcase := range cases {
+		results := []registry.SearchResult{
+			{Name: "nginx", Description: "Web server", StarCount: 9000, IsOfficial: true},
+			{Name: "user/nginx", Description: "Custom nginx", StarCount: 3, IsAutomated: true},
+		}
+		out := bytes.NewBufferString("")
+		testcase.context.Output = out
+		require.NoError(t, SearchWrite(testcase.context, results))
+		assert.Equal(t, testcase.expected, out.String())
+	}
+}
+
+func TestSearchContextWriteJSON(t *testing.T) {
+	results := []registry.SearchResult{
+		{Name: "nginx", Description: "Web server", StarCount: 9000, IsOfficial: true},
+		{Name: "user/nginx", Description: "Custom nginx", StarCount: 3, IsAutomated: true},
+	}
+	expectedJSONs := []map[string]interface{}{
+		{"Name": "nginx", "Description": "Web server", "StarCount": "9000", "IsOfficial": "[OK]", "IsAutomated": ""},
+		{"Name": "user/nginx", "Description": "Custom nginx", "StarCount": "3", "IsOfficial": "", "IsAutomated": "[OK]"},
+	}
+	out := bytes.NewBufferString("")
+	require.NoError(t, SearchWrite(Context{Format: NewSearchFormat("json"), Output: out}, results))
+	for i, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &m))
+		assert.Equal(t, expectedJSONs[i], m)
+	}
+}
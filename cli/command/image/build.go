@@ -62,6 +62,8 @@ type buildOptions struct {
 	squash         bool
 	target         string
 	imageIDFile    string
+	printContext   bool
+	ignoreFile     string
 }
 
 // dockerfileFromStdin returns true when the user specified that the Dockerfile
@@ -127,6 +129,8 @@ func NewBuildCommand(dockerCli *command.DockerCli) *cobra.Command {
 	flags.Var(&options.extraHosts, "add-host", "Add a custom host-to-IP mapping (host:ip)")
 	flags.StringVar(&options.target, "target", "", "Set the target build stage to build.")
 	flags.StringVar(&options.imageIDFile, "iidfile", "", "Write the image ID to the file")
+	flags.StringVar(&options.ignoreFile, "ignore-file", "", "Name of the ignore file (Default is 'PATH/Dockerfile.dockerignore' if it exists, else 'PATH/.dockerignore')")
+	flags.BoolVar(&options.printContext, "print-context", false, "Print the files of the build context and the .dockerignore rules excluding them, without building")
 
 	command.AddTrustVerificationFlags(flags)
 
@@ -180,7 +184,16 @@ func runBuild(dockerCli *command.DockerCli, options buildOptions) error {
 		progBuff = bytes.NewBuffer(nil)
 		buildBuff = bytes.NewBuffer(nil)
 	}
-	if options.imageIDFile != "" {
+	// The files of the context are only selected by the CLI for local
+	// directories and Git repositories.
+	tarContext := options.contextFromStdin() || !isLocalDir(specifiedContext) && urlutil.IsURL(specifiedContext) && !urlutil.IsGitURL(specifiedContext)
+	if options.printContext && tarContext {
+		return errors.New("--print-context requires a local directory or a Git repository as build context")
+	}
+	if options.ignoreFile != "" && tarContext {
+		return errors.New("--ignore-file requires a local directory or a Git repository as build context")
+	}
+	if options.imageIDFile != "" && !options.printContext {
 		// Avoid leaving a stale file if we eventually fail
 		if err := os.Remove(options.imageIDFile); err != nil && !os.IsNotExist(err) {
 			return errors.Wrap(err, "Removing image ID file")
@@ -213,7 +226,16 @@ func runBuild(dockerCli *command.DockerCli, options buildOptions) error {
 	}
 
 	if buildCtx == nil {
-		excludes, err := build.ReadDockerignore(contextDir)
+		ignoreFile := options.ignoreFile
+		if ignoreFile == "" {
+			if ignoreFile, err = build.DockerignoreFile(contextDir, relDockerfile); err != nil {
+				return err
+			}
+		}
+		excludes, err := build.ReadIgnoreFile(ignoreFile)
+		if os.IsNotExist(err) && options.ignoreFile == "" {
+			ignoreFile, err = "", nil
+		}
 		if err != nil {
 			return err
 		}
@@ -228,8 +250,16 @@ func runBuild(dockerCli *command.DockerCli, options buildOptions) error {
 			return errors.Errorf("cannot canonicalize dockerfile path %s: %v", relDockerfile, err)
 		}
 
+		ignoreRules := len(excludes)
 		excludes = build.TrimBuildFilesFromExcludes(excludes, relDockerfile, options.dockerfileFromStdin())
 
+		if options.printContext {
+			if ignoreFile != "" {
+				fmt.Fprintf(dockerCli.Out(), "Ignore file: %s\n\n", ignoreFile)
+			}
+			return printBuildContext(dockerCli.Out(), contextDir, excludes, ignoreRules)
+		}
+
 		compression := archive.Uncompressed
 		if options.compress {
 			compression = archive.Gzip
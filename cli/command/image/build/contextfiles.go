@@ -0,0 +1,101 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/pkg/fileutils"
+)
+
+// ContextEntry is a path of a local build context, with the .dockerignore
+// rule deciding whether it is sent to the daemon
+type ContextEntry struct {
+	// Path is relative to the context directory, with slashes
+	Path     string
+	Size     int64
+	IsDir    bool
+	Excluded bool
+	// Rule is the index of the last exclude pattern matching the path, or
+	// one of its parent directories, or -1 if no pattern matches
+	Rule int
+}
+
+type excludeRule struct {
+	matcher   *fileutils.PatternMatcher
+	exclusion bool
+	pattern   string
+}
+
+// WalkContext returns the entries of a context directory, as they are
+// selected by archive.TarWithOptions with the excludes patterns. Files are
+// returned whether they are sent or excluded, but the content of an excluded
+// directory is only returned if an exception pattern may re-include part of
+// it.
+func WalkContext(contextDir string, excludes []string) ([]ContextEntry, error) {
+	contextRoot, err := getContextRoot(contextDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []excludeRule
+	for _, pattern := range excludes {
+		p := strings.TrimSpace(pattern)
+		exclusion := strings.HasPrefix(p, "!")
+		matcher, err := fileutils.NewPatternMatcher([]string{strings.TrimPrefix(p, "!")})
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, excludeRule{matcher: matcher, exclusion: exclusion, pattern: filepath.Clean(strings.TrimPrefix(p, "!"))})
+	}
+
+	var entries []ContextEntry
+	err = filepath.Walk(contextRoot, func(filePath string, f os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		relFilePath, err := filepath.Rel(contextRoot, filePath)
+		if err != nil || relFilePath == "." {
+			return err
+		}
+
+		entry := ContextEntry{Path: filepath.ToSlash(relFilePath), IsDir: f.IsDir(), Rule: -1}
+		if f.Mode().IsRegular() {
+			entry.Size = f.Size()
+		}
+		for i, rule := range rules {
+			// Empty patterns are ignored, as by archive.TarWithOptions.
+			if len(rule.matcher.Patterns()) == 0 {
+				continue
+			}
+			if match, err := rule.matcher.Matches(relFilePath); err != nil {
+				return err
+			} else if match {
+				entry.Rule = i
+				entry.Excluded = !rule.exclusion
+			}
+		}
+		entries = append(entries, entry)
+
+		if entry.Excluded && f.IsDir() && !mayReinclude(rules, relFilePath) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// mayReinclude returns whether an exception pattern starts with the
+// directory, in which case archive.TarWithOptions walks its content.
+func mayReinclude(rules []excludeRule, dir string) bool {
+	dirSlash := dir + string(filepath.Separator)
+	for _, rule := range rules {
+		if rule.exclusion && strings.HasPrefix(rule.pattern+string(filepath.Separator), dirSlash) {
+			return true
+		}
+	}
+	return false
+}
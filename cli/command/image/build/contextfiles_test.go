@@ -0,0 +1,35 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkContext(t *testing.T) {
+	contextDir, cleanup := createTestTempDir(t, "", "builder-context-test")
+	defer cleanup()
+
+	createTestTempFile(t, contextDir, DefaultDockerfileName, dockerfileContents, 0777)
+	createTestTempFile(t, contextDir, "main.go", "package main", 0777)
+	for _, dir := range []string{"logs", "node_modules/left-pad"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(contextDir, dir), 0777))
+	}
+	createTestTempFile(t, contextDir, "logs/build.log", "log", 0777)
+	createTestTempFile(t, contextDir, "logs/keep.log", "keep", 0777)
+	createTestTempFile(t, contextDir, "node_modules/left-pad/index.js", "module", 0777)
+
+	entries, err := WalkContext(contextDir, []string{"logs", "!logs/keep.log", "node_modules", "Dockerfile", "!Dockerfile"})
+	require.NoError(t, err)
+	assert.Equal(t, []ContextEntry{
+		{Path: "Dockerfile", Size: int64(len(dockerfileContents)), Rule: 4},
+		{Path: "logs", IsDir: true, Excluded: true, Rule: 0},
+		{Path: "logs/build.log", Size: 3, Excluded: true, Rule: 0},
+		{Path: "logs/keep.log", Size: 4, Rule: 1},
+		{Path: "main.go", Size: 12, Rule: -1},
+		{Path: "node_modules", IsDir: true, Excluded: true, Rule: 2},
+	}, entries)
+}
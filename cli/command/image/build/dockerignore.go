@@ -1,28 +1,117 @@
 package build
 
 import (
+	"bufio"
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/docker/docker/builder/dockerignore"
 	"github.com/docker/docker/pkg/fileutils"
+	"github.com/pkg/errors"
 )
 
+// includeDirective starts the lines of an ignore file including the patterns
+// of another ignore file, at the position of the line. These lines are
+// comments for the daemon.
+const includeDirective = "#include "
+
 // ReadDockerignore reads the .dockerignore file in the context directory and
 // returns the list of paths to exclude
 func ReadDockerignore(contextDir string) ([]string, error) {
-	var excludes []string
+	excludes, err := ReadIgnoreFile(filepath.Join(contextDir, ".dockerignore"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return excludes, err
+}
 
-	f, err := os.Open(filepath.Join(contextDir, ".dockerignore"))
-	switch {
-	case os.IsNotExist(err):
-		return excludes, nil
-	case err != nil:
+// DockerignoreFile returns the ignore file of a build: <Dockerfile>.dockerignore
+// next to the Dockerfile if it exists, or else the .dockerignore file of the
+// context directory
+func DockerignoreFile(contextDir, relDockerfile string) (string, error) {
+	if relDockerfile != "" && relDockerfile != "-" {
+		ignoreFile := filepath.Join(contextDir, relDockerfile) + ".dockerignore"
+		_, err := os.Stat(ignoreFile)
+		if err == nil {
+			return ignoreFile, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return filepath.Join(contextDir, ".dockerignore"), nil
+}
+
+// ReadIgnoreFile reads an ignore file, with the files it includes, and
+// returns the list of paths to exclude. Included files are relative to the
+// directory of the including file.
+func ReadIgnoreFile(path string) ([]string, error) {
+	return readIgnoreFile(path, nil)
+}
+
+func readIgnoreFile(path string, including []string) ([]string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range including {
+		if p == path {
+			return nil, errors.Errorf("include cycle: %s", strings.Join(append(including, path), " -> "))
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	return dockerignore.ReadAll(f)
+	var (
+		excludes []string
+		lines    bytes.Buffer
+	)
+	// The lines between the include directives are read as an ignore file.
+	flush := func() error {
+		patterns, err := dockerignore.ReadAll(&lines)
+		excludes = append(excludes, patterns...)
+		lines.Reset()
+		return err
+	}
+	scanner := bufio.NewScanner(f)
+	for first := true; scanner.Scan(); first = false {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if !strings.HasPrefix(line, includeDirective) {
+			lines.WriteString(line + "\n")
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		included := strings.TrimSpace(strings.TrimPrefix(line, includeDirective))
+		if !filepath.IsAbs(included) {
+			included = filepath.Join(filepath.Dir(path), included)
+		}
+		patterns, err := readIgnoreFile(included, append(including, path))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, errors.Errorf("%s: included file %s does not exist", path, included)
+			}
+			return nil, err
+		}
+		excludes = append(excludes, patterns...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Errorf("Error reading %s: %v", path, err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return excludes, nil
 }
 
 // TrimBuildFilesFromExcludes removes the named Dockerfile and .dockerignore from
@@ -0,0 +1,56 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerignoreFile(t *testing.T) {
+	contextDir, cleanup := createTestTempDir(t, "", "builder-context-test")
+	defer cleanup()
+	require.NoError(t, os.Mkdir(filepath.Join(contextDir, "web"), 0777))
+	createTestTempFile(t, contextDir, "web/Dockerfile", dockerfileContents, 0777)
+	createTestTempFile(t, contextDir, "web/Dockerfile.dockerignore", "*.md", 0777)
+
+	ignoreFile, err := DockerignoreFile(contextDir, filepath.Join("web", "Dockerfile"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(contextDir, "web", "Dockerfile.dockerignore"), ignoreFile)
+
+	ignoreFile, err = DockerignoreFile(contextDir, DefaultDockerfileName)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(contextDir, ".dockerignore"), ignoreFile)
+
+	ignoreFile, err = DockerignoreFile(contextDir, "-")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(contextDir, ".dockerignore"), ignoreFile)
+}
+
+func TestReadIgnoreFileInclude(t *testing.T) {
+	contextDir, cleanup := createTestTempDir(t, "", "builder-context-test")
+	defer cleanup()
+	require.NoError(t, os.Mkdir(filepath.Join(contextDir, "api"), 0777))
+	createTestTempFile(t, contextDir, ".dockerignore", "\ufeff.git\n# comment\n*.md\n", 0777)
+	createTestTempFile(t, contextDir, "api/Dockerfile.dockerignore", "#include ../.dockerignore\n!README.md\nweb/\n", 0777)
+
+	excludes, err := ReadIgnoreFile(filepath.Join(contextDir, "api", "Dockerfile.dockerignore"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{".git", "*.md", "!README.md", "web"}, excludes)
+
+	excludes, err = ReadDockerignore(filepath.Join(contextDir, "api"))
+	require.NoError(t, err)
+	assert.Nil(t, excludes)
+
+	createTestTempFile(t, contextDir, "a.dockerignore", "#include b.dockerignore\n", 0777)
+	createTestTempFile(t, contextDir, "b.dockerignore", "#include a.dockerignore\n", 0777)
+	_, err = ReadIgnoreFile(filepath.Join(contextDir, "a.dockerignore"))
+	testutil.ErrorContains(t, err, "include cycle: ")
+
+	createTestTempFile(t, contextDir, "c.dockerignore", "#include missing.dockerignore\n", 0777)
+	_, err = ReadIgnoreFile(filepath.Join(contextDir, "c.dockerignore"))
+	testutil.ErrorContains(t, err, "missing.dockerignore does not exist")
+}
@@ -0,0 +1,92 @@
+package image
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/docker/cli/cli/command/image/build"
+	units "github.com/docker/go-units"
+)
+
+// largestDirectories is the number of directories shown by
+// printBuildContext.
+const largestDirectories = 10
+
+// printBuildContext prints the files of a local build context which would
+// be sent to the daemon, and the excluded ones, with the exclude pattern
+// deciding it. The patterns from ignoreRules on are the ones added to keep
+// the Dockerfile and .dockerignore in the context.
+func printBuildContext(out io.Writer, contextDir string, excludes []string, ignoreRules int) error {
+	entries, err := build.WalkContext(contextDir, excludes)
+	if err != nil {
+		return err
+	}
+
+	var (
+		files, excluded int
+		total           int64
+		dirSizes        = make(map[string]int64)
+	)
+	w := tabwriter.NewWriter(out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "PATH\tSIZE\tSTATUS\tRULE")
+	for _, entry := range entries {
+		if entry.IsDir && !entry.Excluded {
+			continue
+		}
+		name, size, status, rule := entry.Path, units.HumanSizeWithPrecision(float64(entry.Size), 3), "sent", ""
+		if entry.IsDir {
+			name, size = name+"/", "-"
+		}
+		if entry.Excluded {
+			status = "excluded"
+			excluded++
+		} else {
+			files++
+			total += entry.Size
+			for dir := path.Dir(entry.Path); dir != "."; dir = path.Dir(dir) {
+				dirSizes[dir] += entry.Size
+			}
+		}
+		if entry.Rule >= 0 {
+			rule = excludes[entry.Rule]
+			if entry.Rule >= ignoreRules {
+				rule += " (kept by docker build)"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, size, status, rule)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(dirSizes) > 0 {
+		var dirs []string
+		for dir := range dirSizes {
+			dirs = append(dirs, dir)
+		}
+		sort.Slice(dirs, func(i, j int) bool {
+			if dirSizes[dirs[i]] != dirSizes[dirs[j]] {
+				return dirSizes[dirs[i]] > dirSizes[dirs[j]]
+			}
+			return dirs[i] < dirs[j]
+		})
+		if len(dirs) > largestDirectories {
+			dirs = dirs[:largestDirectories]
+		}
+		fmt.Fprintln(out)
+		w = tabwriter.NewWriter(out, 20, 1, 3, ' ', 0)
+		fmt.Fprintln(w, "DIRECTORY\tSIZE")
+		for _, dir := range dirs {
+			fmt.Fprintf(w, "%s/\t%s\n", dir, units.HumanSizeWithPrecision(float64(dirSizes[dir]), 3))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "\nTotal: %d files to send (%s), %d paths excluded\n", files, units.HumanSizeWithPrecision(float64(total), 3), excluded)
+	return nil
+}
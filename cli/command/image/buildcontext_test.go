@@ -0,0 +1,39 @@
+package image
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/pkg/testutil"
+	"github.com/docker/docker/pkg/testutil/golden"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintBuildContext(t *testing.T) {
+	contextDir, err := ioutil.TempDir("", "build-context")
+	require.NoError(t, err)
+	defer os.RemoveAll(contextDir)
+
+	files := map[string]string{
+		"Dockerfile":            "FROM busybox",
+		".dockerignore":         "*.md\n",
+		"README.md":             "readme",
+		"src/app/main.go":       "package main",
+		"src/lib/lib.go":        "package lib\n\nfunc Lib() {}",
+		"vendor/dep/dep.go":     "package dep",
+		"vendor/dep/README.txt": "dep",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(contextDir, name)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(contextDir, name), []byte(content), 0644))
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, printBuildContext(buf, contextDir, []string{"*.md", "vendor/dep/*.txt", "!.dockerignore"}, 2))
+	actual := buf.String()
+	expected := golden.Get(t, []byte(actual), "build-print-context.golden")
+	testutil.EqualNormalizedString(t, testutil.RemoveSpace, actual, string(expected))
+}
@@ -15,18 +15,19 @@ import (
 
 type fakeClient struct {
 	client.Client
-	imageTagFunc     func(string, string) error
-	imageSaveFunc    func(images []string) (io.ReadCloser, error)
-	imageRemoveFunc  func(image string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
-	imagePushFunc    func(ref string, options types.ImagePushOptions) (io.ReadCloser, error)
-	infoFunc         func() (types.Info, error)
-	imagePullFunc    func(ref string, options types.ImagePullOptions) (io.ReadCloser, error)
-	imagesPruneFunc  func(pruneFilter filters.Args) (types.ImagesPruneReport, error)
-	imageLoadFunc    func(input io.Reader, quiet bool) (types.ImageLoadResponse, error)
-	imageListFunc    func(options types.ImageListOptions) ([]types.ImageSummary, error)
-	imageInspectFunc func(image string) (types.ImageInspect, []byte, error)
-	imageImportFunc  func(source types.ImageImportSource, ref string, options types.ImageImportOptions) (io.ReadCloser, error)
-	imageHistoryFunc func(image string) ([]image.HistoryResponseItem, error)
+	imageTagFunc      func(string, string) error
+	imageSaveFunc     func(images []string) (io.ReadCloser, error)
+	imageRemoveFunc   func(image string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
+	imagePushFunc     func(ref string, options types.ImagePushOptions) (io.ReadCloser, error)
+	infoFunc          func() (types.Info, error)
+	imagePullFunc     func(ref string, options types.ImagePullOptions) (io.ReadCloser, error)
+	imagesPruneFunc   func(pruneFilter filters.Args) (types.ImagesPruneReport, error)
+	imageLoadFunc     func(input io.Reader, quiet bool) (types.ImageLoadResponse, error)
+	imageListFunc     func(options types.ImageListOptions) ([]types.ImageSummary, error)
+	imageInspectFunc  func(image string) (types.ImageInspect, []byte, error)
+	imageImportFunc   func(source types.ImageImportSource, ref string, options types.ImageImportOptions) (io.ReadCloser, error)
+	imageHistoryFunc  func(image string) ([]image.HistoryResponseItem, error)
+	containerListFunc func(options types.ContainerListOptions) ([]types.Container, error)
 }
 
 func (cli *fakeClient) ImageTag(_ context.Context, image, ref string) error {
@@ -114,3 +115,10 @@ func (cli *fakeClient) ImageHistory(_ context.Context, img string) ([]image.Hist
 	}
 	return []image.HistoryResponseItem{{ID: img, Created: time.Now().Unix()}}, nil
 }
+
+func (cli *fakeClient) ContainerList(_ context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	if cli.containerListFunc != nil {
+		return cli.containerListFunc(options)
+	}
+	return []types.Container{}, nil
+}
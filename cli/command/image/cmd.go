@@ -18,9 +18,11 @@ func NewImageCommand(dockerCli *command.DockerCli) *cobra.Command {
 	}
 	cmd.AddCommand(
 		NewBuildCommand(dockerCli),
+		NewDiffCommand(dockerCli),
 		NewHistoryCommand(dockerCli),
 		NewImportCommand(dockerCli),
 		NewLoadCommand(dockerCli),
+		NewMirrorCommand(dockerCli),
 		NewPullCommand(dockerCli),
 		NewPushCommand(dockerCli),
 		NewSaveCommand(dockerCli),
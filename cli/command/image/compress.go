@@ -0,0 +1,105 @@
+package image
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	compressionGzip = "gzip"
+	compressionZstd = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+func validateCompression(compression string) error {
+	switch compression {
+	case "", compressionGzip, compressionZstd:
+		return nil
+	}
+	return errors.Errorf("invalid compression %q: must be %q or %q", compression, compressionGzip, compressionZstd)
+}
+
+// compressReader returns the content of r compressed with compression.
+// There is no zstd implementation in Go available here, so the zstd command
+// is used.
+func compressReader(r io.Reader, compression string) (io.ReadCloser, error) {
+	if compression == compressionZstd {
+		return zstdCommand(r, "-c", "-q")
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		gz := gzip.NewWriter(pw)
+		_, err := io.Copy(gz, r)
+		if closeErr := gz.Close(); err == nil {
+			err = closeErr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// decompressReader returns the content of r decompressed, if it is
+// compressed with gzip or zstd.
+func decompressReader(r *bufio.Reader) (io.ReadCloser, error) {
+	magic, _ := r.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(r)
+	case bytes.HasPrefix(magic, zstdMagic):
+		return zstdCommand(r, "-d", "-c", "-q")
+	}
+	return readCloser{Reader: r, close: func() error { return nil }}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error {
+	return r.close()
+}
+
+// zstdCommand runs the zstd command with args on the content of r, and
+// returns its output.
+func zstdCommand(r io.Reader, args ...string) (io.ReadCloser, error) {
+	path, err := exec.LookPath("zstd")
+	if err != nil {
+		return nil, errors.New("zstd compression requires the zstd command, which was not found in PATH")
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = r
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := io.Copy(pw, out)
+		if err != nil {
+			// Let zstd exit if the output is no longer read.
+			out.Close()
+		}
+		if waitErr := cmd.Wait(); waitErr != nil {
+			err = errors.Errorf("zstd failed: %v %s", waitErr, bytes.TrimSpace(stderr.Bytes()))
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
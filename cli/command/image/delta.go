@@ -0,0 +1,256 @@
+package image
+
+import (
+	"archive/tar"
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// imageRootFS is the part of an image configuration listing its layers.
+type imageRootFS struct {
+	RootFS struct {
+		DiffIDs []digest.Digest `json:"diff_ids"`
+	} `json:"rootfs"`
+}
+
+// layerPaths returns the paths of the layers of the images of manifest, by
+// the digests of their content. A layer shared by several images may have
+// several paths. readConfig returns the configuration of an image by its
+// path.
+func layerPaths(manifest []legacyManifestItem, readConfig func(name string) ([]byte, error)) (map[digest.Digest][]string, error) {
+	paths := make(map[digest.Digest][]string)
+	for _, item := range manifest {
+		content, err := readConfig(item.Config)
+		if err != nil {
+			return nil, err
+		}
+		var config imageRootFS
+		if err := json.Unmarshal(content, &config); err != nil {
+			return nil, errors.Wrapf(err, "invalid image configuration %s", item.Config)
+		}
+		if len(config.RootFS.DiffIDs) != len(item.Layers) {
+			return nil, errors.Errorf("image configuration %s does not match its %d layers", item.Config, len(item.Layers))
+		}
+		for i, diffID := range config.RootFS.DiffIDs {
+			paths[diffID] = append(paths[diffID], item.Layers[i])
+		}
+	}
+	return paths, nil
+}
+
+// archiveLayers returns the digests of the layers of a `docker save`
+// archive, possibly compressed, without extracting it.
+func archiveLayers(filename string) (map[digest.Digest]bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := decompressReader(bufio.NewReader(f))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// The manifest and the image configurations are the JSON files at the
+	// root of the archive.
+	files := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid archive %s", filename)
+		}
+		name := path.Clean(hdr.Name)
+		if path.Dir(name) != "." || path.Ext(name) != ".json" {
+			continue
+		}
+		if files[name], err = ioutil.ReadAll(tr); err != nil {
+			return nil, err
+		}
+	}
+
+	var manifest []legacyManifestItem
+	if err := json.Unmarshal(files[legacyManifestFile], &manifest); err != nil {
+		return nil, errors.Errorf("invalid archive %s: no valid %s", filename, legacyManifestFile)
+	}
+	paths, err := layerPaths(manifest, func(name string) ([]byte, error) {
+		content, ok := files[path.Clean(name)]
+		if !ok {
+			return nil, errors.Errorf("invalid archive %s: missing image configuration %s", filename, name)
+		}
+		return content, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	layers := make(map[digest.Digest]bool)
+	for diffID := range paths {
+		layers[diffID] = true
+	}
+	return layers, nil
+}
+
+// excludedLayers returns the layers not to save, because they are in the
+// archive given with --exclude-layers-from or in the image given with
+// --since.
+func excludedLayers(ctx context.Context, dockerCli command.Cli, opts saveOptions) (map[digest.Digest]bool, error) {
+	excluded := make(map[digest.Digest]bool)
+	if opts.excludeLayersFrom != "" {
+		layers, err := archiveLayers(opts.excludeLayersFrom)
+		if err != nil {
+			return nil, err
+		}
+		excluded = layers
+	}
+	if opts.since != "" {
+		image, _, err := dockerCli.Client().ImageInspectWithRaw(ctx, opts.since)
+		if err != nil {
+			return nil, err
+		}
+		for _, layer := range image.RootFS.Layers {
+			excluded[digest.Digest(layer)] = true
+		}
+	}
+	return excluded, nil
+}
+
+// deltaArchive returns the `docker save` archive read from r without the
+// layers of excluded, and a function to call once done. The manifest still
+// lists all the layers: `docker load` only reads the layers it does not
+// have already, and `docker load --base` restores the others.
+func deltaArchive(r io.Reader, excluded map[digest.Digest]bool) (io.Reader, func(), error) {
+	dir, err := ioutil.TempDir("", "docker-save-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	paths, err := extractLegacyArchive(r, dir)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	for diffID, layers := range paths {
+		if !excluded[diffID] {
+			continue
+		}
+		for _, layer := range layers {
+			if err := os.Remove(archivePath(dir, layer)); err != nil && !os.IsNotExist(err) {
+				cleanup()
+				return nil, nil, err
+			}
+		}
+	}
+
+	tr := tarDirectoryReader(dir)
+	return tr, func() {
+		tr.Close()
+		cleanup()
+	}, nil
+}
+
+// restoreBaseLayers adds the layers missing from the `docker save` archive
+// extracted in dir from the archive base, possibly compressed.
+func restoreBaseLayers(dir string, base string) error {
+	var manifest []legacyManifestItem
+	if err := readJSONFile(filepath.Join(dir, legacyManifestFile), &manifest); err != nil {
+		return errors.Wrap(err, "--base can only be used to load archives in the docker format")
+	}
+	paths, err := layerPaths(manifest, readArchiveFile(dir))
+	if err != nil {
+		return err
+	}
+	missing := make(map[digest.Digest][]string)
+	for diffID, layers := range paths {
+		for _, layer := range layers {
+			if _, err := os.Stat(archivePath(dir, layer)); os.IsNotExist(err) {
+				missing[diffID] = append(missing[diffID], layer)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	baseDir, err := ioutil.TempDir("", "docker-load-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(baseDir)
+	f, err := os.Open(base)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	basePaths, err := extractLegacyArchive(f, baseDir)
+	if err != nil {
+		return errors.Wrapf(err, "invalid base archive %s", base)
+	}
+
+	for diffID, layers := range missing {
+		baseLayers, ok := basePaths[diffID]
+		if !ok {
+			return errors.Errorf("layer %s is neither in the archive nor in the base archive %s", diffID, base)
+		}
+		for _, layer := range layers {
+			if err := copyLayer(archivePath(baseDir, baseLayers[0]), dir, layer); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyLayer copies the layer src to the path layer of the archive extracted
+// in dir.
+func copyLayer(src, dir, layer string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	target := archivePath(dir, layer)
+	// target may be a link to a layer that is missing too.
+	os.Remove(target)
+	if err := checkArchivePath(dir, target); err != nil {
+		return err
+	}
+	return extractFile(in, target)
+}
+
+// extractLegacyArchive extracts a `docker save` archive, possibly
+// compressed, to dir, and returns the paths of its layers.
+func extractLegacyArchive(r io.Reader, dir string) (map[digest.Digest][]string, error) {
+	decompressed, err := decompressReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+	defer decompressed.Close()
+	if err := extractTar(decompressed, dir); err != nil {
+		return nil, err
+	}
+	var manifest []legacyManifestItem
+	if err := readJSONFile(filepath.Join(dir, legacyManifestFile), &manifest); err != nil {
+		return nil, err
+	}
+	return layerPaths(manifest, readArchiveFile(dir))
+}
+
+func readArchiveFile(dir string) func(name string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		return ioutil.ReadFile(archivePath(dir, name))
+	}
+}
@@ -0,0 +1,270 @@
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// layeredArchive returns an archive in the format of `docker save` of an
+// image with the given layers.
+func layeredArchive(t *testing.T, layers ...[]byte) []byte {
+	var diffIDs, paths []string
+	var entries []tarEntry
+	for _, layer := range layers {
+		diffID := digest.FromBytes(layer)
+		path := diffID.Hex() + "/layer.tar"
+		diffIDs = append(diffIDs, diffID.String())
+		paths = append(paths, path)
+		entries = append(entries, tarEntry{name: path, content: layer})
+	}
+	rootfs, err := json.Marshal(diffIDs)
+	require.NoError(t, err)
+	config := []byte(`{"os":"linux","rootfs":{"type":"layers","diff_ids":` + string(rootfs) + `}}`)
+	configName := digest.FromBytes(config).Hex() + ".json"
+	manifest, err := json.Marshal([]legacyManifestItem{{Config: configName, RepoTags: []string{"app:latest"}, Layers: paths}})
+	require.NoError(t, err)
+
+	entries = append(entries,
+		tarEntry{name: configName, content: config},
+		tarEntry{name: legacyManifestFile, content: manifest},
+	)
+	return makeTar(t, entries...)
+}
+
+func TestSaveDeltaAndLoadWithBase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "delta")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	baseLayer := makeTar(t, tarEntry{name: "base.txt", content: []byte("base")})
+	appLayer := makeTar(t, tarEntry{name: "app.txt", content: []byte("app")})
+	baseFile := filepath.Join(dir, "base.tar")
+	require.NoError(t, ioutil.WriteFile(baseFile, layeredArchive(t, baseLayer), 0644))
+
+	archive := layeredArchive(t, baseLayer, appLayer)
+	client := &fakeClient{
+		imageSaveFunc: func(images []string) (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(archive)), nil
+		},
+	}
+	deltaFile := filepath.Join(dir, "delta.tar.gz")
+	cmd := NewSaveCommand(test.NewFakeCli(client, new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--exclude-layers-from", baseFile, "--compress", "gzip", "-o", deltaFile, "app"})
+	require.NoError(t, cmd.Execute())
+
+	f, err := os.Open(deltaFile)
+	require.NoError(t, err)
+	defer f.Close()
+	decompressed, err := decompressReader(bufioReader(mustReadAll(t, f)))
+	require.NoError(t, err)
+	saved := readTar(t, decompressed)
+	assert.NotContains(t, saved, digest.FromBytes(baseLayer).Hex()+"/layer.tar")
+	assert.Equal(t, appLayer, saved[digest.FromBytes(appLayer).Hex()+"/layer.tar"])
+	assert.Contains(t, saved, legacyManifestFile)
+
+	var loaded map[string][]byte
+	client = &fakeClient{
+		imageLoadFunc: func(input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+			loaded = readTar(t, input)
+			return types.ImageLoadResponse{Body: ioutil.NopCloser(new(bytes.Buffer))}, nil
+		},
+	}
+	cmd = NewLoadCommand(test.NewFakeCli(client, new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--base", baseFile, "-i", deltaFile})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, baseLayer, loaded[digest.FromBytes(baseLayer).Hex()+"/layer.tar"])
+	assert.Equal(t, appLayer, loaded[digest.FromBytes(appLayer).Hex()+"/layer.tar"])
+}
+
+func TestLoadWithBaseMissingLayer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "delta")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	layer := makeTar(t, tarEntry{name: "app.txt", content: []byte("app")})
+	deltaDir := filepath.Join(dir, "delta")
+	require.NoError(t, extractTar(bytes.NewReader(layeredArchive(t, layer)), deltaDir))
+	require.NoError(t, os.Remove(filepath.Join(deltaDir, digest.FromBytes(layer).Hex(), "layer.tar")))
+
+	otherLayer := makeTar(t, tarEntry{name: "other.txt", content: []byte("other")})
+	baseFile := filepath.Join(dir, "base.tar")
+	require.NoError(t, ioutil.WriteFile(baseFile, layeredArchive(t, otherLayer), 0644))
+
+	err = restoreBaseLayers(deltaDir, baseFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is neither in the archive nor in the base archive")
+}
+
+func TestLoadWithBaseHostileArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "delta")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	layer := makeTar(t, tarEntry{name: "app.txt", content: []byte("app")})
+	outside := filepath.Join(dir, "outside")
+	hostile := func(archive []byte) []byte {
+		entries := []tarEntry{
+			{name: "0123abcd", typeflag: tar.TypeSymlink, linkname: relativeToRoot(outside)},
+			{name: "0123abcd/layer.tar", content: []byte("hostile")},
+		}
+		for name, content := range readTar(t, bytes.NewReader(archive)) {
+			entries = append(entries, tarEntry{name: name, content: content})
+		}
+		return makeTar(t, entries...)
+	}
+	// The delta archive has the configuration and manifest, but not the
+	// layer, which is restored from the base archive.
+	var delta []tarEntry
+	for name, content := range readTar(t, bytes.NewReader(layeredArchive(t, layer))) {
+		if path.Base(name) != "layer.tar" {
+			delta = append(delta, tarEntry{name: name, content: content})
+		}
+	}
+
+	testCases := []struct {
+		doc           string
+		delta         []byte
+		base          []byte
+		expectedError string
+	}{
+		{
+			doc:           "hostile archive",
+			delta:         hostile(layeredArchive(t, layer)),
+			base:          layeredArchive(t, layer),
+			expectedError: "invalid link 0123abcd",
+		},
+		{
+			doc:           "hostile base archive",
+			delta:         makeTar(t, delta...),
+			base:          hostile(layeredArchive(t, layer)),
+			expectedError: "invalid base archive",
+		},
+	}
+	for _, tc := range testCases {
+		deltaFile := filepath.Join(dir, "delta.tar")
+		require.NoError(t, ioutil.WriteFile(deltaFile, tc.delta, 0644))
+		baseFile := filepath.Join(dir, "base.tar")
+		require.NoError(t, ioutil.WriteFile(baseFile, tc.base, 0644))
+
+		cmd := NewLoadCommand(test.NewFakeCli(&fakeClient{}, new(bytes.Buffer)))
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs([]string{"--base", baseFile, "-i", deltaFile})
+		err := cmd.Execute()
+		if assert.Error(t, err, tc.doc) {
+			assert.Contains(t, err.Error(), tc.expectedError, tc.doc)
+		}
+		_, err = os.Stat(outside)
+		assert.True(t, os.IsNotExist(err), tc.doc)
+	}
+
+	// The layers listed in the manifest are rooted at the archive too.
+	diffID := digest.FromBytes(layer)
+	config := []byte(`{"os":"linux","rootfs":{"type":"layers","diff_ids":["` + diffID.String() + `"]}}`)
+	configName := diffID.Hex() + ".json"
+	manifest := `[{"Config":"` + configName + `","Layers":["../../outside/layer.tar"]}]`
+	deltaFile := filepath.Join(dir, "delta.tar")
+	require.NoError(t, ioutil.WriteFile(deltaFile, makeTar(t,
+		tarEntry{name: configName, content: config},
+		tarEntry{name: legacyManifestFile, content: []byte(manifest)},
+	), 0644))
+	baseFile := filepath.Join(dir, "base.tar")
+	require.NoError(t, ioutil.WriteFile(baseFile, layeredArchive(t, layer), 0644))
+
+	var loaded map[string][]byte
+	client := &fakeClient{
+		imageLoadFunc: func(input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+			loaded = readTar(t, input)
+			return types.ImageLoadResponse{Body: ioutil.NopCloser(new(bytes.Buffer))}, nil
+		},
+	}
+	cmd := NewLoadCommand(test.NewFakeCli(client, new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--base", baseFile, "-i", deltaFile})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, layer, loaded["outside/layer.tar"])
+	_, err = os.Stat(outside)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSaveDeltaHostileArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "delta")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	layer := makeTar(t, tarEntry{name: "app.txt", content: []byte("app")})
+	baseFile := filepath.Join(dir, "base.tar")
+	require.NoError(t, ioutil.WriteFile(baseFile, layeredArchive(t, layer), 0644))
+	outside := filepath.Join(dir, "outside")
+	archive := makeTar(t,
+		tarEntry{name: "0123abcd", typeflag: tar.TypeSymlink, linkname: relativeToRoot(outside)},
+		tarEntry{name: "0123abcd/layer.tar", content: []byte("hostile")},
+	)
+	client := &fakeClient{
+		imageSaveFunc: func(images []string) (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(archive)), nil
+		},
+	}
+	cmd := NewSaveCommand(test.NewFakeCli(client, new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--exclude-layers-from", baseFile, "-o", filepath.Join(dir, "delta.tar"), "app"})
+	err = cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid link 0123abcd")
+	_, err = os.Stat(outside)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCompressReaderGzip(t *testing.T) {
+	content := []byte("some archive content")
+	compressed, err := compressReader(bytes.NewReader(content), compressionGzip)
+	require.NoError(t, err)
+	defer compressed.Close()
+
+	decompressed, err := decompressReader(bufioReader(mustReadAll(t, compressed)))
+	require.NoError(t, err)
+	assert.Equal(t, content, mustReadAll(t, decompressed))
+
+	// Content that is not compressed is read as is.
+	decompressed, err = decompressReader(bufioReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, content, mustReadAll(t, decompressed))
+}
+
+func TestSaveInvalidOptions(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--compress", "xz", "-o", "out.tar", "app"}, `invalid compression "xz"`},
+		{[]string{"--format", "oci", "--since", "base", "-o", "out.tar", "app"}, "cannot be used with --format oci"},
+	}
+	for _, tc := range testCases {
+		cmd := NewSaveCommand(test.NewFakeCli(&fakeClient{}, new(bytes.Buffer)))
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs(tc.args)
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tc.expected)
+	}
+}
+
+func mustReadAll(t *testing.T, r io.Reader) []byte {
+	content, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	return content
+}
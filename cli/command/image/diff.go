@@ -0,0 +1,482 @@
+package image
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/go-units"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = whiteoutPrefix + whiteoutPrefix + ".opq"
+)
+
+type diffOptions struct {
+	images  []string
+	files   bool
+	noTrunc bool
+}
+
+// NewDiffCommand creates a new `docker image diff` command
+func NewDiffCommand(dockerCli command.Cli) *cobra.Command {
+	var opts diffOptions
+
+	cmd := &cobra.Command{
+		Use:   "diff [OPTIONS] IMAGE1 IMAGE2",
+		Short: "Show the differences between two images",
+		Long: "Show the differences between the configurations and the layers of two images.\n\n" +
+			"With --files, the layers of both images are read to list the paths added (A),\n" +
+			"changed (C) and deleted (D) from IMAGE1 to IMAGE2.",
+		Args: cli.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.images = args
+			return runDiff(dockerCli, opts)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.BoolVar(&opts.files, "files", false, "List the files added, changed and deleted")
+	flags.BoolVar(&opts.noTrunc, "no-trunc", false, "Don't truncate output")
+
+	return cmd
+}
+
+func runDiff(dockerCli command.Cli, opts diffOptions) error {
+	ctx := context.Background()
+	client := dockerCli.Client()
+
+	var images [2]types.ImageInspect
+	for i, ref := range opts.images {
+		inspect, _, err := client.ImageInspectWithRaw(ctx, ref)
+		if err != nil {
+			return err
+		}
+		images[i] = inspect
+	}
+
+	var (
+		sizes   = make(map[string]int64)
+		changes []fileChange
+	)
+	if opts.files {
+		archive, err := client.ImageSave(ctx, []string{images[0].ID, images[1].ID})
+		if err != nil {
+			return err
+		}
+		defer archive.Close()
+		layers, err := readArchiveLayers(archive)
+		if err != nil {
+			return err
+		}
+		for diffID, layer := range layers {
+			sizes[diffID] = layer.size
+		}
+		before, err := imageFiles(layers, images[0].RootFS.Layers)
+		if err != nil {
+			return err
+		}
+		after, err := imageFiles(layers, images[1].RootFS.Layers)
+		if err != nil {
+			return err
+		}
+		changes = diffFiles(before, after)
+	} else {
+		for i, ref := range opts.images {
+			history, err := client.ImageHistory(ctx, ref)
+			if err != nil {
+				return err
+			}
+			for diffID, size := range historyLayerSizes(history, images[i].RootFS.Layers) {
+				sizes[diffID] = size
+			}
+		}
+	}
+
+	out := dockerCli.Out()
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", opts.images[0], opts.images[1])
+
+	fmt.Fprintln(out, "\nConfig:")
+	configChanges := diffConfigs(images[0], images[1])
+	if len(configChanges) == 0 {
+		fmt.Fprintln(out, "  (no changes)")
+	}
+	for _, change := range configChanges {
+		fmt.Fprintln(out, change)
+	}
+
+	fmt.Fprintln(out, "\nLayers:")
+	if err := printLayerDiff(out, diffLayers(images[0].RootFS.Layers, images[1].RootFS.Layers), sizes, opts.noTrunc); err != nil {
+		return err
+	}
+
+	if opts.files {
+		fmt.Fprintln(out, "\nFiles:")
+		if len(changes) == 0 {
+			fmt.Fprintln(out, "  (no changes)")
+		}
+		for _, change := range changes {
+			fmt.Fprintf(out, "%s %s\n", change.kind, change.path)
+		}
+	}
+	return nil
+}
+
+// diffConfigs returns the differences between the configurations of two
+// images, as lines removed ("-") and added ("+").
+func diffConfigs(a, b types.ImageInspect) []string {
+	var lines []string
+	changed := func(field, before, after string) {
+		if before == after {
+			return
+		}
+		if before != "" {
+			lines = append(lines, fmt.Sprintf("- %s: %s", field, before))
+		}
+		if after != "" {
+			lines = append(lines, fmt.Sprintf("+ %s: %s", field, after))
+		}
+	}
+	changedSet := func(field string, before, after map[string]string) {
+		for _, key := range sortedKeys(before, after) {
+			b, inBefore := before[key]
+			c, inAfter := after[key]
+			if inBefore && inAfter && b == c {
+				continue
+			}
+			if inBefore {
+				lines = append(lines, fmt.Sprintf("- %s: %s", field, b))
+			}
+			if inAfter {
+				lines = append(lines, fmt.Sprintf("+ %s: %s", field, c))
+			}
+		}
+	}
+
+	var before, after imageConfigFields
+	if a.Config != nil {
+		before = configFields(a)
+	}
+	if b.Config != nil {
+		after = configFields(b)
+	}
+	changed("User", before.user, after.user)
+	changed("Entrypoint", before.entrypoint, after.entrypoint)
+	changed("Cmd", before.cmd, after.cmd)
+	changedSet("Env", before.env, after.env)
+	changedSet("ExposedPorts", before.ports, after.ports)
+	changedSet("Labels", before.labels, after.labels)
+	return lines
+}
+
+// imageConfigFields are the fields of an image configuration that are
+// compared, as strings. Env, ExposedPorts and Labels are keyed by variable,
+// port and label.
+type imageConfigFields struct {
+	user, entrypoint, cmd string
+	env, ports, labels    map[string]string
+}
+
+func configFields(image types.ImageInspect) imageConfigFields {
+	config := image.Config
+	fields := imageConfigFields{
+		user:   config.User,
+		env:    make(map[string]string),
+		ports:  make(map[string]string),
+		labels: make(map[string]string),
+	}
+	if len(config.Entrypoint) > 0 {
+		entrypoint, _ := json.Marshal(config.Entrypoint)
+		fields.entrypoint = string(entrypoint)
+	}
+	if len(config.Cmd) > 0 {
+		cmd, _ := json.Marshal(config.Cmd)
+		fields.cmd = string(cmd)
+	}
+	for _, env := range config.Env {
+		fields.env[strings.SplitN(env, "=", 2)[0]] = env
+	}
+	for port := range config.ExposedPorts {
+		fields.ports[string(port)] = string(port)
+	}
+	for key, value := range config.Labels {
+		fields.labels[key] = key + "=" + value
+	}
+	return fields
+}
+
+func sortedKeys(maps ...map[string]string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// layerChange is a layer of either or both of two images.
+type layerChange struct {
+	diffID string
+	status string
+}
+
+// diffLayers returns the layers of two images in order, those of the first
+// image not in the second being removed and those of the second image not
+// in the first being added.
+func diffLayers(a, b []string) []layerChange {
+	inA := make(map[string]bool)
+	for _, layer := range a {
+		inA[layer] = true
+	}
+	inB := make(map[string]bool)
+	for _, layer := range b {
+		inB[layer] = true
+	}
+
+	var changes []layerChange
+	for _, layer := range a {
+		status := "shared"
+		if !inB[layer] {
+			status = "removed"
+		}
+		changes = append(changes, layerChange{diffID: layer, status: status})
+	}
+	for _, layer := range b {
+		if !inA[layer] {
+			changes = append(changes, layerChange{diffID: layer, status: "added"})
+		}
+	}
+	return changes
+}
+
+func printLayerDiff(out io.Writer, changes []layerChange, sizes map[string]int64, noTrunc bool) error {
+	totals := make(map[string]int64)
+	counts := make(map[string]int)
+
+	w := tabwriter.NewWriter(out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "LAYER\tSIZE\tSTATUS")
+	for _, change := range changes {
+		layer := change.diffID
+		if !noTrunc {
+			layer = stringid.TruncateID(layer)
+		}
+		size := "-"
+		if s, ok := sizes[change.diffID]; ok {
+			size = units.HumanSizeWithPrecision(float64(s), 3)
+			totals[change.status] += s
+		}
+		counts[change.status]++
+		fmt.Fprintf(w, "%s\t%s\t%s\n", layer, size, change.status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var summary []string
+	for _, status := range []string{"shared", "removed", "added"} {
+		if counts[status] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s (%s)", counts[status], status, units.HumanSizeWithPrecision(float64(totals[status]), 3)))
+		}
+	}
+	fmt.Fprintf(out, "\n%s\n", strings.Join(summary, ", "))
+	return nil
+}
+
+// historyLayerSizes returns the sizes of the layers of an image from its
+// history, when the entries of the history can be matched with the layers.
+func historyLayerSizes(history []image.HistoryResponseItem, layers []string) map[string]int64 {
+	// The history is from the newest to the oldest entry, and also lists the
+	// instructions that did not create a layer, with a size of 0.
+	var all, nonEmpty []int64
+	for i := len(history) - 1; i >= 0; i-- {
+		all = append(all, history[i].Size)
+		if history[i].Size > 0 {
+			nonEmpty = append(nonEmpty, history[i].Size)
+		}
+	}
+
+	var layerSizes []int64
+	switch len(layers) {
+	case len(nonEmpty):
+		layerSizes = nonEmpty
+	case len(all):
+		layerSizes = all
+	default:
+		return nil
+	}
+	sizes := make(map[string]int64)
+	for i, layer := range layers {
+		sizes[layer] = layerSizes[i]
+	}
+	return sizes
+}
+
+// fileInfo describes a file of an image, as compared between two images.
+type fileInfo struct {
+	typeflag byte
+	mode     int64
+	uid, gid int
+	size     int64
+	linkname string
+	digest   digest.Digest
+}
+
+// layerFiles are the files of a layer, in the order of its archive, and its
+// size.
+type layerFiles struct {
+	size  int64
+	names []string
+	files map[string]fileInfo
+}
+
+// readArchiveLayers reads the layers of a `docker save` archive, by the
+// digests of their content.
+func readArchiveLayers(r io.Reader) (map[string]layerFiles, error) {
+	layers := make(map[string]layerFiles)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return layers, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid image archive")
+		}
+		if path.Base(hdr.Name) != "layer.tar" || hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+		h := sha256.New()
+		layer, err := readLayerFiles(io.TeeReader(tr, h))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid layer %s", hdr.Name)
+		}
+		layer.size = hdr.Size
+		layers[digest.NewDigest(digest.SHA256, h).String()] = layer
+	}
+}
+
+func readLayerFiles(r io.Reader) (layerFiles, error) {
+	layer := layerFiles{files: make(map[string]fileInfo)}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return layer, err
+		}
+		name := path.Clean("/" + hdr.Name)
+		if name == "/" {
+			continue
+		}
+		info := fileInfo{
+			typeflag: hdr.Typeflag,
+			mode:     hdr.Mode,
+			uid:      hdr.Uid,
+			gid:      hdr.Gid,
+			size:     hdr.Size,
+			linkname: hdr.Linkname,
+		}
+		if hdr.Typeflag == tar.TypeReg || hdr.Typeflag == tar.TypeRegA {
+			if info.digest, err = digest.FromReader(tr); err != nil {
+				return layer, err
+			}
+		}
+		layer.names = append(layer.names, name)
+		layer.files[name] = info
+	}
+	// Read the padding of the archive, so that all of it is hashed.
+	_, err := io.Copy(ioutil.Discard, r)
+	return layer, err
+}
+
+// imageFiles returns the files of an image, by applying its layers in order.
+func imageFiles(layers map[string]layerFiles, diffIDs []string) (map[string]fileInfo, error) {
+	files := make(map[string]fileInfo)
+	for _, diffID := range diffIDs {
+		layer, ok := layers[diffID]
+		if !ok {
+			return nil, errors.Errorf("layer %s is missing from the image archive", diffID)
+		}
+		// Whiteouts only hide the files of the layers below.
+		for _, name := range layer.names {
+			dir, base := path.Split(name)
+			switch {
+			case base == whiteoutOpaque:
+				removeFiles(files, path.Clean(dir), false)
+			case strings.HasPrefix(base, whiteoutPrefix):
+				removeFiles(files, path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), true)
+			}
+		}
+		for _, name := range layer.names {
+			if strings.HasPrefix(path.Base(name), whiteoutPrefix) {
+				continue
+			}
+			files[name] = layer.files[name]
+		}
+	}
+	return files, nil
+}
+
+// removeFiles removes the files under dir, and dir itself if self is set.
+func removeFiles(files map[string]fileInfo, dir string, self bool) {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	for name := range files {
+		if strings.HasPrefix(name, prefix) || self && name == dir {
+			delete(files, name)
+		}
+	}
+}
+
+// fileChange is a path added ("A"), changed ("C") or deleted ("D").
+type fileChange struct {
+	kind string
+	path string
+}
+
+func diffFiles(before, after map[string]fileInfo) []fileChange {
+	var changes []fileChange
+	for name, info := range after {
+		previous, ok := before[name]
+		switch {
+		case !ok:
+			changes = append(changes, fileChange{kind: "A", path: name})
+		case previous != info:
+			changes = append(changes, fileChange{kind: "C", path: name})
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changes = append(changes, fileChange{kind: "D", path: name})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].path < changes[j].path
+	})
+	return changes
+}
@@ -0,0 +1,125 @@
+package image
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/go-connections/nat"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	a := types.ImageInspect{Config: &container.Config{
+		User:   "root",
+		Cmd:    []string{"sh"},
+		Env:    []string{"PATH=/bin", "VERSION=1.0"},
+		Labels: map[string]string{"maintainer": "me"},
+	}}
+	b := types.ImageInspect{Config: &container.Config{
+		User:         "app",
+		Cmd:          []string{"sh"},
+		Env:          []string{"PATH=/bin", "VERSION=1.1"},
+		ExposedPorts: nat.PortSet{"80/tcp": {}},
+		Labels:       map[string]string{"maintainer": "me"},
+	}}
+	assert.Equal(t, []string{
+		"- User: root",
+		"+ User: app",
+		"- Env: VERSION=1.0",
+		"+ Env: VERSION=1.1",
+		"+ ExposedPorts: 80/tcp",
+	}, diffConfigs(a, b))
+	assert.Empty(t, diffConfigs(a, a))
+}
+
+func TestHistoryLayerSizes(t *testing.T) {
+	history := []image.HistoryResponseItem{
+		{Size: 0, CreatedBy: "CMD"},
+		{Size: 20},
+		{Size: 0, CreatedBy: "ENV"},
+		{Size: 10},
+	}
+	assert.Equal(t, map[string]int64{"a": 10, "b": 20}, historyLayerSizes(history, []string{"a", "b"}))
+	assert.Nil(t, historyLayerSizes(history, []string{"a", "b", "c"}))
+}
+
+func TestImageFilesWhiteouts(t *testing.T) {
+	lower := makeTar(t,
+		tarEntry{name: "etc/passwd", content: []byte("root")},
+		tarEntry{name: "tmp/a", content: []byte("a")},
+		tarEntry{name: "var/cache/b", content: []byte("b")},
+	)
+	upper := makeTar(t,
+		tarEntry{name: "etc/passwd", content: []byte("root\napp")},
+		tarEntry{name: "tmp/.wh.a"},
+		tarEntry{name: "var/cache/.wh..wh..opq"},
+		tarEntry{name: "var/cache/c", content: []byte("c")},
+	)
+	archive := makeTar(t,
+		tarEntry{name: "lower/layer.tar", content: lower},
+		tarEntry{name: "upper/layer.tar", content: upper},
+	)
+	layers, err := readArchiveLayers(bytes.NewReader(archive))
+	require.NoError(t, err)
+	lowerID, upperID := digest.FromBytes(lower).String(), digest.FromBytes(upper).String()
+	require.Contains(t, layers, lowerID)
+	assert.Equal(t, int64(len(upper)), layers[upperID].size)
+
+	before, err := imageFiles(layers, []string{lowerID})
+	require.NoError(t, err)
+	after, err := imageFiles(layers, []string{lowerID, upperID})
+	require.NoError(t, err)
+	assert.Equal(t, []fileChange{
+		{kind: "C", path: "/etc/passwd"},
+		{kind: "D", path: "/tmp/a"},
+		{kind: "D", path: "/var/cache/b"},
+		{kind: "A", path: "/var/cache/c"},
+	}, diffFiles(before, after))
+
+	_, err = imageFiles(layers, []string{"sha256:missing"})
+	assert.Error(t, err)
+}
+
+func TestRunDiffFiles(t *testing.T) {
+	base := makeTar(t, tarEntry{name: "bin/sh", content: []byte("sh")})
+	app := makeTar(t, tarEntry{name: "app", content: []byte("app")})
+	baseID, appID := digest.FromBytes(base).String(), digest.FromBytes(app).String()
+
+	client := &fakeClient{
+		imageInspectFunc: func(ref string) (types.ImageInspect, []byte, error) {
+			inspect := types.ImageInspect{ID: ref, Config: &container.Config{Cmd: []string{"sh"}}}
+			inspect.RootFS.Layers = []string{baseID}
+			if ref == "app" {
+				inspect.RootFS.Layers = append(inspect.RootFS.Layers, appID)
+			}
+			return inspect, nil, nil
+		},
+		imageSaveFunc: func(images []string) (io.ReadCloser, error) {
+			assert.Equal(t, []string{"base", "app"}, images)
+			return ioutil.NopCloser(bytes.NewReader(makeTar(t,
+				tarEntry{name: "1/layer.tar", content: base},
+				tarEntry{name: "2/layer.tar", content: app},
+			))), nil
+		},
+	}
+	buf := new(bytes.Buffer)
+	cmd := NewDiffCommand(test.NewFakeCli(client, buf))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--files", "base", "app"})
+	require.NoError(t, cmd.Execute())
+
+	output := buf.String()
+	assert.Contains(t, output, "Config:\n  (no changes)\n")
+	assert.Contains(t, output, digest.FromBytes(base).Hex()[:12]+"        2.05kB              shared\n")
+	assert.Contains(t, output, digest.FromBytes(app).Hex()[:12]+"        2.05kB              added\n")
+	assert.Contains(t, output, "1 shared (2.05kB), 1 added (2.05kB)\n")
+	assert.Contains(t, output, "Files:\nA /app\n")
+}
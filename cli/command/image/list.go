@@ -8,6 +8,7 @@ import (
 	"github.com/docker/cli/cli/command/formatter"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/opts"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +21,7 @@ type imagesOptions struct {
 	showDigests bool
 	format      string
 	filter      opts.FilterOpt
+	tree        bool
 }
 
 // NewImagesCommand creates a new `docker images` command
@@ -46,6 +48,7 @@ func NewImagesCommand(dockerCli command.Cli) *cobra.Command {
 	flags.BoolVar(&opts.showDigests, "digests", false, "Show digests")
 	flags.StringVar(&opts.format, "format", "", "Pretty-print images using a Go template")
 	flags.VarP(&opts.filter, "filter", "f", "Filter output based on conditions provided")
+	flags.BoolVar(&opts.tree, "tree", false, `Show the images as a tree of their shared layers, or as a graph with --format "dot"`)
 
 	return cmd
 }
@@ -60,6 +63,13 @@ func newListCommand(dockerCli command.Cli) *cobra.Command {
 func runImages(dockerCli command.Cli, opts imagesOptions) error {
 	ctx := context.Background()
 
+	if opts.tree && (opts.quiet || opts.showDigests) {
+		return errors.New("--tree cannot be used with --quiet or --digests")
+	}
+	if opts.tree && opts.format != "" && opts.format != treeFormatDot {
+		return errors.Errorf("--tree only supports the %q format", treeFormatDot)
+	}
+
 	filters := opts.filter.Value()
 	if opts.matchName != "" {
 		filters.Add("reference", opts.matchName)
@@ -75,6 +85,10 @@ func runImages(dockerCli command.Cli, opts imagesOptions) error {
 		return err
 	}
 
+	if opts.tree {
+		return runImageTree(ctx, dockerCli, images, opts.format, opts.noTrunc)
+	}
+
 	format := opts.format
 	if len(format) == 0 {
 		if len(dockerCli.ConfigFile().ImagesFormat) > 0 && !opts.quiet {
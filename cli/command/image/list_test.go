@@ -9,6 +9,7 @@ import (
 	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/cli/cli/internal/test"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/pkg/testutil"
 	"github.com/docker/docker/pkg/testutil/golden"
 	"github.com/pkg/errors"
@@ -100,3 +101,58 @@ func TestNewListCommandAlias(t *testing.T) {
 	assert.True(t, cmd.HasAlias("list"))
 	assert.False(t, cmd.HasAlias("other"))
 }
+
+func TestNewImagesCommandTree(t *testing.T) {
+	layerSizes := map[string]int64{"l1": 1000, "l2": 2000, "l3": 3000, "l4": 4000, "l5": 5000}
+	images := []types.ImageSummary{
+		{ID: "sha256:aaaaaaaaaaaaaaaa", RepoTags: []string{"alpine:3.6"}},
+		{ID: "sha256:eeeeeeeeeeeeeeee", RepoTags: []string{"app:debug"}, ParentID: "sha256:bbbbbbbbbbbbbbbb"},
+		{ID: "sha256:bbbbbbbbbbbbbbbb", RepoTags: []string{"app:1.0"}},
+		{ID: "sha256:cccccccccccccccc", RepoTags: []string{"app:1.1"}},
+		{ID: "sha256:dddddddddddddddd", RepoTags: []string{"busybox:latest"}},
+	}
+	layers := map[string][]string{
+		"sha256:aaaaaaaaaaaaaaaa": {"l1"},
+		"sha256:bbbbbbbbbbbbbbbb": {"l1", "l2", "l3"},
+		"sha256:cccccccccccccccc": {"l1", "l2", "l4"},
+		"sha256:dddddddddddddddd": {"l5"},
+		"sha256:eeeeeeeeeeeeeeee": {"l1", "l2", "l3"},
+	}
+	client := &fakeClient{
+		imageListFunc: func(options types.ImageListOptions) ([]types.ImageSummary, error) {
+			return images, nil
+		},
+		imageInspectFunc: func(ref string) (types.ImageInspect, []byte, error) {
+			inspect := types.ImageInspect{ID: ref}
+			inspect.RootFS.Layers = layers[ref]
+			return inspect, nil, nil
+		},
+		imageHistoryFunc: func(ref string) ([]image.HistoryResponseItem, error) {
+			history := []image.HistoryResponseItem{{CreatedBy: "CMD"}}
+			for i := len(layers[ref]) - 1; i >= 0; i-- {
+				history = append(history, image.HistoryResponseItem{Size: layerSizes[layers[ref][i]]})
+			}
+			return history, nil
+		},
+	}
+
+	for _, format := range []string{"", "dot"} {
+		buf := new(bytes.Buffer)
+		cmd := NewImagesCommand(test.NewFakeCli(client, buf))
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs([]string{"--tree", "--format", format})
+		assert.NoError(t, cmd.Execute())
+		name := "list-command-success.tree.golden"
+		if format != "" {
+			name = "list-command-success.tree-" + format + ".golden"
+		}
+		actual := buf.String()
+		expected := string(golden.Get(t, []byte(actual), name))
+		assert.Equal(t, expected, actual)
+	}
+
+	cmd := NewImagesCommand(test.NewFakeCli(client, new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--tree", "--format", "table"})
+	testutil.ErrorContains(t, cmd.Execute(), `--tree only supports the "dot" format`)
+}
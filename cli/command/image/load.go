@@ -1,7 +1,10 @@
 package image
 
 import (
+	"bufio"
 	"io"
+	"io/ioutil"
+	"os"
 
 	"golang.org/x/net/context"
 
@@ -16,6 +19,7 @@ import (
 type loadOptions struct {
 	input string
 	quiet bool
+	base  string
 }
 
 // NewLoadCommand creates a new `docker load` command
@@ -25,7 +29,13 @@ func NewLoadCommand(dockerCli command.Cli) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "load [OPTIONS]",
 		Short: "Load an image from a tar archive or STDIN",
-		Args:  cli.NoArgs,
+		Long: "Load an image from a tar archive or STDIN.\n\n" +
+			"Both the archives of 'docker save' and OCI image layouts are accepted, possibly\n" +
+			"compressed with gzip or zstd. With --input, they can also be given as a\n" +
+			"directory.\n\n" +
+			"With --base, the layers left out of an archive saved with\n" +
+			"'docker save --exclude-layers-from' are taken from the base archive.",
+		Args: cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runLoad(dockerCli, opts)
 		},
@@ -33,8 +43,9 @@ func NewLoadCommand(dockerCli command.Cli) *cobra.Command {
 
 	flags := cmd.Flags()
 
-	flags.StringVarP(&opts.input, "input", "i", "", "Read from tar archive file, instead of STDIN")
+	flags.StringVarP(&opts.input, "input", "i", "", "Read from tar archive file or directory, instead of STDIN")
 	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Suppress the load output")
+	flags.StringVar(&opts.base, "base", "", "Take the layers missing from the archive from a previously saved archive")
 
 	return cmd
 }
@@ -42,7 +53,17 @@ func NewLoadCommand(dockerCli command.Cli) *cobra.Command {
 func runLoad(dockerCli command.Cli, opts loadOptions) error {
 
 	var input io.Reader = dockerCli.In()
-	if opts.input != "" {
+	if fi, err := os.Stat(opts.input); opts.input != "" && err == nil && fi.IsDir() {
+		if opts.base != "" {
+			return errors.New("--base cannot be used to load a directory")
+		}
+		dirInput, cleanup, err := loadDirectory(opts.input)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		input = dirInput
+	} else if opts.input != "" {
 		// We use system.OpenSequential to use sequential file access on Windows, avoiding
 		// depleting the standby list un-necessarily. On Linux, this equates to a regular os.Open.
 		file, err := system.OpenSequential(opts.input)
@@ -59,6 +80,48 @@ func runLoad(dockerCli command.Cli, opts loadOptions) error {
 		return errors.Errorf("requested load from stdin, but stdin is empty")
 	}
 
+	decompressed, err := decompressReader(bufio.NewReader(input))
+	if err != nil {
+		return err
+	}
+	defer decompressed.Close()
+	buffered := bufio.NewReader(decompressed)
+	input = buffered
+	if isOCILayoutArchive(buffered) {
+		if opts.base != "" {
+			return errors.New("--base cannot be used to load an OCI image layout")
+		}
+		layoutDir, err := ioutil.TempDir("", "docker-oci-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(layoutDir)
+		if err := extractTar(buffered, layoutDir); err != nil {
+			return err
+		}
+		ociInput, cleanup, err := loadOCILayout(layoutDir)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		input = ociInput
+	} else if opts.base != "" {
+		deltaDir, err := ioutil.TempDir("", "docker-load-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(deltaDir)
+		if err := extractTar(buffered, deltaDir); err != nil {
+			return err
+		}
+		if err := restoreBaseLayers(deltaDir, opts.base); err != nil {
+			return err
+		}
+		r := tarDirectoryReader(deltaDir)
+		defer r.Close()
+		input = r
+	}
+
 	if !dockerCli.Out().IsTerminal() {
 		opts.quiet = true
 	}
@@ -75,3 +138,39 @@ func runLoad(dockerCli command.Cli, opts loadOptions) error {
 	_, err = io.Copy(dockerCli.Out(), response.Body)
 	return err
 }
+
+// loadDirectory returns a tar archive of an image layout directory, in the
+// format of `docker save` or OCI, to load, and a function to call once done.
+func loadDirectory(dir string) (io.Reader, func(), error) {
+	if isOCILayoutDir(dir) {
+		return loadOCILayout(dir)
+	}
+	r := tarDirectoryReader(dir)
+	return r, func() { r.Close() }, nil
+}
+
+// loadOCILayout converts an OCI image layout to the format of `docker save`,
+// and returns it as a tar archive to load, and a function to call once done.
+func loadOCILayout(layoutDir string) (io.Reader, func(), error) {
+	legacyDir, err := ioutil.TempDir("", "docker-load-")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ociToLegacy(layoutDir, legacyDir); err != nil {
+		os.RemoveAll(legacyDir)
+		return nil, nil, err
+	}
+	r := tarDirectoryReader(legacyDir)
+	return r, func() {
+		r.Close()
+		os.RemoveAll(legacyDir)
+	}, nil
+}
+
+func tarDirectoryReader(dir string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(tarDirectory(dir, pw))
+	}()
+	return pr
+}
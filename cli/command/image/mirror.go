@@ -0,0 +1,323 @@
+package image
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+// mirrorRetryDelay is the delay before the first retry of an image, which
+// grows with each attempt.
+var mirrorRetryDelay = 2 * time.Second
+
+type mirrorOptions struct {
+	images      []string
+	fromFile    string
+	to          string
+	maxParallel int
+	retries     int
+	insecure    bool
+}
+
+// mirrorResult is the outcome of the mirroring of an image.
+type mirrorResult struct {
+	source   string
+	target   string
+	digest   digest.Digest
+	attempts int
+	err      error
+}
+
+// NewMirrorCommand creates a new `docker image mirror` command
+func NewMirrorCommand(dockerCli command.Cli) *cobra.Command {
+	var opts mirrorOptions
+
+	cmd := &cobra.Command{
+		Use:   "mirror [OPTIONS] --to REGISTRY[/PATH] [IMAGE...]",
+		Short: "Copy images to another registry",
+		Long: `Copy images to another registry.
+
+Each image is pulled, tagged with the name of its repository in the target
+registry, and pushed. The target name replaces the registry of the image with
+REGISTRY[/PATH], keeping the path of its repository: with --to
+registry.internal:5000, "nginx:1.13" is pushed as
+"registry.internal:5000/library/nginx:1.13".
+
+Images are given as arguments, or one per line in the file of --from-file
+("-" to read from stdin), where blank lines and lines starting with "#" are
+ignored. After each push, the manifest in the target registry is checked
+against the digest reported by the push and the local image.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.images = args
+			return runMirror(dockerCli, opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opts.fromFile, "from-file", "f", "", "Read the images from a file, one per line")
+	flags.StringVar(&opts.to, "to", "", "Registry, and optional path, to copy the images to")
+	flags.IntVar(&opts.maxParallel, "max-parallel", 4, "Maximum number of images to copy at once")
+	flags.IntVar(&opts.retries, "retries", 2, "Number of retries of an image that failed to copy")
+	flags.BoolVar(&opts.insecure, "insecure", false, "Allow communication with an insecure target registry to verify the images")
+	return cmd
+}
+
+func runMirror(dockerCli command.Cli, opts mirrorOptions) error {
+	if opts.to == "" {
+		return errors.New("a target registry is required with --to")
+	}
+	if opts.maxParallel < 1 {
+		return errors.Errorf("invalid value %d for --max-parallel: must be at least 1", opts.maxParallel)
+	}
+	if opts.retries < 0 {
+		return errors.Errorf("invalid value %d for --retries: must not be negative", opts.retries)
+	}
+	if err := validateMirrorTarget(opts.to); err != nil {
+		return err
+	}
+
+	images := opts.images
+	if opts.fromFile != "" {
+		fromFile, err := readMirrorFile(dockerCli, opts.fromFile)
+		if err != nil {
+			return err
+		}
+		images = append(images, fromFile...)
+	}
+	if len(images) == 0 {
+		return errors.New("no images to mirror: pass them as arguments or with --from-file")
+	}
+
+	ctx := context.Background()
+	results := make([]chan mirrorResult, len(images))
+	for i := range images {
+		results[i] = make(chan mirrorResult, 1)
+	}
+	go func() {
+		sem := make(chan struct{}, opts.maxParallel)
+		for i, image := range images {
+			sem <- struct{}{}
+			go func(i int, image string) {
+				results[i] <- mirrorWithRetries(ctx, dockerCli, image, opts)
+				<-sem
+			}(i, image)
+		}
+	}()
+
+	var report []mirrorResult
+	for i := range images {
+		result := <-results[i]
+		if result.err != nil {
+			fmt.Fprintf(dockerCli.Err(), "Failed to mirror %s: %s\n", result.source, result.err)
+		} else {
+			fmt.Fprintf(dockerCli.Err(), "Mirrored %s to %s\n", result.source, result.target)
+		}
+		report = append(report, result)
+	}
+
+	failed := printMirrorReport(dockerCli.Out(), report)
+	if failed > 0 {
+		return errors.Errorf("failed to mirror %d of %d images", failed, len(report))
+	}
+	return nil
+}
+
+// validateMirrorTarget checks that the first component of to is the host of
+// a registry, rather than the name of a repository in Docker Hub.
+func validateMirrorTarget(to string) error {
+	host := strings.SplitN(to, "/", 2)[0]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return errors.Errorf("invalid --to %q: must start with a registry host, such as registry.example.com:5000", to)
+	}
+	if _, err := reference.ParseNormalizedNamed(strings.TrimSuffix(to, "/") + "/image"); err != nil {
+		return errors.Wrapf(err, "invalid --to %q", to)
+	}
+	return nil
+}
+
+// readMirrorFile returns the images listed in a file, or in stdin if name is
+// "-".
+func readMirrorFile(dockerCli command.Cli, name string) ([]string, error) {
+	var in io.Reader = dockerCli.In()
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var images []string
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		images = append(images, line)
+	}
+	return images, scanner.Err()
+}
+
+// mirrorTarget returns the name of source in the target registry, which
+// replaces the registry of source, keeping the path of its repository.
+func mirrorTarget(source reference.Named, to string) (reference.NamedTagged, error) {
+	tagged, ok := source.(reference.Tagged)
+	if !ok {
+		return nil, errors.Errorf("a tag is required to push %s to the target registry", reference.FamiliarString(source))
+	}
+	name, err := reference.ParseNormalizedNamed(strings.TrimSuffix(to, "/") + "/" + reference.Path(source))
+	if err != nil {
+		return nil, err
+	}
+	return reference.WithTag(name, tagged.Tag())
+}
+
+func mirrorWithRetries(ctx context.Context, dockerCli command.Cli, image string, opts mirrorOptions) mirrorResult {
+	result := mirrorResult{source: image}
+	source, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	if _, isDigested := source.(reference.Digested); !isDigested {
+		source = reference.TagNameOnly(source)
+	}
+	result.source = reference.FamiliarString(source)
+	target, err := mirrorTarget(source, opts.to)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	result.target = reference.FamiliarString(target)
+
+	for result.attempts = 1; ; result.attempts++ {
+		result.digest, result.err = mirrorImage(ctx, dockerCli, source, target, opts.insecure)
+		if result.err == nil || result.attempts > opts.retries {
+			return result
+		}
+		time.Sleep(time.Duration(result.attempts) * mirrorRetryDelay)
+	}
+}
+
+// mirrorImage pulls source, tags it as target and pushes it, returning the
+// digest of the pushed manifest once it is verified.
+func mirrorImage(ctx context.Context, dockerCli command.Cli, source reference.Named, target reference.NamedTagged, insecure bool) (digest.Digest, error) {
+	// The progress of the images copied at once is not shown.
+	quietCli := &mirrorCli{Cli: dockerCli, out: command.NewOutStream(ioutil.Discard)}
+
+	sourceInfo, err := registry.ParseRepositoryInfo(source)
+	if err != nil {
+		return "", err
+	}
+	sourceAuth := command.ResolveAuthConfig(ctx, dockerCli, sourceInfo.Index)
+	if err := imagePullPrivileged(ctx, quietCli, sourceAuth, reference.FamiliarString(source), nil, false); err != nil {
+		return "", errors.Wrap(err, "pull failed")
+	}
+
+	inspect, _, err := dockerCli.Client().ImageInspectWithRaw(ctx, reference.FamiliarString(source))
+	if err != nil {
+		return "", err
+	}
+	if err := dockerCli.Client().ImageTag(ctx, inspect.ID, reference.FamiliarString(target)); err != nil {
+		return "", errors.Wrap(err, "tag failed")
+	}
+
+	targetInfo, err := registry.ParseRepositoryInfo(target)
+	if err != nil {
+		return "", err
+	}
+	targetAuth := command.ResolveAuthConfig(ctx, dockerCli, targetInfo.Index)
+	responseBody, err := imagePushPrivileged(ctx, dockerCli, targetAuth, target, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "push failed")
+	}
+	defer responseBody.Close()
+
+	var pushed types.PushResult
+	handleAux := func(aux *json.RawMessage) {
+		json.Unmarshal(*aux, &pushed)
+	}
+	if err := jsonmessage.DisplayJSONMessagesToStream(responseBody, quietCli.Out(), handleAux); err != nil {
+		return "", errors.Wrap(err, "push failed")
+	}
+	if pushed.Digest == "" {
+		return "", errors.New("the push did not report the digest of the image")
+	}
+
+	dgst := digest.Digest(pushed.Digest)
+	if err := verifyMirror(ctx, dockerCli, target, dgst, inspect.ID, insecure); err != nil {
+		return "", err
+	}
+	return dgst, nil
+}
+
+// verifyMirror checks that the manifest of target in the registry is the
+// pushed one, and that it is the manifest of the image with the given ID.
+func verifyMirror(ctx context.Context, dockerCli command.Cli, target reference.Named, pushed digest.Digest, imageID string, insecure bool) error {
+	manifest, err := dockerCli.RegistryClient(insecure).GetManifest(ctx, target)
+	if err != nil {
+		return errors.Wrap(err, "verification failed")
+	}
+	if manifest.Digest != pushed {
+		return errors.Errorf("verification failed: the registry has manifest %s, but the push reported %s", manifest.Digest, pushed)
+	}
+	var config struct {
+		Config struct {
+			Digest digest.Digest `json:"digest"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(manifest.Raw, &config); err != nil {
+		return errors.Wrap(err, "verification failed")
+	}
+	if config.Config.Digest.String() != imageID {
+		return errors.Errorf("verification failed: the manifest in the registry is for image %s, not %s", config.Config.Digest, imageID)
+	}
+	return nil
+}
+
+// printMirrorReport prints a summary of the mirrored images, and returns the
+// number of images that failed.
+func printMirrorReport(out io.Writer, report []mirrorResult) int {
+	w := tabwriter.NewWriter(out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tTARGET\tDIGEST\tATTEMPTS\tSTATUS")
+	failed := 0
+	for _, result := range report {
+		status, dgst := "mirrored", result.digest.String()
+		if result.err != nil {
+			status, dgst = "failed", "-"
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", result.source, result.target, dgst, result.attempts, status)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "%d mirrored, %d failed\n", len(report)-failed, failed)
+	return failed
+}
+
+// mirrorCli is a command.Cli with a different output stream.
+type mirrorCli struct {
+	command.Cli
+	out *command.OutStream
+}
+
+func (c *mirrorCli) Out() *command.OutStream {
+	return c.out
+}
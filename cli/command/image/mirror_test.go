@@ -0,0 +1,121 @@
+package image
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/cli/cli/internal/test/registry"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestMirrorTarget(t *testing.T) {
+	testCases := []struct {
+		source   string
+		to       string
+		expected string
+	}{
+		{source: "nginx", to: "registry.internal:5000", expected: "registry.internal:5000/library/nginx:latest"},
+		{source: "quay.io/coreos/etcd:v3.2", to: "registry.internal:5000/mirror/", expected: "registry.internal:5000/mirror/coreos/etcd:v3.2"},
+	}
+	for _, tc := range testCases {
+		source, err := reference.ParseNormalizedNamed(tc.source)
+		require.NoError(t, err)
+		target, err := mirrorTarget(reference.TagNameOnly(source), tc.to)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, target.String())
+	}
+
+	source, err := reference.ParseNormalizedNamed("nginx@sha256:" + strings.Repeat("a", 64))
+	require.NoError(t, err)
+	_, err = mirrorTarget(source, "registry.internal:5000")
+	assert.EqualError(t, err, "a tag is required to push nginx@sha256:"+strings.Repeat("a", 64)+" to the target registry")
+
+	assert.Error(t, validateMirrorTarget("mirror"))
+	assert.NoError(t, validateMirrorTarget("localhost/mirror"))
+}
+
+func TestRunMirror(t *testing.T) {
+	defer func(delay time.Duration) { mirrorRetryDelay = delay }(mirrorRetryDelay)
+	mirrorRetryDelay = 0
+
+	fakeRegistry := registry.NewFakeRegistry()
+	defer fakeRegistry.Close()
+	host := fakeRegistry.Host()
+
+	configs := map[string]digest.Digest{
+		"alpine:3.6":  digest.FromString("alpine config"),
+		"busybox:1.0": digest.FromString("busybox config"),
+	}
+	pushes := make(map[string]int)
+	var tags []string
+	client := &fakeClient{
+		imageInspectFunc: func(ref string) (types.ImageInspect, []byte, error) {
+			return types.ImageInspect{ID: configs[ref].String()}, nil, nil
+		},
+		imageTagFunc: func(image, ref string) error {
+			tags = append(tags, image+" "+ref)
+			return nil
+		},
+		imagePushFunc: func(ref string, options types.ImagePushOptions) (io.ReadCloser, error) {
+			pushes[ref]++
+			// The first push of alpine fails, and busybox is pushed as another image.
+			source := strings.TrimPrefix(strings.Replace(ref, "library/", "", 1), host+"/")
+			if source == "alpine:3.6" && pushes[ref] == 1 {
+				return nil, errors.New("connection reset")
+			}
+			config := configs[source]
+			if source == "busybox:1.0" {
+				config = digest.FromString("other config")
+			}
+			repo, tag := strings.TrimPrefix(ref[:strings.LastIndex(ref, ":")], host+"/"), ref[strings.LastIndex(ref, ":")+1:]
+			dgst := fakeRegistry.AddManifest(repo, tag, "application/vnd.docker.distribution.manifest.v2+json",
+				[]byte(`{"schemaVersion":2,"config":{"digest":"`+config.String()+`"}}`))
+			return ioutil.NopCloser(strings.NewReader(`{"status":"pushed"}` + "\n" +
+				`{"aux":{"Tag":"` + tag + `","Digest":"` + dgst.String() + `","Size":100}}` + "\n")), nil
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	cli := test.NewFakeCli(client, buf)
+	cli.SetErr(ioutil.Discard)
+	resolver := func(ctx context.Context, index *registrytypes.IndexInfo) types.AuthConfig {
+		return types.AuthConfig{}
+	}
+	cli.SetRegistryClient(registryclient.NewRegistryClient(resolver, "test", false))
+	cli.SetIn(command.NewInStream(ioutil.NopCloser(strings.NewReader("# base images\nalpine:3.6\n\nbusybox:1.0\n"))))
+
+	cmd := NewMirrorCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--from-file", "-", "--to", host, "--retries", "1", "--max-parallel", "1"})
+	assert.EqualError(t, cmd.Execute(), "failed to mirror 1 of 2 images")
+
+	assert.Equal(t, []string{
+		configs["alpine:3.6"].String() + " " + host + "/library/alpine:3.6",
+		configs["alpine:3.6"].String() + " " + host + "/library/alpine:3.6",
+		configs["busybox:1.0"].String() + " " + host + "/library/busybox:1.0",
+		configs["busybox:1.0"].String() + " " + host + "/library/busybox:1.0",
+	}, tags)
+
+	_, raw, ok := fakeRegistry.Manifest("library/alpine", "3.6")
+	require.True(t, ok)
+	lines := strings.Split(buf.String(), "\n")
+	require.Len(t, lines, 5)
+	assert.Regexp(t, `^SOURCE\s+TARGET\s+DIGEST\s+ATTEMPTS\s+STATUS$`, lines[0])
+	assert.Regexp(t, `^alpine:3.6\s+`+host+`/library/alpine:3.6\s+`+digest.FromBytes(raw).String()+`\s+2\s+mirrored$`, lines[1])
+	assert.Regexp(t, `^busybox:1.0\s+`+host+`/library/busybox:1.0\s+-\s+2\s+failed$`, lines[2])
+	assert.Equal(t, "1 mirrored, 1 failed", lines[3])
+}
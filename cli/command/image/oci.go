@@ -0,0 +1,540 @@
+package image
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// The files and media types of the OCI image layout, see
+// https://github.com/opencontainers/image-spec/blob/master/image-layout.md
+const (
+	ociLayoutFile    = "oci-layout"
+	ociIndexFile     = "index.json"
+	ociBlobsDir      = "blobs"
+	ociLayoutVersion = "1.0.0"
+
+	mediaTypeOCIManifest = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex    = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIConfig   = "application/vnd.oci.image.config.v1+json"
+	mediaTypeOCILayer    = "application/vnd.oci.image.layer.v1.tar"
+
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// annotationRefName holds the tag of an image in index.json.
+	annotationRefName = "org.opencontainers.image.ref.name"
+	// annotationImageName holds the full reference of an image in the
+	// layouts written by containerd.
+	annotationImageName = "io.containerd.image.name"
+
+	// legacyManifestFile is the manifest of the archives of `docker save`.
+	legacyManifestFile = "manifest.json"
+)
+
+type ociLayout struct {
+	ImageLayoutVersion string `json:"imageLayoutVersion"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      digest.Digest     `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociIndex struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType,omitempty"`
+	Manifests     []ociDescriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType,omitempty"`
+	Config        ociDescriptor   `json:"config"`
+	Layers        []ociDescriptor `json:"layers"`
+}
+
+// legacyManifestItem is an image of the manifest.json of `docker save`.
+type legacyManifestItem struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+// legacyToOCI converts an archive written by `docker save` to an OCI image
+// layout in layoutDir. The tags of the images are kept in the
+// org.opencontainers.image.ref.name annotation of index.json.
+func legacyToOCI(archive io.Reader, layoutDir string) error {
+	tmpDir, err := ioutil.TempDir("", "docker-save-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := extractTar(archive, tmpDir); err != nil {
+		return err
+	}
+	var items []legacyManifestItem
+	if err := readJSONFile(filepath.Join(tmpDir, legacyManifestFile), &items); err != nil {
+		return err
+	}
+
+	index := ociIndex{SchemaVersion: 2, Manifests: []ociDescriptor{}}
+	for _, item := range items {
+		manifest := ociManifest{SchemaVersion: 2, Layers: []ociDescriptor{}}
+		manifest.Config, err = writeBlobFile(layoutDir, mediaTypeOCIConfig, archivePath(tmpDir, item.Config))
+		if err != nil {
+			return err
+		}
+		for _, layer := range item.Layers {
+			desc, err := writeBlobFile(layoutDir, mediaTypeOCILayer, archivePath(tmpDir, layer))
+			if err != nil {
+				return err
+			}
+			manifest.Layers = append(manifest.Layers, desc)
+		}
+		desc, err := writeJSONBlob(layoutDir, mediaTypeOCIManifest, manifest)
+		if err != nil {
+			return err
+		}
+		if len(item.RepoTags) == 0 {
+			index.Manifests = append(index.Manifests, desc)
+		}
+		for _, tag := range item.RepoTags {
+			tagged := desc
+			tagged.Annotations = map[string]string{annotationRefName: tag}
+			index.Manifests = append(index.Manifests, tagged)
+		}
+	}
+
+	if err := writeJSONFile(filepath.Join(layoutDir, ociIndexFile), index); err != nil {
+		return err
+	}
+	return writeJSONFile(filepath.Join(layoutDir, ociLayoutFile), ociLayout{ImageLayoutVersion: ociLayoutVersion})
+}
+
+// ociToLegacy converts the OCI image layout in layoutDir to the format of
+// `docker save` in legacyDir. Layers are decompressed, as `docker load`
+// expects uncompressed layers.
+func ociToLegacy(layoutDir, legacyDir string) error {
+	var layout ociLayout
+	if err := readJSONFile(filepath.Join(layoutDir, ociLayoutFile), &layout); err != nil {
+		return errors.Wrap(err, "invalid OCI image layout")
+	}
+	var index ociIndex
+	if err := readJSONFile(filepath.Join(layoutDir, ociIndexFile), &index); err != nil {
+		return errors.Wrap(err, "invalid OCI image layout")
+	}
+
+	var items []legacyManifestItem
+	byManifest := make(map[digest.Digest]int)
+	for _, desc := range index.Manifests {
+		switch desc.MediaType {
+		case mediaTypeOCIManifest, mediaTypeDockerManifest:
+		case mediaTypeOCIIndex, mediaTypeDockerManifestList:
+			return errors.Errorf("unsupported nested image index %s: only single-platform images can be loaded", desc.Digest)
+		default:
+			return errors.Errorf("unsupported media type %q of %s", desc.MediaType, desc.Digest)
+		}
+
+		i, ok := byManifest[desc.Digest]
+		if !ok {
+			item, err := ociImageToLegacy(layoutDir, legacyDir, desc)
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+			i = len(items) - 1
+			byManifest[desc.Digest] = i
+		}
+		if tag := refNameTag(desc.Annotations); tag != "" {
+			items[i].RepoTags = append(items[i].RepoTags, tag)
+		}
+	}
+	return writeJSONFile(filepath.Join(legacyDir, legacyManifestFile), items)
+}
+
+func ociImageToLegacy(layoutDir, legacyDir string, desc ociDescriptor) (legacyManifestItem, error) {
+	var item legacyManifestItem
+	var manifest ociManifest
+	if err := readJSONBlob(layoutDir, desc.Digest, &manifest); err != nil {
+		return item, err
+	}
+
+	// The digests are part of the paths of the legacy archive, so they are
+	// validated before any of these paths is used.
+	if err := manifest.Config.Digest.Validate(); err != nil {
+		return item, errors.Wrapf(err, "invalid config digest %q", manifest.Config.Digest)
+	}
+	item.Config = manifest.Config.Digest.Hex() + ".json"
+	if err := copyBlob(layoutDir, manifest.Config.Digest, filepath.Join(legacyDir, item.Config), false); err != nil {
+		return item, err
+	}
+	for _, layer := range manifest.Layers {
+		var gzipped bool
+		switch {
+		case strings.HasSuffix(layer.MediaType, "+gzip"), strings.HasSuffix(layer.MediaType, ".gzip"):
+			gzipped = true
+		case strings.HasSuffix(layer.MediaType, ".tar"):
+		default:
+			return item, errors.Errorf("unsupported layer media type %q of %s", layer.MediaType, layer.Digest)
+		}
+		if err := layer.Digest.Validate(); err != nil {
+			return item, errors.Wrapf(err, "invalid layer digest %q", layer.Digest)
+		}
+		layerPath := path.Join(layer.Digest.Hex(), "layer.tar")
+		target := filepath.Join(legacyDir, filepath.FromSlash(layerPath))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return item, err
+		}
+		if err := copyBlob(layoutDir, layer.Digest, target, gzipped); err != nil {
+			return item, err
+		}
+		item.Layers = append(item.Layers, layerPath)
+	}
+	return item, nil
+}
+
+// refNameTag returns the tag of an image of index.json, in the format of
+// the RepoTags of `docker save`, or an empty string if it has no tag.
+func refNameTag(annotations map[string]string) string {
+	name := annotations[annotationImageName]
+	if name == "" {
+		name = annotations[annotationRefName]
+		if !strings.ContainsAny(name, ":/") {
+			// A bare tag, such as "latest", does not say which repository
+			// it belongs to.
+			return ""
+		}
+	}
+	named, err := reference.ParseNormalizedNamed(name)
+	if err != nil {
+		return ""
+	}
+	if _, ok := named.(reference.NamedTagged); !ok {
+		if _, ok := named.(reference.Canonical); ok {
+			return ""
+		}
+		named = reference.TagNameOnly(named)
+	}
+	return reference.FamiliarString(named)
+}
+
+func blobPath(layoutDir string, dgst digest.Digest) (string, error) {
+	if err := dgst.Validate(); err != nil {
+		return "", err
+	}
+	return filepath.Join(layoutDir, ociBlobsDir, dgst.Algorithm().String(), dgst.Hex()), nil
+}
+
+// writeBlobFile copies a file to the blobs of the layout.
+func writeBlobFile(layoutDir, mediaType, filename string) (ociDescriptor, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	defer f.Close()
+	return writeBlob(layoutDir, mediaType, f)
+}
+
+func writeJSONBlob(layoutDir, mediaType string, v interface{}) (ociDescriptor, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	return writeBlob(layoutDir, mediaType, bytes.NewReader(content))
+}
+
+// writeBlob writes the content of r to the blobs of the layout, named after
+// its digest, and returns its descriptor.
+func writeBlob(layoutDir, mediaType string, r io.Reader) (ociDescriptor, error) {
+	dir := filepath.Join(layoutDir, ociBlobsDir, digest.Canonical.String())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ociDescriptor{}, err
+	}
+	tmpFile, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	digester := digest.Canonical.Digester()
+	size, err := io.Copy(io.MultiWriter(tmpFile, digester.Hash()), r)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	dgst := digester.Digest()
+	if err := os.Rename(tmpFile.Name(), filepath.Join(dir, dgst.Hex())); err != nil {
+		return ociDescriptor{}, err
+	}
+	return ociDescriptor{MediaType: mediaType, Digest: dgst, Size: size}, nil
+}
+
+// copyBlob copies a blob of the layout to target, after checking its
+// digest, decompressing it if gzipped is set.
+func copyBlob(layoutDir string, dgst digest.Digest, target string, gzipped bool) error {
+	blob, err := blobPath(layoutDir, dgst)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(target); err == nil {
+		// Layers shared by several images are only copied once.
+		return nil
+	}
+	f, err := os.Open(blob)
+	if err != nil {
+		return errors.Wrapf(err, "missing blob %s", dgst)
+	}
+	defer f.Close()
+
+	verifier := dgst.Verifier()
+	blobReader := io.TeeReader(f, verifier)
+	r := blobReader
+	if gzipped {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return errors.Wrapf(err, "invalid blob %s", dgst)
+		}
+		r = gz
+	}
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	if err == nil {
+		// Read what remains after the end of the gzip stream.
+		_, err = io.Copy(ioutil.Discard, blobReader)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && !verifier.Verified() {
+		err = errors.Errorf("blob %s does not match its digest", dgst)
+	}
+	if err != nil {
+		os.Remove(target)
+	}
+	return err
+}
+
+func readJSONBlob(layoutDir string, dgst digest.Digest, v interface{}) error {
+	blob, err := blobPath(layoutDir, dgst)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(blob)
+	if err != nil {
+		return errors.Wrapf(err, "missing blob %s", dgst)
+	}
+	if digest.FromBytes(content) != dgst {
+		return errors.Errorf("blob %s does not match its digest", dgst)
+	}
+	return json.Unmarshal(content, v)
+}
+
+func readJSONFile(filename string, v interface{}) error {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(json.Unmarshal(content, v), "invalid %s", filepath.Base(filename))
+}
+
+func writeJSONFile(filename string, v interface{}) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, content, 0644)
+}
+
+// isOCILayoutArchive returns whether the tar archive read by r holds an OCI
+// image layout, from the name of its first entry. The archives of
+// `docker save` start with an image or a layer instead.
+func isOCILayoutArchive(r *bufio.Reader) bool {
+	header, err := r.Peek(512)
+	if err != nil {
+		return false
+	}
+	hdr, err := tar.NewReader(bytes.NewReader(header)).Next()
+	if err != nil {
+		return false
+	}
+	name := strings.TrimPrefix(path.Clean(hdr.Name), "./")
+	return name == ociLayoutFile || name == ociIndexFile || name == ociBlobsDir || strings.HasPrefix(name, ociBlobsDir+"/")
+}
+
+// isOCILayoutDir returns whether dir holds an OCI image layout.
+func isOCILayoutDir(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ociLayoutFile))
+	return err == nil
+}
+
+// extractTar extracts the regular files, directories and symbolic links of
+// a tar archive to dir. Entries are rooted at dir, and are never written
+// through a symbolic link. Links must be relative and clean, and must not
+// point out of dir: as they can only go up through real directories, they
+// cannot point out of dir through another link either.
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean("/" + hdr.Name)
+		if name == "/" || hdr.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		target := archivePath(dir, name)
+		if err := checkArchivePath(dir, target); err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg, tar.TypeRegA:
+			err = extractFile(tr, target)
+		case tar.TypeSymlink:
+			linked := path.Join(path.Dir(name[1:]), hdr.Linkname)
+			if path.IsAbs(hdr.Linkname) || path.Clean(hdr.Linkname) != hdr.Linkname || linked == ".." || strings.HasPrefix(linked, "../") {
+				return errors.Errorf("invalid link %s -> %s in archive", hdr.Name, hdr.Linkname)
+			}
+			if err = os.MkdirAll(filepath.Dir(target), 0755); err == nil {
+				err = os.Symlink(hdr.Linkname, target)
+			}
+		case tar.TypeLink:
+			return errors.Errorf("invalid hard link %s -> %s in archive: hard links are not supported", hdr.Name, hdr.Linkname)
+		default:
+			return errors.Errorf("invalid entry %s in archive: unsupported type %q", hdr.Name, hdr.Typeflag)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// archivePath returns the path in dir of the entry name of an archive, or of
+// a path listed in the archive. Names are rooted at dir, so that they cannot
+// point out of it.
+func archivePath(dir, name string) string {
+	return filepath.Join(dir, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+// checkArchivePath returns an error if target, in dir, or one of its parent
+// directories up to dir is a symbolic link, which writing target would
+// follow.
+func checkArchivePath(dir, target string) error {
+	rel, err := filepath.Rel(dir, target)
+	if err != nil {
+		return err
+	}
+	p := dir
+	for _, elem := range strings.Split(rel, string(filepath.Separator)) {
+		p = filepath.Join(p, elem)
+		info, err := os.Lstat(p)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return errors.Errorf("invalid path %s in archive: %s is a symbolic link", filepath.ToSlash(rel), filepath.ToSlash(p[len(dir)+1:]))
+		}
+	}
+	return nil
+}
+
+func extractFile(r io.Reader, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// tarDirectory writes the content of dir to a tar archive, starting with
+// the oci-layout file of an OCI image layout.
+func tarDirectory(dir string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	add := func(name string, info os.FileInfo) error {
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, ociLayoutFile)); err == nil {
+		if err := add(ociLayoutFile, info); err != nil {
+			return err
+		}
+	}
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, p)
+		if err != nil || name == "." || name == ociLayoutFile {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			// Only regular files and directories are written.
+			if info, err = os.Stat(p); err != nil {
+				return err
+			}
+		}
+		return add(name, info)
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -0,0 +1,362 @@
+package image
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tarEntry struct {
+	name     string
+	content  []byte
+	typeflag byte
+	linkname string
+}
+
+func makeTar(t *testing.T, entries ...tarEntry) []byte {
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, e := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: e.typeflag, Linkname: e.linkname}))
+		_, err := tw.Write(e.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func readTar(t *testing.T, r io.Reader) map[string][]byte {
+	files := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		content, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = content
+	}
+}
+
+// legacyArchive returns an archive in the format of `docker save`, and the
+// content of its layer.
+func legacyArchive(t *testing.T) ([]byte, []byte) {
+	layer := makeTar(t, tarEntry{name: "hello.txt", content: []byte("hello")})
+	config := []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":["` + digest.FromBytes(layer).String() + `"]}}`)
+	configName := digest.FromBytes(config).Hex() + ".json"
+	manifest := `[{"Config":"` + configName + `","RepoTags":["busybox:latest","example.com/app:1.0"],"Layers":["0123abcd/layer.tar"]}]`
+
+	return makeTar(t,
+		tarEntry{name: "0123abcd/layer.tar", content: layer},
+		tarEntry{name: configName, content: config},
+		tarEntry{name: "manifest.json", content: []byte(manifest)},
+	), layer
+}
+
+func TestSaveAndLoadOCILayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oci-layout")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive, layer := legacyArchive(t)
+	client := &fakeClient{
+		imageSaveFunc: func(images []string) (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(archive)), nil
+		},
+	}
+
+	// Save to a directory.
+	layoutDir := filepath.Join(dir, "layout") + string(filepath.Separator)
+	cmd := NewSaveCommand(test.NewFakeCli(client, new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--format", "oci", "-o", layoutDir, "busybox"})
+	require.NoError(t, cmd.Execute())
+
+	var index ociIndex
+	require.NoError(t, readJSONFile(filepath.Join(layoutDir, ociIndexFile), &index))
+	require.Len(t, index.Manifests, 2)
+	assert.Equal(t, "busybox:latest", index.Manifests[0].Annotations[annotationRefName])
+	assert.Equal(t, "example.com/app:1.0", index.Manifests[1].Annotations[annotationRefName])
+	assert.Equal(t, index.Manifests[0].Digest, index.Manifests[1].Digest)
+
+	var manifest ociManifest
+	require.NoError(t, readJSONBlob(layoutDir, index.Manifests[0].Digest, &manifest))
+	require.Len(t, manifest.Layers, 1)
+	assert.Equal(t, ociDescriptor{MediaType: mediaTypeOCILayer, Digest: digest.FromBytes(layer), Size: int64(len(layer))}, manifest.Layers[0])
+	assert.True(t, isOCILayoutDir(layoutDir))
+
+	// Save to a tar archive.
+	archiveFile := filepath.Join(dir, "image.tar")
+	cmd = NewSaveCommand(test.NewFakeCli(client, new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--format", "oci", "-o", archiveFile, "busybox"})
+	require.NoError(t, cmd.Execute())
+
+	// Load both, which are converted back to the format of `docker save`.
+	for _, input := range []string{layoutDir, archiveFile} {
+		var loaded map[string][]byte
+		client := &fakeClient{
+			imageLoadFunc: func(input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+				loaded = readTar(t, input)
+				return types.ImageLoadResponse{Body: ioutil.NopCloser(new(bytes.Buffer))}, nil
+			},
+		}
+		cmd := NewLoadCommand(test.NewFakeCli(client, new(bytes.Buffer)))
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs([]string{"-i", input})
+		require.NoError(t, cmd.Execute())
+
+		var items []legacyManifestItem
+		require.NoError(t, json.Unmarshal(loaded[legacyManifestFile], &items))
+		require.Len(t, items, 1)
+		assert.Equal(t, []string{"busybox:latest", "example.com/app:1.0"}, items[0].RepoTags)
+		require.Len(t, items[0].Layers, 1)
+		assert.Equal(t, layer, loaded[items[0].Layers[0]])
+		assert.Contains(t, loaded, items[0].Config)
+	}
+}
+
+func TestOCIToLegacyGzipLayers(t *testing.T) {
+	layoutDir, err := ioutil.TempDir("", "oci-layout")
+	require.NoError(t, err)
+	defer os.RemoveAll(layoutDir)
+	legacyDir, err := ioutil.TempDir("", "legacy")
+	require.NoError(t, err)
+	defer os.RemoveAll(legacyDir)
+
+	layer := makeTar(t, tarEntry{name: "hello.txt", content: []byte("hello")})
+	gzipped := new(bytes.Buffer)
+	gz := gzip.NewWriter(gzipped)
+	gz.Write(layer)
+	require.NoError(t, gz.Close())
+
+	layerDesc, err := writeBlob(layoutDir, "application/vnd.oci.image.layer.v1.tar+gzip", gzipped)
+	require.NoError(t, err)
+	configDesc, err := writeJSONBlob(layoutDir, mediaTypeOCIConfig, map[string]string{"os": "linux"})
+	require.NoError(t, err)
+	manifestDesc, err := writeJSONBlob(layoutDir, mediaTypeOCIManifest, ociManifest{SchemaVersion: 2, Config: configDesc, Layers: []ociDescriptor{layerDesc}})
+	require.NoError(t, err)
+	manifestDesc.Annotations = map[string]string{annotationRefName: "latest", annotationImageName: "docker.io/library/alpine:3.6"}
+	require.NoError(t, writeJSONFile(filepath.Join(layoutDir, ociIndexFile), ociIndex{SchemaVersion: 2, Manifests: []ociDescriptor{manifestDesc}}))
+	require.NoError(t, writeJSONFile(filepath.Join(layoutDir, ociLayoutFile), ociLayout{ImageLayoutVersion: ociLayoutVersion}))
+
+	require.NoError(t, ociToLegacy(layoutDir, legacyDir))
+	var items []legacyManifestItem
+	require.NoError(t, readJSONFile(filepath.Join(legacyDir, legacyManifestFile), &items))
+	require.Len(t, items, 1)
+	assert.Equal(t, []string{"alpine:3.6"}, items[0].RepoTags)
+	content, err := ioutil.ReadFile(filepath.Join(legacyDir, items[0].Layers[0]))
+	require.NoError(t, err)
+	assert.Equal(t, layer, content)
+
+	// A corrupted blob is rejected.
+	blob, err := blobPath(layoutDir, layerDesc.Digest)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(blob, []byte("corrupted"), 0644))
+	os.RemoveAll(legacyDir)
+	require.NoError(t, os.MkdirAll(legacyDir, 0755))
+	err = ociToLegacy(layoutDir, legacyDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid blob")
+}
+
+func TestOCIToLegacyHostileDigests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hostile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	layoutDir := filepath.Join(dir, "layout")
+	configDesc, err := writeJSONBlob(layoutDir, mediaTypeOCIConfig, map[string]string{"os": "linux"})
+	require.NoError(t, err)
+	layerDesc, err := writeBlob(layoutDir, mediaTypeOCILayer, bytes.NewReader(makeTar(t)))
+	require.NoError(t, err)
+	hostile := ociDescriptor{MediaType: mediaTypeOCILayer, Digest: digest.Digest("sha256:../outside/x")}
+
+	testCases := []struct {
+		doc      string
+		manifest ociManifest
+	}{
+		{
+			doc:      "config",
+			manifest: ociManifest{SchemaVersion: 2, Config: ociDescriptor{MediaType: mediaTypeOCIConfig, Digest: hostile.Digest}, Layers: []ociDescriptor{layerDesc}},
+		},
+		{
+			doc:      "layer",
+			manifest: ociManifest{SchemaVersion: 2, Config: configDesc, Layers: []ociDescriptor{layerDesc, hostile}},
+		},
+	}
+	for _, tc := range testCases {
+		legacyDir := filepath.Join(dir, "legacy")
+		require.NoError(t, os.MkdirAll(legacyDir, 0755))
+		manifestDesc, err := writeJSONBlob(layoutDir, mediaTypeOCIManifest, tc.manifest)
+		require.NoError(t, err)
+		require.NoError(t, writeJSONFile(filepath.Join(layoutDir, ociIndexFile), ociIndex{SchemaVersion: 2, Manifests: []ociDescriptor{manifestDesc}}))
+		require.NoError(t, writeJSONFile(filepath.Join(layoutDir, ociLayoutFile), ociLayout{ImageLayoutVersion: ociLayoutVersion}))
+
+		err = ociToLegacy(layoutDir, legacyDir)
+		require.Error(t, err, tc.doc)
+		assert.Contains(t, err.Error(), "invalid "+tc.doc+" digest", tc.doc)
+		_, err = os.Stat(filepath.Join(dir, "outside"))
+		assert.True(t, os.IsNotExist(err), tc.doc)
+		require.NoError(t, os.RemoveAll(legacyDir))
+	}
+}
+
+func TestRefNameTag(t *testing.T) {
+	testCases := []struct {
+		annotations map[string]string
+		expected    string
+	}{
+		{map[string]string{annotationRefName: "busybox:latest"}, "busybox:latest"},
+		{map[string]string{annotationRefName: "docker.io/library/busybox"}, "busybox:latest"},
+		{map[string]string{annotationRefName: "example.com/app:1.0"}, "example.com/app:1.0"},
+		{map[string]string{annotationRefName: "latest"}, ""},
+		{map[string]string{annotationRefName: "latest", annotationImageName: "docker.io/library/busybox:1.26"}, "busybox:1.26"},
+		{map[string]string{}, ""},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, refNameTag(tc.annotations))
+	}
+}
+
+func TestIsOCILayoutArchive(t *testing.T) {
+	archive, _ := legacyArchive(t)
+	assert.False(t, isOCILayoutArchive(bufioReader(archive)))
+	assert.True(t, isOCILayoutArchive(bufioReader(makeTar(t, tarEntry{name: "oci-layout", content: []byte("{}")}))))
+	assert.True(t, isOCILayoutArchive(bufioReader(makeTar(t, tarEntry{name: "./blobs/sha256/abcd"}))))
+}
+
+func TestExtractTar(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extract")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	target := filepath.Join(dir, "target")
+	archive := makeTar(t,
+		tarEntry{name: "0123abcd/layer.tar", content: []byte("layer")},
+		tarEntry{name: "4567cdef/layer.tar", typeflag: tar.TypeSymlink, linkname: "../0123abcd/layer.tar"},
+		tarEntry{name: "../../manifest.json", content: []byte("[]")},
+	)
+	require.NoError(t, extractTar(bytes.NewReader(archive), target))
+	content, err := ioutil.ReadFile(filepath.Join(target, "4567cdef", "layer.tar"))
+	require.NoError(t, err)
+	assert.Equal(t, "layer", string(content))
+	_, err = os.Stat(filepath.Join(target, "manifest.json"))
+	assert.NoError(t, err)
+}
+
+func TestExtractTarHostileArchive(t *testing.T) {
+	testCases := []struct {
+		doc           string
+		entries       []tarEntry
+		expectedError string
+	}{
+		{
+			doc: "link out of the directory",
+			entries: []tarEntry{
+				{name: "blobs", typeflag: tar.TypeSymlink, linkname: "../../../outside"},
+				{name: "blobs/sha256/abcd", content: []byte("hostile")},
+			},
+			expectedError: "invalid link blobs -> ../../../outside in archive",
+		},
+		{
+			doc: "absolute link",
+			entries: []tarEntry{
+				{name: "blobs", typeflag: tar.TypeSymlink, linkname: "/tmp"},
+			},
+			expectedError: "invalid link blobs -> /tmp in archive",
+		},
+		{
+			doc: "link through another link",
+			entries: []tarEntry{
+				{name: "a/self", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "a/blobs", typeflag: tar.TypeSymlink, linkname: "self/../../.."},
+			},
+			expectedError: "invalid link a/blobs -> self/../../.. in archive",
+		},
+		{
+			doc: "file written through a link",
+			entries: []tarEntry{
+				{name: "blobs", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "blobs/sha256/abcd", content: []byte("hostile")},
+			},
+			expectedError: "invalid path blobs/sha256/abcd in archive: blobs is a symbolic link",
+		},
+		{
+			doc: "file replacing a link",
+			entries: []tarEntry{
+				{name: "index.json", typeflag: tar.TypeSymlink, linkname: "oci-layout"},
+				{name: "index.json", content: []byte("hostile")},
+			},
+			expectedError: "invalid path index.json in archive: index.json is a symbolic link",
+		},
+		{
+			doc: "hard link",
+			entries: []tarEntry{
+				{name: "blobs", typeflag: tar.TypeLink, linkname: "/etc/passwd"},
+			},
+			expectedError: "hard links are not supported",
+		},
+	}
+	for _, tc := range testCases {
+		dir, err := ioutil.TempDir("", "extract")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		target := filepath.Join(dir, "a", "b", "target")
+		err = extractTar(bytes.NewReader(makeTar(t, tc.entries...)), target)
+		if assert.Error(t, err, tc.doc) {
+			assert.Contains(t, err.Error(), tc.expectedError, tc.doc)
+		}
+		_, err = os.Stat(filepath.Join(dir, "outside"))
+		assert.True(t, os.IsNotExist(err), tc.doc)
+	}
+}
+
+func TestLoadHostileOCILayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hostile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	outside := filepath.Join(dir, "outside")
+	archive := makeTar(t,
+		tarEntry{name: "blobs", typeflag: tar.TypeSymlink, linkname: relativeToRoot(outside)},
+		tarEntry{name: "blobs/sha256/abcd", content: []byte("hostile")},
+	)
+	cli := test.NewFakeCli(&fakeClient{}, new(bytes.Buffer))
+	cli.SetIn(command.NewInStream(ioutil.NopCloser(bytes.NewReader(archive))))
+	cmd := NewLoadCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	err = cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid link blobs")
+	_, err = os.Stat(outside)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// relativeToRoot returns a relative link to the absolute path p, from any
+// directory.
+func relativeToRoot(p string) string {
+	return strings.Repeat("../", 16) + filepath.ToSlash(strings.TrimPrefix(p, "/"))
+}
+
+func bufioReader(content []byte) *bufio.Reader {
+	return bufio.NewReader(bytes.NewReader(content))
+}
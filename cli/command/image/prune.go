@@ -7,15 +7,17 @@ import (
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/opts"
 	units "github.com/docker/go-units"
 	"github.com/spf13/cobra"
 )
 
 type pruneOptions struct {
-	force  bool
-	all    bool
-	filter opts.FilterOpt
+	force     bool
+	all       bool
+	filter    opts.FilterOpt
+	retention retentionOptions
 }
 
 // NewPruneCommand returns a new cobra prune command for images
@@ -25,8 +27,19 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "prune [OPTIONS]",
 		Short: "Remove unused images",
-		Args:  cli.NoArgs,
+		Long: `Remove unused images.
+
+With --keep-last, --keep-tags or --keep-used-by-containers, the images to
+remove are selected by these retention policies instead: all the images which
+are not kept by a policy, tagged or not, are removed. --keep-last keeps the
+most recently created images, in each repository with --per-repository. The
+images used by running containers are always kept, and the images used by
+stopped containers too with --keep-used-by-containers.`,
+		Args: cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.retention.set() {
+				return runRetentionPrune(dockerCli, opts)
+			}
 			spaceReclaimed, output, err := runPrune(dockerCli, opts)
 			if err != nil {
 				return err
@@ -44,6 +57,7 @@ func NewPruneCommand(dockerCli command.Cli) *cobra.Command {
 	flags.BoolVarP(&opts.force, "force", "f", false, "Do not prompt for confirmation")
 	flags.BoolVarP(&opts.all, "all", "a", false, "Remove all unused images, not just dangling ones")
 	flags.Var(&opts.filter, "filter", "Provide filter values (e.g. 'until=<timestamp>')")
+	addRetentionFlags(flags, &opts.retention)
 
 	return cmd
 }
@@ -74,20 +88,28 @@ func runPrune(dockerCli command.Cli, opts pruneOptions) (spaceReclaimed uint64,
 	}
 
 	if len(report.ImagesDeleted) > 0 {
-		output = "Deleted Images:\n"
-		for _, st := range report.ImagesDeleted {
-			if st.Untagged != "" {
-				output += fmt.Sprintln("untagged:", st.Untagged)
-			} else {
-				output += fmt.Sprintln("deleted:", st.Deleted)
-			}
-		}
+		output = deletedImagesOutput(report.ImagesDeleted)
 		spaceReclaimed = report.SpaceReclaimed
 	}
 
 	return
 }
 
+func deletedImagesOutput(items []types.ImageDeleteResponseItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	output := "Deleted Images:\n"
+	for _, st := range items {
+		if st.Untagged != "" {
+			output += fmt.Sprintln("untagged:", st.Untagged)
+		} else {
+			output += fmt.Sprintln("deleted:", st.Deleted)
+		}
+	}
+	return output
+}
+
 // RunPrune calls the Image Prune API
 // This returns the amount of space reclaimed and a detailed output string
 func RunPrune(dockerCli command.Cli, all bool, filter opts.FilterOpt) (uint64, string, error) {
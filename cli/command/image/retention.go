@@ -0,0 +1,281 @@
+package image
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	timetypes "github.com/docker/docker/api/types/time"
+	"github.com/docker/docker/pkg/stringid"
+	units "github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"golang.org/x/net/context"
+)
+
+// retentionOptions are the policies selecting the images kept by prune,
+// which removes all the other images.
+type retentionOptions struct {
+	keepLast      int
+	perRepository bool
+	keepTags      []string
+	keepUsed      bool
+	dryRun        bool
+}
+
+func addRetentionFlags(flags *pflag.FlagSet, opts *retentionOptions) {
+	flags.IntVar(&opts.keepLast, "keep-last", 0, "Keep the most recently created images")
+	flags.BoolVar(&opts.perRepository, "per-repository", false, "Apply --keep-last to each repository")
+	flags.StringSliceVar(&opts.keepTags, "keep-tags", nil, "Keep the images with a tag matching a pattern (e.g. 'v*')")
+	flags.BoolVar(&opts.keepUsed, "keep-used-by-containers", false, "Keep the images used by stopped containers too")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "Show the images that would be removed, without removing them")
+}
+
+// set returns whether a retention flag is set.
+func (o retentionOptions) set() bool {
+	return o.keepLast != 0 || o.perRepository || len(o.keepTags) > 0 || o.keepUsed || o.dryRun
+}
+
+// enabled returns whether a retention policy is set.
+func (o retentionOptions) enabled() bool {
+	return o.keepLast > 0 || len(o.keepTags) > 0 || o.keepUsed
+}
+
+func (o retentionOptions) validate() error {
+	if o.keepLast < 0 {
+		return errors.Errorf("invalid value %d for --keep-last: must not be negative", o.keepLast)
+	}
+	if o.perRepository && o.keepLast == 0 {
+		return errors.New("--per-repository requires --keep-last")
+	}
+	for _, pattern := range o.keepTags {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Errorf("invalid pattern %q for --keep-tags: %s", pattern, err)
+		}
+	}
+	if o.dryRun && !o.enabled() {
+		return errors.New("--dry-run requires --keep-last, --keep-tags or --keep-used-by-containers")
+	}
+	return nil
+}
+
+// keepsTag returns whether the image tagged as repoTag matches one of the
+// --keep-tags patterns. Patterns with a ":" match the whole reference, and
+// the others the tag only.
+func (o retentionOptions) keepsTag(repoTag string) bool {
+	named, err := reference.ParseNormalizedNamed(repoTag)
+	if err != nil {
+		return false
+	}
+	tagged, ok := named.(reference.Tagged)
+	if !ok {
+		return false
+	}
+	for _, pattern := range o.keepTags {
+		name := tagged.Tag()
+		if strings.Contains(pattern, ":") {
+			name = reference.FamiliarString(named)
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// imageTags returns the tags of an image, which are none for a dangling
+// image.
+func imageTags(image types.ImageSummary) []string {
+	var tags []string
+	for _, repoTag := range image.RepoTags {
+		if repoTag != "<none>:<none>" {
+			tags = append(tags, repoTag)
+		}
+	}
+	return tags
+}
+
+// retentionCandidates returns the images which are not kept by a policy,
+// from the most recently created. Images in used, or created after until if
+// it is set, are kept.
+func retentionCandidates(images []types.ImageSummary, used map[string]bool, until time.Time, opts retentionOptions) []types.ImageSummary {
+	sorted := append([]types.ImageSummary{}, images...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Created > sorted[j].Created
+	})
+
+	keep := make(map[string]bool)
+	groups := make(map[string]int)
+	for _, image := range sorted {
+		if used[image.ID] {
+			keep[image.ID] = true
+		}
+		if !until.IsZero() && time.Unix(image.Created, 0).After(until) {
+			keep[image.ID] = true
+		}
+
+		// Dangling images are never kept by --keep-last.
+		repos := make(map[string]bool)
+		for _, repoTag := range imageTags(image) {
+			if opts.keepsTag(repoTag) {
+				keep[image.ID] = true
+			}
+			repo := ""
+			if opts.perRepository {
+				repo = repoTag[:strings.LastIndex(repoTag, ":")]
+			}
+			repos[repo] = true
+		}
+		for repo := range repos {
+			if groups[repo] < opts.keepLast {
+				keep[image.ID] = true
+			}
+			groups[repo]++
+		}
+	}
+
+	var candidates []types.ImageSummary
+	for _, image := range sorted {
+		if !keep[image.ID] {
+			candidates = append(candidates, image)
+		}
+	}
+	return candidates
+}
+
+// runRetentionPrune removes the images which are not kept by the retention
+// policies, or only shows them with --dry-run. The sizes of the images
+// include the layers they share with other images, so the space reclaimed
+// may be less than shown.
+func runRetentionPrune(dockerCli command.Cli, opts pruneOptions) error {
+	if err := opts.retention.validate(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	pruneFilters := command.PruneFilters(dockerCli, opts.filter.Value())
+	until, err := retentionUntil(pruneFilters)
+	if err != nil {
+		return err
+	}
+	images, err := dockerCli.Client().ImageList(ctx, types.ImageListOptions{Filters: pruneFilters})
+	if err != nil {
+		return err
+	}
+	// The images of running containers are always kept, and the images of
+	// stopped containers only with --keep-used-by-containers.
+	containers, err := dockerCli.Client().ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return err
+	}
+	used := make(map[string]bool)
+	for _, c := range containers {
+		if c.State == "running" || opts.retention.keepUsed {
+			used[c.ImageID] = true
+		}
+	}
+
+	candidates := retentionCandidates(images, used, until, opts.retention)
+	if len(candidates) == 0 {
+		fmt.Fprintln(dockerCli.Out(), "No images to remove")
+		return nil
+	}
+	reclaimable := writeRetentionCandidates(dockerCli.Out(), candidates)
+	if opts.retention.dryRun {
+		fmt.Fprintln(dockerCli.Out(), "Total reclaimable space:", units.HumanSize(float64(reclaimable)))
+		fmt.Fprintln(dockerCli.Out(), "The layers shared by several images are counted once per image, so less space may be reclaimed.")
+		return nil
+	}
+	if !opts.force && !command.PromptForConfirmation(dockerCli.In(), dockerCli.Out(), "WARNING! This will remove the images above.\nAre you sure you want to continue?") {
+		return nil
+	}
+
+	var (
+		deleted        []types.ImageDeleteResponseItem
+		spaceReclaimed uint64
+		errs           []string
+	)
+	for _, image := range candidates {
+		refs := imageTags(image)
+		if len(refs) == 0 {
+			refs = []string{image.ID}
+		}
+		for _, ref := range refs {
+			items, err := dockerCli.Client().ImageRemove(ctx, ref, types.ImageRemoveOptions{PruneChildren: true})
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			for _, item := range items {
+				if item.Deleted == image.ID {
+					spaceReclaimed += uint64(image.Size)
+				}
+			}
+			deleted = append(deleted, items...)
+		}
+	}
+
+	fmt.Fprintln(dockerCli.Out())
+	if output := deletedImagesOutput(deleted); output != "" {
+		fmt.Fprintln(dockerCli.Out(), output)
+	}
+	fmt.Fprintln(dockerCli.Out(), "Total reclaimed space:", units.HumanSize(float64(spaceReclaimed)))
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// retentionUntil removes the until filter, which is not supported when
+// listing images, and returns its time, or the zero time if it is not set.
+func retentionUntil(pruneFilters filters.Args) (time.Time, error) {
+	values := pruneFilters.Get("until")
+	if len(values) == 0 {
+		return time.Time{}, nil
+	}
+	if len(values) > 1 {
+		return time.Time{}, errors.New("more than one until filter specified")
+	}
+	pruneFilters.Del("until", values[0])
+	ts, err := timetypes.GetTimestamp(values[0], time.Now())
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, nanoseconds, err := timetypes.ParseTimestamps(ts, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, nanoseconds), nil
+}
+
+// writeRetentionCandidates writes the images to remove, with one line per
+// tag, and returns their total size.
+func writeRetentionCandidates(out io.Writer, candidates []types.ImageSummary) int64 {
+	w := tabwriter.NewWriter(out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tTAG\tIMAGE ID\tCREATED\tSIZE")
+	var total int64
+	for _, image := range candidates {
+		created := units.HumanDuration(time.Now().UTC().Sub(time.Unix(image.Created, 0))) + " ago"
+		size := units.HumanSizeWithPrecision(float64(image.Size), 3)
+		id := stringid.TruncateID(image.ID)
+		tags := imageTags(image)
+		if len(tags) == 0 {
+			fmt.Fprintf(w, "<none>\t<none>\t%s\t%s\t%s\n", id, created, size)
+		}
+		for _, repoTag := range tags {
+			i := strings.LastIndex(repoTag, ":")
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", repoTag[:i], repoTag[i+1:], id, created, size)
+		}
+		total += image.Size
+	}
+	w.Flush()
+	return total
+}
@@ -0,0 +1,173 @@
+package image
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/testutil"
+	"github.com/docker/docker/pkg/testutil/golden"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func retentionImages() []types.ImageSummary {
+	hours := func(n int) int64 {
+		return time.Now().Add(-time.Duration(n) * time.Hour).Unix()
+	}
+	return []types.ImageSummary{
+		{ID: "sha256:app1", RepoTags: []string{"app:v1"}, Created: hours(6), Size: 1000},
+		{ID: "sha256:app2", RepoTags: []string{"app:2"}, Created: hours(5), Size: 2000},
+		{ID: "sha256:app3", RepoTags: []string{"app:3", "registry.example.com:5000/app:3"}, Created: hours(4), Size: 3000},
+		{ID: "sha256:app4", RepoTags: []string{"app:latest"}, Created: hours(3), Size: 4000},
+		{ID: "sha256:db1", RepoTags: []string{"db:1"}, Created: hours(2), Size: 500},
+		{ID: "sha256:dangling", RepoTags: []string{"<none>:<none>"}, Created: hours(1), Size: 100},
+	}
+}
+
+func candidateIDs(candidates []types.ImageSummary) []string {
+	var ids []string
+	for _, image := range candidates {
+		ids = append(ids, image.ID)
+	}
+	return ids
+}
+
+func TestRetentionCandidates(t *testing.T) {
+	images := retentionImages()
+	testCases := []struct {
+		name     string
+		opts     retentionOptions
+		used     map[string]bool
+		until    time.Time
+		expected []string
+	}{
+		{
+			name:     "keep-last",
+			opts:     retentionOptions{keepLast: 2},
+			expected: []string{"sha256:dangling", "sha256:app3", "sha256:app2", "sha256:app1"},
+		},
+		{
+			name:     "keep-last-per-repository",
+			opts:     retentionOptions{keepLast: 1, perRepository: true},
+			expected: []string{"sha256:dangling", "sha256:app2", "sha256:app1"},
+		},
+		{
+			name:     "keep-tags",
+			opts:     retentionOptions{keepLast: 2, keepTags: []string{"v*", "registry.example.com:5000/app:*"}},
+			expected: []string{"sha256:dangling", "sha256:app2"},
+		},
+		{
+			name:     "keep-used-by-containers",
+			opts:     retentionOptions{keepUsed: true},
+			used:     map[string]bool{"sha256:dangling": true, "sha256:app1": true},
+			expected: []string{"sha256:db1", "sha256:app4", "sha256:app3", "sha256:app2"},
+		},
+		{
+			name:     "until",
+			opts:     retentionOptions{keepUsed: true},
+			until:    time.Now().Add(-150 * time.Minute),
+			expected: []string{"sha256:app4", "sha256:app3", "sha256:app2", "sha256:app1"},
+		},
+	}
+	for _, tc := range testCases {
+		candidates := retentionCandidates(images, tc.used, tc.until, tc.opts)
+		assert.Equal(t, tc.expected, candidateIDs(candidates), tc.name)
+	}
+}
+
+func TestPruneRetentionErrors(t *testing.T) {
+	testCases := []struct {
+		args          []string
+		expectedError string
+	}{
+		{args: []string{"--per-repository"}, expectedError: "--per-repository requires --keep-last"},
+		{args: []string{"--dry-run"}, expectedError: "--dry-run requires --keep-last, --keep-tags or --keep-used-by-containers"},
+		{args: []string{"--keep-last", "-1"}, expectedError: "invalid value -1 for --keep-last"},
+		{args: []string{"--keep-tags", "v["}, expectedError: "invalid pattern \"v[\" for --keep-tags"},
+		{args: []string{"--keep-last", "1", "--filter", "until=1h", "--filter", "until=2h"}, expectedError: "more than one until filter specified"},
+	}
+	for _, tc := range testCases {
+		cmd := NewPruneCommand(test.NewFakeCli(&fakeClient{}, new(bytes.Buffer)))
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs(tc.args)
+		testutil.ErrorContains(t, cmd.Execute(), tc.expectedError)
+	}
+}
+
+func TestPruneRetentionDryRun(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cmd := NewPruneCommand(test.NewFakeCli(&fakeClient{
+		imageListFunc: func(options types.ImageListOptions) ([]types.ImageSummary, error) {
+			assert.Equal(t, []string{"com.example.team=web"}, options.Filters.Get("label"))
+			return retentionImages(), nil
+		},
+		containerListFunc: func(options types.ContainerListOptions) ([]types.Container, error) {
+			assert.True(t, options.All)
+			return []types.Container{{ImageID: "sha256:app1"}}, nil
+		},
+	}, buf))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--dry-run", "--keep-last", "1", "--per-repository", "--keep-used-by-containers", "--filter", "label=com.example.team=web"})
+	require.NoError(t, cmd.Execute())
+	actual := buf.String()
+	expected := golden.Get(t, []byte(actual), "prune-command-retention.dry-run.golden")
+	testutil.EqualNormalizedString(t, testutil.RemoveSpace, actual, string(expected))
+}
+
+func TestPruneRetentionKeepsImagesOfRunningContainers(t *testing.T) {
+	var removed []string
+	cmd := NewPruneCommand(test.NewFakeCli(&fakeClient{
+		imageListFunc: func(options types.ImageListOptions) ([]types.ImageSummary, error) {
+			return retentionImages(), nil
+		},
+		containerListFunc: func(options types.ContainerListOptions) ([]types.Container, error) {
+			return []types.Container{
+				{ImageID: "sha256:app3", State: "running"},
+				{ImageID: "sha256:app2", State: "exited"},
+			}, nil
+		},
+		imageRemoveFunc: func(image string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+			removed = append(removed, image)
+			return []types.ImageDeleteResponseItem{{Deleted: image}}, nil
+		},
+	}, new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--force", "--keep-last", "2", "--keep-tags", "v*"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, []string{"sha256:dangling", "app:2"}, removed)
+
+	removed = nil
+	cmd.SetArgs([]string{"--force", "--keep-last", "2", "--keep-tags", "v*", "--keep-used-by-containers"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, []string{"sha256:dangling"}, removed)
+}
+
+func TestPruneRetentionRemove(t *testing.T) {
+	var removed []string
+	buf := new(bytes.Buffer)
+	cmd := NewPruneCommand(test.NewFakeCli(&fakeClient{
+		imageListFunc: func(options types.ImageListOptions) ([]types.ImageSummary, error) {
+			return retentionImages(), nil
+		},
+		imageRemoveFunc: func(image string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+			removed = append(removed, image)
+			switch image {
+			case "app:3":
+				return []types.ImageDeleteResponseItem{{Untagged: image}}, nil
+			case "registry.example.com:5000/app:3":
+				return []types.ImageDeleteResponseItem{{Untagged: image}, {Deleted: "sha256:app3"}}, nil
+			}
+			return []types.ImageDeleteResponseItem{{Deleted: image}}, nil
+		},
+	}, buf))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--force", "--keep-last", "2", "--keep-tags", "v*"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, []string{"sha256:dangling", "app:3", "registry.example.com:5000/app:3", "app:2"}, removed)
+	assert.Contains(t, buf.String(), "Deleted Images:\ndeleted: sha256:dangling\nuntagged: app:3\n")
+	assert.Contains(t, buf.String(), "Total reclaimed space: 3.1kB\n")
+}
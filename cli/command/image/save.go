@@ -2,6 +2,10 @@ package image
 
 import (
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
@@ -10,9 +14,18 @@ import (
 	"golang.org/x/net/context"
 )
 
+const (
+	saveFormatDocker = "docker"
+	saveFormatOCI    = "oci"
+)
+
 type saveOptions struct {
-	images []string
-	output string
+	images            []string
+	output            string
+	format            string
+	compress          string
+	excludeLayersFrom string
+	since             string
 }
 
 // NewSaveCommand creates a new `docker save` command
@@ -22,7 +35,12 @@ func NewSaveCommand(dockerCli command.Cli) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "save [OPTIONS] IMAGE [IMAGE...]",
 		Short: "Save one or more images to a tar archive (streamed to STDOUT by default)",
-		Args:  cli.RequiresMinArgs(1),
+		Long: "Save one or more images to a tar archive (streamed to STDOUT by default).\n\n" +
+			"With --exclude-layers-from or --since, the layers already in a previous\n" +
+			"archive or image are left out of the archive. Such a delta archive loads on a\n" +
+			"daemon that has those layers, or with 'docker load --base' and the previous\n" +
+			"archive.",
+		Args: cli.RequiresMinArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.images = args
 			return runSave(dockerCli, opts)
@@ -31,26 +49,111 @@ func NewSaveCommand(dockerCli command.Cli) *cobra.Command {
 
 	flags := cmd.Flags()
 
-	flags.StringVarP(&opts.output, "output", "o", "", "Write to a file, instead of STDOUT")
+	flags.StringVarP(&opts.output, "output", "o", "", "Write to a file, or to a directory with --format oci, instead of STDOUT")
+	flags.StringVar(&opts.format, "format", saveFormatDocker, `Format of the archive ("`+saveFormatDocker+`"|"`+saveFormatOCI+`")`)
+	flags.StringVar(&opts.compress, "compress", "", `Compress the archive ("`+compressionGzip+`"|"`+compressionZstd+`")`)
+	flags.StringVar(&opts.excludeLayersFrom, "exclude-layers-from", "", "Leave out the layers of a previously saved archive")
+	flags.StringVar(&opts.since, "since", "", "Leave out the layers of an image")
 
 	return cmd
 }
 
 func runSave(dockerCli command.Cli, opts saveOptions) error {
+	if opts.format != saveFormatDocker && opts.format != saveFormatOCI {
+		return errors.Errorf("invalid format %q: must be %q or %q", opts.format, saveFormatDocker, saveFormatOCI)
+	}
+	if err := validateCompression(opts.compress); err != nil {
+		return err
+	}
+	if opts.format == saveFormatOCI && (opts.excludeLayersFrom != "" || opts.since != "") {
+		return errors.New("--exclude-layers-from and --since cannot be used with --format oci")
+	}
 	if opts.output == "" && dockerCli.Out().IsTerminal() {
 		return errors.New("cowardly refusing to save to a terminal. Use the -o flag or redirect")
 	}
 
-	responseBody, err := dockerCli.Client().ImageSave(context.Background(), opts.images)
+	ctx := context.Background()
+	excluded, err := excludedLayers(ctx, dockerCli, opts)
+	if err != nil {
+		return err
+	}
+
+	responseBody, err := dockerCli.Client().ImageSave(ctx, opts.images)
 	if err != nil {
 		return err
 	}
 	defer responseBody.Close()
 
-	if opts.output == "" {
-		_, err := io.Copy(dockerCli.Out(), responseBody)
+	if opts.format == saveFormatOCI {
+		return saveOCILayout(dockerCli, opts.output, opts.compress, responseBody)
+	}
+
+	var archive io.Reader = responseBody
+	if len(excluded) > 0 {
+		delta, cleanup, err := deltaArchive(responseBody, excluded)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		archive = delta
+	}
+	return writeArchive(dockerCli, opts.output, opts.compress, archive)
+}
+
+// writeArchive writes archive, compressed with compression if set, to
+// output, or to STDOUT if output is empty.
+func writeArchive(dockerCli command.Cli, output string, compression string, archive io.Reader) error {
+	if compression != "" {
+		compressed, err := compressReader(archive, compression)
+		if err != nil {
+			return err
+		}
+		defer compressed.Close()
+		archive = compressed
+	}
+
+	if output == "" {
+		_, err := io.Copy(dockerCli.Out(), archive)
 		return err
 	}
+	return command.CopyToFile(output, archive)
+}
+
+// saveOCILayout converts the archive of the daemon to an OCI image layout,
+// written to output as a directory if it is one or ends with a separator,
+// or else as a tar archive.
+func saveOCILayout(dockerCli command.Cli, output string, compression string, archive io.Reader) error {
+	if isDirectoryTarget(output) {
+		if compression != "" {
+			return errors.New("--compress cannot be used to save to a directory")
+		}
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return err
+		}
+		return legacyToOCI(archive, output)
+	}
 
-	return command.CopyToFile(opts.output, responseBody)
+	layoutDir, err := ioutil.TempDir("", "docker-oci-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(layoutDir)
+	if err := legacyToOCI(archive, layoutDir); err != nil {
+		return err
+	}
+
+	r := tarDirectoryReader(layoutDir)
+	defer r.Close()
+	return writeArchive(dockerCli, output, compression, r)
+}
+
+func isDirectoryTarget(output string) bool {
+	if output == "" {
+		return false
+	}
+	if strings.HasSuffix(output, string(filepath.Separator)) || strings.HasSuffix(output, "/") {
+		return true
+	}
+	fi, err := os.Stat(output)
+	return err == nil && fi.IsDir()
 }
@@ -0,0 +1,236 @@
+package image
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/go-units"
+	"golang.org/x/net/context"
+)
+
+const treeFormatDot = "dot"
+
+// layerNode is a layer in the tree of the layers of the images, with the
+// images whose last layer it is.
+type layerNode struct {
+	layers    int
+	size      int64
+	sizeKnown bool
+	images    []types.ImageSummary
+	children  []*layerNode
+	byLayer   map[string]*layerNode
+}
+
+func newLayerNode() *layerNode {
+	return &layerNode{sizeKnown: true, byLayer: make(map[string]*layerNode)}
+}
+
+// buildLayerTree returns the tree of the layers of images, in which images
+// sharing base layers have a common ancestor. The layers of each image are
+// given by layers, and the sizes of the layers, when known, by sizes.
+func buildLayerTree(images []types.ImageSummary, layers map[string][]string, sizes map[string]int64) *layerNode {
+	root := newLayerNode()
+	for _, image := range images {
+		node := root
+		for _, layer := range layers[image.ID] {
+			child, ok := node.byLayer[layer]
+			if !ok {
+				child = newLayerNode()
+				child.layers = 1
+				child.size, child.sizeKnown = sizes[layer]
+				node.byLayer[layer] = child
+				node.children = append(node.children, child)
+			}
+			node = child
+		}
+		node.images = append(node.images, image)
+	}
+	root.compact()
+	root.sort()
+	return root
+}
+
+// compact merges the chains of layers without images into a single node.
+func (n *layerNode) compact() {
+	for i, child := range n.children {
+		for len(child.images) == 0 && len(child.children) == 1 {
+			next := child.children[0]
+			next.layers += child.layers
+			next.size += child.size
+			next.sizeKnown = next.sizeKnown && child.sizeKnown
+			child = next
+		}
+		n.children[i] = child
+		child.compact()
+	}
+	n.byLayer = nil
+}
+
+func (n *layerNode) sort() {
+	// Images sharing all their layers, when built from one another, are
+	// sorted along their parent chain.
+	parents := make(map[string]string)
+	for _, image := range n.images {
+		parents[image.ID] = image.ParentID
+	}
+	depth := func(image types.ImageSummary) int {
+		d := 0
+		for parent, ok := parents[image.ParentID]; ok && d < len(n.images); parent, ok = parents[parent] {
+			d++
+		}
+		return d
+	}
+	sort.SliceStable(n.images, func(i, j int) bool {
+		return depth(n.images[i]) < depth(n.images[j])
+	})
+	for _, child := range n.children {
+		child.sort()
+	}
+	sort.SliceStable(n.children, func(i, j int) bool {
+		return n.children[i].label() < n.children[j].label()
+	})
+}
+
+// label returns the names of the images of the node, or the number of its
+// layers if it has no image.
+func (n *layerNode) label() string {
+	if len(n.images) == 0 {
+		if n.layers == 1 {
+			return "<1 layer>"
+		}
+		return fmt.Sprintf("<%d layers>", n.layers)
+	}
+	var names []string
+	for _, image := range n.images {
+		names = append(names, imageNames(image)...)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (n *layerNode) ids(noTrunc bool) string {
+	var ids []string
+	for _, image := range n.images {
+		id := image.ID
+		if !noTrunc {
+			id = stringid.TruncateID(id)
+		}
+		ids = append(ids, id)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// topLevel returns the nodes of the images sharing no layer, including
+// those of the images without layers.
+func (n *layerNode) topLevel() []*layerNode {
+	nodes := n.children
+	if len(n.images) > 0 {
+		nodes = append(nodes, &layerNode{images: n.images, sizeKnown: true})
+	}
+	return nodes
+}
+
+// addedSize returns the size of the layers of the node, which it adds to
+// its parent.
+func (n *layerNode) addedSize() string {
+	if !n.sizeKnown {
+		return "-"
+	}
+	return units.HumanSizeWithPrecision(float64(n.size), 3)
+}
+
+func imageNames(image types.ImageSummary) []string {
+	if len(image.RepoTags) > 0 && image.RepoTags[0] != "<none>:<none>" {
+		return image.RepoTags
+	}
+	if len(image.RepoDigests) > 0 && image.RepoDigests[0] != "<none>@<none>" {
+		name := strings.SplitN(image.RepoDigests[0], "@", 2)[0]
+		return []string{name + ":<none>"}
+	}
+	return []string{"<none>:<none>"}
+}
+
+// runImageTree shows images as a tree of their shared layers.
+func runImageTree(ctx context.Context, dockerCli command.Cli, images []types.ImageSummary, format string, noTrunc bool) error {
+	layers := make(map[string][]string)
+	sizes := make(map[string]int64)
+	for _, image := range images {
+		inspect, _, err := dockerCli.Client().ImageInspectWithRaw(ctx, image.ID)
+		if err != nil {
+			return err
+		}
+		layers[image.ID] = inspect.RootFS.Layers
+		history, err := dockerCli.Client().ImageHistory(ctx, image.ID)
+		if err != nil {
+			return err
+		}
+		for layer, size := range historyLayerSizes(history, inspect.RootFS.Layers) {
+			sizes[layer] = size
+		}
+	}
+
+	tree := buildLayerTree(images, layers, sizes)
+	if format == treeFormatDot {
+		return writeLayerTreeDot(dockerCli.Out(), tree)
+	}
+	return writeLayerTree(dockerCli.Out(), tree, noTrunc)
+}
+
+func writeLayerTree(out io.Writer, root *layerNode, noTrunc bool) error {
+	w := tabwriter.NewWriter(out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "IMAGE\tIMAGE ID\tADDED SIZE\tLAYERS")
+	var walk func(n *layerNode, prefix, childPrefix string)
+	walk = func(n *layerNode, prefix, childPrefix string) {
+		fmt.Fprintf(w, "%s%s\t%s\t%s\t%d\n", prefix, n.label(), n.ids(noTrunc), n.addedSize(), n.layers)
+		for i, child := range n.children {
+			if i == len(n.children)-1 {
+				walk(child, childPrefix+"└─ ", childPrefix+"   ")
+			} else {
+				walk(child, childPrefix+"├─ ", childPrefix+"│  ")
+			}
+		}
+	}
+	for _, n := range root.topLevel() {
+		walk(n, "", "")
+	}
+	return w.Flush()
+}
+
+func dotEscape(s string) string {
+	return strings.Replace(strings.Replace(s, `\`, `\\`, -1), `"`, `\"`, -1)
+}
+
+func writeLayerTreeDot(out io.Writer, root *layerNode) error {
+	fmt.Fprintln(out, "digraph images {")
+	fmt.Fprintln(out, "\trankdir=LR;")
+	fmt.Fprintln(out, "\tnode [shape=box];")
+	id := 0
+	var walk func(n *layerNode, parent string)
+	walk = func(n *layerNode, parent string) {
+		id++
+		name := "n" + strconv.Itoa(id)
+		label := dotEscape(n.label()) + `\n` + dotEscape(n.addedSize())
+		if len(n.images) > 0 {
+			fmt.Fprintf(out, "\t%s [label=\"%s\"];\n", name, label)
+		} else {
+			fmt.Fprintf(out, "\t%s [label=\"%s\", style=dashed];\n", name, label)
+		}
+		if parent != "" {
+			fmt.Fprintf(out, "\t%s -> %s;\n", parent, name)
+		}
+		for _, child := range n.children {
+			walk(child, name)
+		}
+	}
+	for _, n := range root.topLevel() {
+		walk(n, "")
+	}
+	fmt.Fprintln(out, "}")
+	return nil
+}
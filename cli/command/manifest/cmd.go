@@ -0,0 +1,30 @@
+package manifest
+
+import (
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+)
+
+// NewManifestCommand returns a cobra command for `manifest` subcommands
+func NewManifestCommand(dockerCli command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest COMMAND",
+		Short: "Manage Docker image manifests and manifest lists",
+		Long: "Manage Docker image manifests and manifest lists.\n\n" +
+			"A manifest list references the images of several platforms under a single\n" +
+			"name. Manifest lists are created locally with 'docker manifest create' and\n" +
+			"'docker manifest annotate', then pushed with 'docker manifest push'. The\n" +
+			"registry is accessed directly, using the credentials of 'docker login'.",
+		Args: cli.NoArgs,
+		RunE: command.ShowHelp(dockerCli.Err()),
+	}
+	cmd.AddCommand(
+		newInspectCommand(dockerCli),
+		newCreateListCommand(dockerCli),
+		newAnnotateCommand(dockerCli),
+		newPushListCommand(dockerCli),
+		newRemoveCommand(dockerCli),
+	)
+	return cmd
+}
@@ -0,0 +1,156 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/manifest/store"
+	"github.com/docker/cli/cli/manifest/types"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+type inspectOptions struct {
+	ref      string
+	list     string
+	verbose  bool
+	insecure bool
+}
+
+func newInspectCommand(dockerCli command.Cli) *cobra.Command {
+	var opts inspectOptions
+
+	cmd := &cobra.Command{
+		Use:   "inspect [OPTIONS] [MANIFEST_LIST] MANIFEST",
+		Short: "Display an image manifest, or manifest list",
+		Long: "Display an image manifest, or manifest list.\n\n" +
+			"A manifest list created locally is shown as it would be pushed. Otherwise, the\n" +
+			"manifest is fetched from the registry. With a MANIFEST_LIST, the manifest of\n" +
+			"MANIFEST in the local manifest list is shown.",
+		Args: cli.RequiresRangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch len(args) {
+			case 1:
+				opts.ref = args[0]
+			case 2:
+				opts.list = args[0]
+				opts.ref = args[1]
+			}
+			return runInspect(dockerCli, opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.insecure, "insecure", false, "Allow communication with an insecure registry")
+	flags.BoolVarP(&opts.verbose, "verbose", "v", false, "Output additional info including layers and platform")
+	return cmd
+}
+
+func runInspect(dockerCli command.Cli, opts inspectOptions) error {
+	namedRef, err := normalizeReference(opts.ref)
+	if err != nil {
+		return err
+	}
+	manifestStore := dockerCli.ManifestStore()
+
+	// If list reference is provided, display the local manifest in a list
+	if opts.list != "" {
+		listRef, err := normalizeReference(opts.list)
+		if err != nil {
+			return err
+		}
+		imageManifest, err := manifestStore.Get(listRef, namedRef)
+		if err != nil {
+			return err
+		}
+		return printJSON(dockerCli, imageManifest)
+	}
+
+	// Try a local manifest list first
+	images, err := manifestStore.GetList(namedRef)
+	switch {
+	case store.IsNotFound(err):
+	case err != nil:
+		return err
+	case opts.verbose:
+		return printJSON(dockerCli, images)
+	default:
+		list, err := buildManifestList(images)
+		if err != nil {
+			return err
+		}
+		return printRawJSON(dockerCli, list.Raw)
+	}
+
+	// Next try a remote manifest
+	ctx := context.Background()
+	client := dockerCli.RegistryClient(opts.insecure)
+	manifest, err := client.GetManifest(ctx, namedRef)
+	if err != nil {
+		return err
+	}
+	if !opts.verbose {
+		return printRawJSON(dockerCli, manifest.Raw)
+	}
+	if !isManifestList(manifest.MediaType) {
+		imageManifest, err := imageManifestFromRegistry(ctx, client, namedRef, manifest)
+		if err != nil {
+			return err
+		}
+		return printJSON(dockerCli, imageManifest)
+	}
+
+	var list manifestlist.ManifestList
+	if err := json.Unmarshal(manifest.Raw, &list); err != nil {
+		return errors.Wrapf(err, "invalid manifest list %s", reference.FamiliarString(namedRef))
+	}
+	images = nil
+	for _, descriptor := range list.Manifests {
+		imageManifest, err := listedImageManifest(ctx, client, namedRef, descriptor)
+		if err != nil {
+			return err
+		}
+		images = append(images, imageManifest)
+	}
+	return printJSON(dockerCli, images)
+}
+
+// listedImageManifest returns the manifest of an image of the manifest list
+// listRef, with the platform given by the list.
+func listedImageManifest(ctx context.Context, client registryclient.RegistryClient, listRef reference.Named, descriptor manifestlist.ManifestDescriptor) (types.ImageManifest, error) {
+	imageRef, err := reference.WithDigest(reference.TrimNamed(listRef), descriptor.Digest)
+	if err != nil {
+		return types.ImageManifest{}, err
+	}
+	imageManifest, err := getImageManifest(ctx, client, imageRef)
+	if err != nil {
+		return types.ImageManifest{}, err
+	}
+	imageManifest.Descriptor.Platform = descriptor.Platform
+	return imageManifest, nil
+}
+
+func printJSON(dockerCli command.Cli, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(dockerCli.Out(), string(content))
+	return nil
+}
+
+func printRawJSON(dockerCli command.Cli, raw []byte) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "\t"); err != nil {
+		return err
+	}
+	fmt.Fprintln(dockerCli.Out(), buf.String())
+	return nil
+}
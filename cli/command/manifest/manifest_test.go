@@ -0,0 +1,142 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/cli/cli/internal/test/registry"
+	"github.com/docker/cli/cli/manifest/store"
+	"github.com/docker/cli/cli/manifest/types"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution/manifest/manifestlist"
+	apitypes "github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func newTestCli(root string) (*test.FakeCli, *bytes.Buffer) {
+	buf := new(bytes.Buffer)
+	cli := test.NewFakeCli(nil, buf)
+	cli.SetManifestStore(store.NewStore(root))
+	resolver := func(ctx context.Context, index *registrytypes.IndexInfo) apitypes.AuthConfig {
+		return apitypes.AuthConfig{}
+	}
+	cli.SetRegistryClient(registryclient.NewRegistryClient(resolver, "test", false))
+	return cli, buf
+}
+
+// addImage adds an image with a layer to a repository of the registry.
+func addImage(fakeRegistry *registry.FakeRegistry, repo, os, arch string) {
+	config := fakeRegistry.AddBlob(repo, []byte(`{"os":"`+os+`","architecture":"`+arch+`"}`))
+	layer := fakeRegistry.AddBlob(repo, []byte(repo+" layer"))
+	manifest := `{"schemaVersion":2,"mediaType":"` + types.MediaTypeManifest + `",` +
+		`"config":{"mediaType":"application/vnd.docker.container.image.v1+json","size":10,"digest":"` + config.String() + `"},` +
+		`"layers":[{"mediaType":"application/vnd.docker.image.rootfs.diff.tar.gzip","size":10,"digest":"` + layer.String() + `"}]}`
+	fakeRegistry.AddManifest(repo, "latest", types.MediaTypeManifest, []byte(manifest))
+}
+
+func runCommand(cli *test.FakeCli, args ...string) error {
+	cmd := NewManifestCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs(args)
+	return cmd.Execute()
+}
+
+func TestCreateAnnotatePushManifestList(t *testing.T) {
+	root, err := ioutil.TempDir("", "manifests")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	fakeRegistry := registry.NewFakeRegistry()
+	defer fakeRegistry.Close()
+	addImage(fakeRegistry, "app-amd64", "linux", "amd64")
+	addImage(fakeRegistry, "app-arm", "linux", "arm")
+	host := fakeRegistry.Host()
+
+	cli, out := newTestCli(root)
+	require.NoError(t, runCommand(cli, "create", host+"/app:1.0", host+"/app-amd64", host+"/app-arm"))
+	assert.Equal(t, "Created manifest list "+host+"/app:1.0\n", out.String())
+
+	err = runCommand(cli, "create", host+"/app:1.0", host+"/app-amd64")
+	assert.EqualError(t, err, "refusing to amend an existing manifest list with no --amend flag")
+
+	require.NoError(t, runCommand(cli, "annotate", "--variant", "v7", host+"/app:1.0", host+"/app-arm"))
+	err = runCommand(cli, "annotate", "--arch", "sparc", host+"/app:1.0", host+"/app-arm")
+	assert.EqualError(t, err, "manifest entry for image has unsupported os/arch combination: linux/sparc")
+
+	out.Reset()
+	require.NoError(t, runCommand(cli, "push", "--purge", host+"/app:1.0"))
+	mediaType, raw, ok := fakeRegistry.Manifest("app", "1.0")
+	require.True(t, ok)
+	assert.Equal(t, manifestlist.MediaTypeManifestList, mediaType)
+	assert.Equal(t, digest.FromBytes(raw).String()+"\n", out.String())
+
+	var list manifestlist.ManifestList
+	require.NoError(t, json.Unmarshal(raw, &list))
+	require.Len(t, list.Manifests, 2)
+	assert.Equal(t, manifestlist.PlatformSpec{OS: "linux", Architecture: "amd64"}, list.Manifests[0].Platform)
+	assert.Equal(t, manifestlist.PlatformSpec{OS: "linux", Architecture: "arm", Variant: "v7"}, list.Manifests[1].Platform)
+
+	// The images are available in the repository of the list.
+	for _, descriptor := range list.Manifests {
+		_, _, ok := fakeRegistry.Manifest("app", descriptor.Digest.String())
+		assert.True(t, ok)
+	}
+
+	// The local manifest list was purged.
+	err = runCommand(cli, "rm", host+"/app:1.0")
+	assert.EqualError(t, err, "No such manifest: "+host+"/app:1.0")
+}
+
+func TestCreateManifestListRejectsLists(t *testing.T) {
+	root, err := ioutil.TempDir("", "manifests")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	fakeRegistry := registry.NewFakeRegistry()
+	defer fakeRegistry.Close()
+	fakeRegistry.AddManifest("list", "latest", manifestlist.MediaTypeManifestList, []byte(`{"schemaVersion":2,"manifests":[]}`))
+	host := fakeRegistry.Host()
+
+	cli, _ := newTestCli(root)
+	err = runCommand(cli, "create", host+"/app", host+"/list")
+	assert.EqualError(t, err, host+"/list:latest is a manifest list")
+
+	err = runCommand(cli, "create", host+"/app", "busybox")
+	assert.EqualError(t, err, "cannot add busybox to a manifest list in a different registry")
+}
+
+func TestInspectManifest(t *testing.T) {
+	root, err := ioutil.TempDir("", "manifests")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	fakeRegistry := registry.NewFakeRegistry()
+	defer fakeRegistry.Close()
+	addImage(fakeRegistry, "app", "linux", "amd64")
+	host := fakeRegistry.Host()
+
+	cli, out := newTestCli(root)
+	require.NoError(t, runCommand(cli, "inspect", host+"/app"))
+	assert.Contains(t, out.String(), "\t\"schemaVersion\": 2,\n")
+
+	out.Reset()
+	require.NoError(t, runCommand(cli, "inspect", "--verbose", host+"/app"))
+	var image types.ImageManifest
+	require.NoError(t, json.Unmarshal(out.Bytes(), &image))
+	assert.Equal(t, manifestlist.PlatformSpec{OS: "linux", Architecture: "amd64"}, image.Descriptor.Platform)
+	assert.Len(t, image.Blobs, 2)
+
+	// A local manifest list is shown as it would be pushed.
+	require.NoError(t, runCommand(cli, "create", host+"/app:list", host+"/app"))
+	out.Reset()
+	require.NoError(t, runCommand(cli, "inspect", host+"/app:list"))
+	var list manifestlist.ManifestList
+	require.NoError(t, json.Unmarshal(out.Bytes(), &list))
+	require.Len(t, list.Manifests, 1)
+	assert.Equal(t, image.Descriptor, list.Manifests[0])
+}
@@ -0,0 +1,112 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/manifest/store"
+	"github.com/docker/cli/cli/manifest/types"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+type pushOpts struct {
+	insecure bool
+	purge    bool
+	target   string
+}
+
+func newPushListCommand(dockerCli command.Cli) *cobra.Command {
+	opts := pushOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "push [OPTIONS] MANIFEST_LIST",
+		Short: "Push a manifest list to a repository",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.target = args[0]
+			return runPush(dockerCli, opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&opts.purge, "purge", "p", false, "Remove the local manifest list after push")
+	flags.BoolVar(&opts.insecure, "insecure", false, "Allow push to an insecure registry")
+	return cmd
+}
+
+func runPush(dockerCli command.Cli, opts pushOpts) error {
+	targetRef, err := normalizeReference(opts.target)
+	if err != nil {
+		return err
+	}
+
+	manifestStore := dockerCli.ManifestStore()
+	images, err := manifestStore.GetList(targetRef)
+	switch {
+	case store.IsNotFound(err):
+		return errors.Errorf("%s not found", targetRef)
+	case err != nil:
+		return err
+	case len(images) == 0:
+		return errors.Errorf("%s has no images", targetRef)
+	}
+
+	ctx := context.Background()
+	client := dockerCli.RegistryClient(opts.insecure)
+	for _, image := range images {
+		if err := copyImageManifest(ctx, client, targetRef, image); err != nil {
+			return err
+		}
+	}
+
+	list, err := buildManifestList(images)
+	if err != nil {
+		return err
+	}
+	dgst, err := client.PutManifest(ctx, targetRef, list)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(dockerCli.Out(), dgst.String())
+
+	if opts.purge {
+		return manifestStore.Remove(targetRef)
+	}
+	return nil
+}
+
+// copyImageManifest makes the manifest of image, and its blobs, available in
+// the repository of the manifest list targetRef if it is in another one.
+func copyImageManifest(ctx context.Context, client registryclient.RegistryClient, targetRef reference.Named, image types.ImageManifest) error {
+	imageRef, err := reference.ParseNormalizedNamed(image.Ref)
+	if err != nil {
+		return errors.Wrapf(err, "invalid reference %s in the manifest list", image.Ref)
+	}
+	if reference.Domain(imageRef) != reference.Domain(targetRef) {
+		return errors.Errorf("cannot push a manifest list to a different registry than its image %s", reference.FamiliarString(imageRef))
+	}
+	if imageRef.Name() == targetRef.Name() {
+		return nil
+	}
+
+	for _, blob := range image.Blobs {
+		if err := client.MountBlob(ctx, targetRef, blob, imageRef); err != nil {
+			return err
+		}
+	}
+	manifest, err := client.GetManifest(ctx, imageRef)
+	if err != nil {
+		return err
+	}
+	targetImageRef, err := reference.WithDigest(reference.TrimNamed(targetRef), manifest.Digest)
+	if err != nil {
+		return err
+	}
+	_, err = client.PutManifest(ctx, targetImageRef, manifest)
+	return err
+}
@@ -0,0 +1,46 @@
+package manifest
+
+import (
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newRemoveCommand(dockerCli command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rm MANIFEST_LIST [MANIFEST_LIST...]",
+		Short: "Delete one or more local manifest lists",
+		Args:  cli.RequiresMinArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRm(dockerCli, args)
+		},
+	}
+
+	return cmd
+}
+
+func runRm(dockerCli command.Cli, targets []string) error {
+	manifestStore := dockerCli.ManifestStore()
+	var errs []string
+	for _, target := range targets {
+		targetRef, err := normalizeReference(target)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if _, err := manifestStore.GetList(targetRef); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if err := manifestStore.Remove(targetRef); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package manifest
+
+import (
+	"encoding/json"
+
+	"github.com/docker/cli/cli/manifest/types"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// validOSArches are the valid combinations of operating system and
+// architecture of the images of a manifest list.
+var validOSArches = map[string][]string{
+	"darwin":    {"386", "amd64", "arm", "arm64"},
+	"dragonfly": {"amd64"},
+	"freebsd":   {"386", "amd64", "arm"},
+	"linux":     {"386", "amd64", "arm", "arm64", "ppc64", "ppc64le", "mips64", "mips64le", "s390x"},
+	"netbsd":    {"386", "amd64", "arm"},
+	"openbsd":   {"386", "amd64", "arm"},
+	"plan9":     {"386", "amd64"},
+	"solaris":   {"amd64"},
+	"windows":   {"386", "amd64"},
+}
+
+func isValidOSArch(os, arch string) bool {
+	for _, validArch := range validOSArches[os] {
+		if arch == validArch {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeReference parses ref, tagged with latest if it has neither a tag
+// nor a digest.
+func normalizeReference(ref string) (reference.Named, error) {
+	namedRef, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, err
+	}
+	if _, isDigested := namedRef.(reference.Canonical); !isDigested {
+		return reference.TagNameOnly(namedRef), nil
+	}
+	return namedRef, nil
+}
+
+// imageManifestContent is the part of an image manifest listing its blobs.
+type imageManifestContent struct {
+	Config distribution.Descriptor   `json:"config"`
+	Layers []distribution.Descriptor `json:"layers"`
+}
+
+// imageConfig is the part of an image configuration giving its platform.
+type imageConfig struct {
+	OS           string   `json:"os"`
+	Architecture string   `json:"architecture"`
+	Variant      string   `json:"variant,omitempty"`
+	OSVersion    string   `json:"os.version,omitempty"`
+	OSFeatures   []string `json:"os.features,omitempty"`
+}
+
+func isManifestList(mediaType string) bool {
+	return mediaType == manifestlist.MediaTypeManifestList || mediaType == types.MediaTypeOCIIndex
+}
+
+// getImageManifest returns the manifest of the image ref, with the platform
+// of its configuration.
+func getImageManifest(ctx context.Context, client registryclient.RegistryClient, ref reference.Named) (types.ImageManifest, error) {
+	manifest, err := client.GetManifest(ctx, ref)
+	if err != nil {
+		return types.ImageManifest{}, err
+	}
+	return imageManifestFromRegistry(ctx, client, ref, manifest)
+}
+
+func imageManifestFromRegistry(ctx context.Context, client registryclient.RegistryClient, ref reference.Named, manifest registryclient.Manifest) (types.ImageManifest, error) {
+	switch {
+	case isManifestList(manifest.MediaType):
+		return types.ImageManifest{}, errors.Errorf("%s is a manifest list", reference.FamiliarString(ref))
+	case manifest.MediaType != types.MediaTypeManifest && manifest.MediaType != types.MediaTypeOCIManifest:
+		return types.ImageManifest{}, errors.Errorf("unsupported manifest format %q for %s", manifest.MediaType, reference.FamiliarString(ref))
+	}
+
+	var content imageManifestContent
+	if err := json.Unmarshal(manifest.Raw, &content); err != nil {
+		return types.ImageManifest{}, errors.Wrapf(err, "invalid manifest for %s", reference.FamiliarString(ref))
+	}
+	rawConfig, err := client.GetBlob(ctx, ref, content.Config.Digest)
+	if err != nil {
+		return types.ImageManifest{}, err
+	}
+	var config imageConfig
+	if err := json.Unmarshal(rawConfig, &config); err != nil {
+		return types.ImageManifest{}, errors.Wrapf(err, "invalid image configuration for %s", reference.FamiliarString(ref))
+	}
+
+	canonical, err := reference.WithDigest(reference.TrimNamed(ref), manifest.Digest)
+	if err != nil {
+		return types.ImageManifest{}, err
+	}
+	blobs := []digest.Digest{content.Config.Digest}
+	for _, layer := range content.Layers {
+		blobs = append(blobs, layer.Digest)
+	}
+	return types.ImageManifest{
+		Ref: canonical.String(),
+		Descriptor: manifestlist.ManifestDescriptor{
+			Descriptor: distribution.Descriptor{
+				MediaType: manifest.MediaType,
+				Size:      int64(len(manifest.Raw)),
+				Digest:    manifest.Digest,
+			},
+			Platform: manifestlist.PlatformSpec{
+				OS:           config.OS,
+				Architecture: config.Architecture,
+				Variant:      config.Variant,
+				OSVersion:    config.OSVersion,
+				OSFeatures:   config.OSFeatures,
+			},
+		},
+		Blobs: blobs,
+	}, nil
+}
+
+// buildManifestList returns the manifest list of images.
+func buildManifestList(images []types.ImageManifest) (registryclient.Manifest, error) {
+	var descriptors []manifestlist.ManifestDescriptor
+	for _, image := range images {
+		descriptors = append(descriptors, image.Descriptor)
+	}
+	list, err := manifestlist.FromDescriptors(descriptors)
+	if err != nil {
+		return registryclient.Manifest{}, err
+	}
+	mediaType, raw, err := list.Payload()
+	if err != nil {
+		return registryclient.Manifest{}, err
+	}
+	return registryclient.Manifest{MediaType: mediaType, Digest: digest.FromBytes(raw), Raw: raw}, nil
+}
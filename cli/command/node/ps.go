@@ -9,6 +9,7 @@ import (
 	"github.com/docker/cli/cli/command/idresolver"
 	"github.com/docker/cli/cli/command/task"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/opts"
 	"github.com/pkg/errors"
@@ -23,6 +24,7 @@ type psOptions struct {
 	quiet     bool
 	format    string
 	filter    opts.FilterOpt
+	watch     bool
 }
 
 func newPsCommand(dockerCli command.Cli) *cobra.Command {
@@ -48,6 +50,7 @@ func newPsCommand(dockerCli command.Cli) *cobra.Command {
 	flags.VarP(&opts.filter, "filter", "f", "Filter output based on conditions provided")
 	flags.StringVar(&opts.format, "format", "", "Pretty-print tasks using a Go template")
 	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Only display task IDs")
+	flags.BoolVar(&opts.watch, "watch", false, "Watch the tasks and redraw them as they change")
 
 	return cmd
 }
@@ -56,6 +59,19 @@ func runPs(dockerCli command.Cli, opts psOptions) error {
 	client := dockerCli.Client()
 	ctx := context.Background()
 
+	format := opts.format
+	if len(format) == 0 {
+		if dockerCli.ConfigFile() != nil && len(dockerCli.ConfigFile().TasksFormat) > 0 && !opts.quiet {
+			format = dockerCli.ConfigFile().TasksFormat
+		} else {
+			format = formatter.TableFormatKey
+		}
+	}
+
+	if opts.watch {
+		return watchPs(ctx, dockerCli, opts, format)
+	}
+
 	var (
 		errs  []string
 		tasks []swarm.Task
@@ -86,15 +102,6 @@ func runPs(dockerCli command.Cli, opts psOptions) error {
 		tasks = append(tasks, nodeTasks...)
 	}
 
-	format := opts.format
-	if len(format) == 0 {
-		if dockerCli.ConfigFile() != nil && len(dockerCli.ConfigFile().TasksFormat) > 0 && !opts.quiet {
-			format = dockerCli.ConfigFile().TasksFormat
-		} else {
-			format = formatter.TableFormatKey
-		}
-	}
-
 	if len(errs) == 0 || len(tasks) != 0 {
 		if err := task.Print(ctx, dockerCli, tasks, idresolver.New(client, opts.noResolve), !opts.noTrunc, opts.quiet, format); err != nil {
 			errs = append(errs, err.Error())
@@ -107,3 +114,31 @@ func runPs(dockerCli command.Cli, opts psOptions) error {
 
 	return nil
 }
+
+// watchPs keeps listing the tasks of the nodes in opts. Unlike a single
+// listing, any node that cannot be resolved is an error.
+func watchPs(ctx context.Context, dockerCli command.Cli, opts psOptions, format string) error {
+	client := dockerCli.Client()
+
+	eventFilter := filters.NewArgs()
+	eventFilter.Add("type", "node")
+
+	filter := opts.filter.Value()
+	for _, nodeID := range opts.nodeIDs {
+		nodeRef, err := Reference(ctx, client, nodeID)
+		if err != nil {
+			return err
+		}
+		node, _, err := client.NodeInspectWithRaw(ctx, nodeRef)
+		if err != nil {
+			return err
+		}
+		filter.Add("node", node.ID)
+		eventFilter.Add("node", node.ID)
+	}
+
+	listTasks := func(ctx context.Context) ([]swarm.Task, error) {
+		return client.TaskList(ctx, types.TaskListOptions{Filters: filter})
+	}
+	return task.Watch(ctx, dockerCli, listTasks, eventFilter, idresolver.New(client, opts.noResolve), !opts.noTrunc, opts.quiet, format)
+}
@@ -0,0 +1,24 @@
+package registry
+
+import (
+	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/client"
+	"golang.org/x/net/context"
+)
+
+type fakeClient struct {
+	client.Client
+	imageSearchFunc func(term string, options types.ImageSearchOptions) ([]registrytypes.SearchResult, error)
+}
+
+func (cli *fakeClient) ImageSearch(_ context.Context, term string, options types.ImageSearchOptions) ([]registrytypes.SearchResult, error) {
+	if cli.imageSearchFunc != nil {
+		return cli.imageSearchFunc(term, options)
+	}
+	return nil, nil
+}
+
+func (cli *fakeClient) Info(_ context.Context) (types.Info, error) {
+	return types.Info{}, nil
+}
@@ -2,26 +2,43 @@ package registry
 
 import (
 	"fmt"
+	"net/http"
 	"sort"
+	"strconv"
 	"strings"
-	"text/tabwriter"
 
 	"golang.org/x/net/context"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
 	registrytypes "github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/opts"
-	"github.com/docker/docker/pkg/stringutils"
 	"github.com/docker/docker/registry"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+// maxSearchResults is the maximum number of results returned by the daemon
+// for a search.
+const maxSearchResults = 100
+
+const (
+	sortByStars = "stars"
+	sortByName  = "name"
+)
+
 type searchOptions struct {
+	format  string
 	term    string
 	noTrunc bool
 	limit   int
+	page    int
+	all     bool
+	sort    string
 	filter  opts.FilterOpt
 
 	// Deprecated
@@ -36,7 +53,17 @@ func NewSearchCommand(dockerCli command.Cli) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "search [OPTIONS] TERM",
 		Short: "Search the Docker Hub for images",
-		Args:  cli.ExactArgs(1),
+		Long: `Search the Docker Hub for images.
+
+A term starting with the host of a registry, such as "registry.example.com/app",
+searches this registry instead. If the registry does not support searches,
+its repositories matching the term are listed from its catalog, with their
+tags.
+
+Results are shown by pages of --limit results, sorted by stars or name within
+each page. Searches return at most 100 results, while all the repositories
+listed from a catalog can be paged through.`,
+		Args: cli.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.term = args[0]
 			return runSearch(dockerCli, opts)
@@ -47,7 +74,11 @@ func NewSearchCommand(dockerCli command.Cli) *cobra.Command {
 
 	flags.BoolVar(&opts.noTrunc, "no-trunc", false, "Don't truncate output")
 	flags.VarP(&opts.filter, "filter", "f", "Filter output based on conditions provided")
-	flags.IntVar(&opts.limit, "limit", registry.DefaultSearchLimit, "Max number of search results")
+	flags.IntVar(&opts.limit, "limit", registry.DefaultSearchLimit, "Max number of search results, per page")
+	flags.IntVar(&opts.page, "page", 1, "Page of results to show, with --limit results per page")
+	flags.BoolVar(&opts.all, "all", false, "Show all the results")
+	flags.StringVar(&opts.sort, "sort", sortByStars, `Sort the results by "stars" or "name"`)
+	flags.StringVar(&opts.format, "format", "", "Pretty-print search results using a Go template, or \"json\"")
 
 	flags.BoolVar(&opts.automated, "automated", false, "Only show automated builds")
 	flags.UintVarP(&opts.stars, "stars", "s", 0, "Only displays with at least x stars")
@@ -59,6 +90,19 @@ func NewSearchCommand(dockerCli command.Cli) *cobra.Command {
 }
 
 func runSearch(dockerCli command.Cli, opts searchOptions) error {
+	if opts.limit < 1 {
+		return errors.Errorf("invalid value %d for --limit: must be at least 1", opts.limit)
+	}
+	if opts.page < 1 {
+		return errors.Errorf("invalid value %d for --page: must be at least 1", opts.page)
+	}
+	if opts.all && opts.page > 1 {
+		return errors.New("--page cannot be used with --all")
+	}
+	if opts.sort != sortByStars && opts.sort != sortByName {
+		return errors.Errorf("invalid value %q for --sort: must be %q or %q", opts.sort, sortByStars, sortByName)
+	}
+
 	indexInfo, err := registry.ParseSearchIndexInfo(opts.term)
 	if err != nil {
 		return err
@@ -66,61 +110,175 @@ func runSearch(dockerCli command.Cli, opts searchOptions) error {
 
 	ctx := context.Background()
 
+	results, err := searchDaemon(ctx, dockerCli, indexInfo, opts)
+	fromCatalog := false
+	if err != nil && !indexInfo.Official && isSearchUnavailable(err) {
+		var catalogErr error
+		if results, catalogErr = searchCatalog(ctx, dockerCli, indexInfo, opts); catalogErr != nil {
+			return errors.Errorf("%s, and listing the repositories of %s failed: %s", err, indexInfo.Name, catalogErr)
+		}
+		err, fromCatalog = nil, true
+	}
+	if err != nil {
+		return err
+	}
+	if !fromCatalog && !opts.all && opts.page*opts.limit > maxSearchResults {
+		return errors.Errorf("searches return at most %d results: --page %d of %d results is out of range", maxSearchResults, opts.page, opts.limit)
+	}
+
+	var filtered []registrytypes.SearchResult
+	for _, res := range results {
+		// --automated and -s, --stars are deprecated since Docker 1.12
+		if (opts.automated && !res.IsAutomated) || (int(opts.stars) > res.StarCount) {
+			continue
+		}
+		filtered = append(filtered, res)
+	}
+	results = filtered
+	if !opts.all {
+		results = searchPage(results, opts.page, opts.limit)
+	}
+	if fromCatalog {
+		if err := addCatalogTags(ctx, dockerCli, results); err != nil {
+			return err
+		}
+	}
+	sortSearchResults(results, opts.sort)
+
+	searchCtx := formatter.Context{
+		Output: dockerCli.Out(),
+		Format: formatter.NewSearchFormat(opts.format),
+		Trunc:  !opts.noTrunc,
+	}
+	return formatter.SearchWrite(searchCtx, results)
+}
+
+// searchDaemon searches a registry with the daemon, which returns the
+// results of the pages up to the requested one.
+func searchDaemon(ctx context.Context, dockerCli command.Cli, indexInfo *registrytypes.IndexInfo, opts searchOptions) ([]registrytypes.SearchResult, error) {
+	limit := opts.page * opts.limit
+	if opts.all || limit > maxSearchResults {
+		limit = maxSearchResults
+	}
+
 	authConfig := command.ResolveAuthConfig(ctx, dockerCli, indexInfo)
 	requestPrivilege := command.RegistryAuthenticationPrivilegedFunc(dockerCli, indexInfo, "search")
 
 	encodedAuth, err := command.EncodeAuthToBase64(authConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	options := types.ImageSearchOptions{
 		RegistryAuth:  encodedAuth,
 		PrivilegeFunc: requestPrivilege,
 		Filters:       opts.filter.Value(),
-		Limit:         opts.limit,
+		Limit:         limit,
 	}
 
-	clnt := dockerCli.Client()
+	return dockerCli.Client().ImageSearch(ctx, opts.term, options)
+}
 
-	unorderedResults, err := clnt.ImageSearch(ctx, opts.term, options)
-	if err != nil {
-		return err
+// isSearchUnavailable returns whether a search failed because the registry
+// does not implement the search endpoint. The daemon reports the status
+// returned by the registry in the message of the error.
+func isSearchUnavailable(err error) bool {
+	for _, status := range []int{http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		if strings.Contains(err.Error(), fmt.Sprintf("Unexpected status code %d", status)) {
+			return true
+		}
 	}
+	return false
+}
 
-	results := searchResultsByStars(unorderedResults)
-	sort.Sort(results)
+// searchCatalog returns the repositories of a registry whose name contains
+// the search term, from its catalog, for registries without search.
+func searchCatalog(ctx context.Context, dockerCli command.Cli, indexInfo *registrytypes.IndexInfo, opts searchOptions) ([]registrytypes.SearchResult, error) {
+	repositories, err := dockerCli.RegistryClient(false).GetRepositories(ctx, indexInfo)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(repositories)
+	searchFilters := opts.filter.Value()
+	if err := searchFilters.Validate(map[string]bool{"stars": true, "is-automated": true, "is-official": true}); err != nil {
+		return nil, err
+	}
 
-	w := tabwriter.NewWriter(dockerCli.Out(), 10, 1, 3, ' ', 0)
-	fmt.Fprintf(w, "NAME\tDESCRIPTION\tSTARS\tOFFICIAL\tAUTOMATED\n")
-	for _, res := range results {
-		// --automated and -s, --stars are deprecated since Docker 1.12
-		if (opts.automated && !res.IsAutomated) || (int(opts.stars) > res.StarCount) {
+	term := strings.ToLower(strings.TrimPrefix(opts.term, indexInfo.Name+"/"))
+	var results []registrytypes.SearchResult
+	for _, repository := range repositories {
+		if !strings.Contains(strings.ToLower(repository), term) {
 			continue
 		}
-		desc := strings.Replace(res.Description, "\n", " ", -1)
-		desc = strings.Replace(desc, "\r", " ", -1)
-		if !opts.noTrunc {
-			desc = stringutils.Ellipsis(desc, 45)
+		result := registrytypes.SearchResult{Name: indexInfo.Name + "/" + repository}
+		match, err := matchSearchFilters(result, searchFilters)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			results = append(results, result)
 		}
-		fmt.Fprintf(w, "%s\t%s\t%d\t", res.Name, desc, res.StarCount)
-		if res.IsOfficial {
-			fmt.Fprint(w, "[OK]")
+	}
+	return results, nil
+}
+
+// matchSearchFilters returns whether a result matches the filters, as
+// applied by the daemon to the results of searches.
+func matchSearchFilters(result registrytypes.SearchResult, searchFilters filters.Args) (bool, error) {
+	for _, value := range searchFilters.Get("stars") {
+		stars, err := strconv.Atoi(value)
+		if err != nil {
+			return false, errors.Errorf("invalid filter 'stars=%s'", value)
+		}
+		if result.StarCount < stars {
+			return false, nil
+		}
+	}
+	for field, value := range map[string]bool{"is-automated": result.IsAutomated, "is-official": result.IsOfficial} {
+		if searchFilters.Include(field) && !searchFilters.ExactMatch(field, strconv.FormatBool(value)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
 
+// addCatalogTags adds the tags of the repositories listed from the catalog
+// of a registry to their description.
+func addCatalogTags(ctx context.Context, dockerCli command.Cli, results []registrytypes.SearchResult) error {
+	for i, result := range results {
+		repo, err := reference.ParseNormalizedNamed(result.Name)
+		if err != nil {
+			return err
 		}
-		fmt.Fprint(w, "\t")
-		if res.IsAutomated {
-			fmt.Fprint(w, "[OK]")
+		tags, err := dockerCli.RegistryClient(false).GetTags(ctx, repo)
+		if err != nil {
+			return err
 		}
-		fmt.Fprint(w, "\n")
+		sort.Strings(tags)
+		results[i].Description = "Tags: " + strings.Join(tags, ", ")
 	}
-	w.Flush()
 	return nil
 }
 
-// searchResultsByStars sorts search results in descending order by number of stars.
-type searchResultsByStars []registrytypes.SearchResult
+// searchPage returns the results of a page, starting from 1.
+func searchPage(results []registrytypes.SearchResult, page, limit int) []registrytypes.SearchResult {
+	start := (page - 1) * limit
+	if start >= len(results) {
+		return nil
+	}
+	if end := start + limit; end < len(results) {
+		return results[start:end]
+	}
+	return results[start:]
+}
 
-func (r searchResultsByStars) Len() int           { return len(r) }
-func (r searchResultsByStars) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
-func (r searchResultsByStars) Less(i, j int) bool { return r[j].StarCount < r[i].StarCount }
+// sortSearchResults sorts search results in descending order by number of
+// stars, or by name.
+func sortSearchResults(results []registrytypes.SearchResult, by string) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if by == sortByName {
+			return results[i].Name < results[j].Name
+		}
+		return results[j].StarCount < results[i].StarCount
+	})
+}
@@ -0,0 +1,177 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/cli/cli/internal/test/registry"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/pkg/testutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func searchResults(n int) []registrytypes.SearchResult {
+	var results []registrytypes.SearchResult
+	for i := 0; i < n; i++ {
+		results = append(results, registrytypes.SearchResult{
+			Name:      fmt.Sprintf("image%02d", i),
+			StarCount: i % 3,
+		})
+	}
+	return results
+}
+
+func TestSearchPagination(t *testing.T) {
+	var limit int
+	buf := new(bytes.Buffer)
+	cli := test.NewFakeCli(&fakeClient{
+		imageSearchFunc: func(term string, options types.ImageSearchOptions) ([]registrytypes.SearchResult, error) {
+			limit = options.Limit
+			return searchResults(options.Limit), nil
+		},
+	}, buf)
+	cmd := NewSearchCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--limit", "3", "--page", "2", "--format", "{{.Name}} {{.StarCount}}", "image"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, 6, limit)
+	assert.Equal(t, "image05 2\nimage04 1\nimage03 0\n", buf.String())
+
+	buf.Reset()
+	cmd = NewSearchCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--all", "--sort", "name", "--format", "{{.Name}}", "image"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, 100, limit)
+	assert.True(t, strings.HasPrefix(buf.String(), "image00\nimage01\nimage02\n"))
+}
+
+func TestSearchErrors(t *testing.T) {
+	testCases := []struct {
+		args          []string
+		expectedError string
+	}{
+		{args: []string{"--page", "0", "image"}, expectedError: "invalid value 0 for --page"},
+		{args: []string{"--all", "--page", "2", "image"}, expectedError: "--page cannot be used with --all"},
+		{args: []string{"--sort", "date", "image"}, expectedError: `invalid value "date" for --sort`},
+		{args: []string{"image"}, expectedError: "search failed"},
+	}
+	for _, tc := range testCases {
+		cmd := NewSearchCommand(test.NewFakeCli(&fakeClient{
+			imageSearchFunc: func(term string, options types.ImageSearchOptions) ([]registrytypes.SearchResult, error) {
+				return nil, errors.New("search failed")
+			},
+		}, new(bytes.Buffer)))
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs(tc.args)
+		testutil.ErrorContains(t, cmd.Execute(), tc.expectedError)
+	}
+}
+
+func TestSearchPageOutOfRange(t *testing.T) {
+	var limit int
+	cmd := NewSearchCommand(test.NewFakeCli(&fakeClient{
+		imageSearchFunc: func(term string, options types.ImageSearchOptions) ([]registrytypes.SearchResult, error) {
+			limit = options.Limit
+			return searchResults(options.Limit), nil
+		},
+	}, new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--page", "5", "--limit", "25", "image"})
+	testutil.ErrorContains(t, cmd.Execute(), "searches return at most 100 results")
+	assert.Equal(t, 100, limit)
+}
+
+// newCatalogRegistry returns a fake registry with repositories, and a fake
+// cli using it, whose searches fail with searchErr.
+func newCatalogRegistry(searchErr error) (*registry.FakeRegistry, *test.FakeCli, *bytes.Buffer) {
+	fakeRegistry := registry.NewFakeRegistry()
+	for _, ref := range []string{"team/api:1.0", "team/api:latest", "team/web:2", "db:9.6"} {
+		i := strings.LastIndex(ref, ":")
+		fakeRegistry.AddManifest(ref[:i], ref[i+1:], "application/vnd.docker.distribution.manifest.v2+json", []byte(`{"tag":"`+ref+`"}`))
+	}
+
+	buf := new(bytes.Buffer)
+	cli := test.NewFakeCli(&fakeClient{
+		imageSearchFunc: func(term string, options types.ImageSearchOptions) ([]registrytypes.SearchResult, error) {
+			return nil, searchErr
+		},
+	}, buf)
+	resolver := func(ctx context.Context, index *registrytypes.IndexInfo) types.AuthConfig {
+		return types.AuthConfig{}
+	}
+	cli.SetRegistryClient(registryclient.NewRegistryClient(resolver, "test", false))
+	return fakeRegistry, cli, buf
+}
+
+func TestSearchCatalogFallback(t *testing.T) {
+	fakeRegistry, cli, buf := newCatalogRegistry(errors.New("Unexpected status code 404"))
+	defer fakeRegistry.Close()
+	host := fakeRegistry.Host()
+
+	cmd := NewSearchCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--format", "{{.Name}}: {{.Description}}", host + "/team"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, host+"/team/api: Tags: 1.0, latest\n"+host+"/team/web: Tags: 2\n", buf.String())
+
+	cmd = NewSearchCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--filter", "is-official=true", host + "/team"})
+	buf.Reset()
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+}
+
+func TestSearchCatalogFallbackStatuses(t *testing.T) {
+	for _, status := range []int{404, 405, 501} {
+		fakeRegistry, cli, buf := newCatalogRegistry(errors.Errorf("Unexpected status code %d", status))
+		cmd := NewSearchCommand(cli)
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs([]string{"--format", "{{.Name}}", fakeRegistry.Host() + "/db"})
+		require.NoError(t, cmd.Execute(), "status %d", status)
+		assert.Equal(t, fakeRegistry.Host()+"/db\n", buf.String())
+		fakeRegistry.Close()
+	}
+}
+
+func TestSearchCatalogPagination(t *testing.T) {
+	fakeRegistry, cli, buf := newCatalogRegistry(errors.New("Unexpected status code 404"))
+	defer fakeRegistry.Close()
+
+	// The results listed from a catalog are not limited to 100.
+	cmd := NewSearchCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--page", "5", "--limit", "25", fakeRegistry.Host() + "/team"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+}
+
+func TestSearchErrorsWithoutCatalogFallback(t *testing.T) {
+	testCases := []error{
+		errors.New("Unexpected status code 401"),
+		errors.New("Get https://registry.example.com/v1/search: x509: certificate signed by unknown authority"),
+		errors.New("Get https://registry.example.com/v1/search: net/http: request canceled (Client.Timeout exceeded while awaiting headers)"),
+		errors.New("Invalid filter 'unknown'"),
+	}
+	for _, searchErr := range testCases {
+		fakeRegistry, cli, buf := newCatalogRegistry(searchErr)
+		cmd := NewSearchCommand(cli)
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs([]string{fakeRegistry.Host() + "/team"})
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Equal(t, searchErr.Error(), err.Error())
+		assert.Empty(t, buf.String())
+		fakeRegistry.Close()
+	}
+}
@@ -11,7 +11,7 @@ import (
 	"golang.org/x/net/context"
 )
 
-func newCreateCommand(dockerCli *command.DockerCli) *cobra.Command {
+func newCreateCommand(dockerCli command.Cli) *cobra.Command {
 	opts := newServiceOptions()
 
 	cmd := &cobra.Command{
@@ -29,6 +29,7 @@ func newCreateCommand(dockerCli *command.DockerCli) *cobra.Command {
 	flags := cmd.Flags()
 	flags.StringVar(&opts.mode, flagMode, "replicated", "Service mode (replicated or global)")
 	flags.StringVar(&opts.name, flagName, "", "Service name")
+	flags.BoolVar(&opts.dryRun, flagDryRun, false, "Show where tasks would be scheduled without creating the service")
 
 	addServiceFlags(flags, opts, buildServiceDefaultFlagMapping())
 
@@ -36,6 +37,7 @@ func newCreateCommand(dockerCli *command.DockerCli) *cobra.Command {
 	flags.Var(&opts.containerLabels, flagContainerLabel, "Container labels")
 	flags.VarP(&opts.env, flagEnv, "e", "Set environment variables")
 	flags.Var(&opts.envFile, flagEnvFile, "Read in a file of environment variables")
+	flags.StringVar(&opts.envFileFormat, flagEnvFileFormat, "", `Format of the env files ("plain" or "extended"), detected from their header by default`)
 	flags.Var(&opts.mounts, flagMount, "Attach a filesystem mount to the service")
 	flags.Var(&opts.constraints, flagConstraint, "Placement constraints")
 	flags.Var(&opts.placementPrefs, flagPlacementPref, "Add a placement preference")
@@ -61,7 +63,11 @@ func newCreateCommand(dockerCli *command.DockerCli) *cobra.Command {
 	return cmd
 }
 
-func runCreate(dockerCli *command.DockerCli, flags *pflag.FlagSet, opts *serviceOptions) error {
+func runCreate(dockerCli command.Cli, flags *pflag.FlagSet, opts *serviceOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
 	apiClient := dockerCli.Client()
 	createOpts := types.ServiceCreateOptions{}
 
@@ -92,6 +98,10 @@ func runCreate(dockerCli *command.DockerCli, flags *pflag.FlagSet, opts *service
 		service.TaskTemplate.ContainerSpec.Configs = configs
 	}
 
+	if opts.dryRun {
+		return runDryRun(ctx, dockerCli, service)
+	}
+
 	if err := resolveServiceImageDigest(dockerCli, &service); err != nil {
 		return err
 	}
@@ -125,5 +135,5 @@ func runCreate(dockerCli *command.DockerCli, flags *pflag.FlagSet, opts *service
 		return nil
 	}
 
-	return waitOnService(ctx, dockerCli, response.ID, opts)
+	return waitOnService(ctx, dockerCli, response.ID, &opts.waitOptions)
 }
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateInvalidProgressFormat(t *testing.T) {
+	for _, args := range [][]string{
+		{"--progress", "bogus", "busybox"},
+		{"--progress", "bogus", "--detach=false", "busybox"},
+	} {
+		created := false
+		cli := test.NewFakeCli(&fakeClient{
+			serviceCreateFunc: func(service swarm.ServiceSpec, options types.ServiceCreateOptions) (types.ServiceCreateResponse, error) {
+				created = true
+				return types.ServiceCreateResponse{ID: "id"}, nil
+			},
+		}, new(bytes.Buffer))
+		cmd := newCreateCommand(cli)
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs(args)
+		testutil.ErrorContains(t, cmd.Execute(), `invalid progress format "bogus"`)
+		assert.False(t, created)
+	}
+}
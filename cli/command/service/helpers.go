@@ -3,20 +3,47 @@ package service
 import (
 	"io"
 
+	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/service/progress"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"golang.org/x/net/context"
 )
 
+// Exit codes used when waiting for a service that did not converge
+const (
+	exitCodeRolledBack = 2
+	exitCodeTimedOut   = 3
+)
+
 // waitOnService waits for the service to converge. It outputs a progress bar,
 // if appopriate based on the CLI flags.
-func waitOnService(ctx context.Context, dockerCli *command.DockerCli, serviceID string, opts *serviceOptions) error {
-	errChan := make(chan error, 1)
+func waitOnService(ctx context.Context, dockerCli command.Cli, serviceID string, opts *waitOptions) error {
+	return waitOnServices(ctx, dockerCli, []string{serviceID}, opts)[0]
+}
+
+// waitOnServices waits for several services to converge at once, with their
+// progress combined in a single output. It returns the result of each
+// service, in the order of serviceIDs.
+func waitOnServices(ctx context.Context, dockerCli command.Cli, serviceIDs []string, opts *waitOptions) []error {
+	if opts.waitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.waitTimeout)
+		defer cancel()
+	}
+	errs := runServicesProgress(ctx, dockerCli, serviceIDs, opts)
+	for i, err := range errs {
+		errs[i] = convergenceStatus(ctx, serviceIDs[i], err)
+	}
+	return errs
+}
+
+func runServicesProgress(ctx context.Context, dockerCli command.Cli, serviceIDs []string, opts *waitOptions) []error {
+	errChan := make(chan []error, 1)
 	pipeReader, pipeWriter := io.Pipe()
 
 	go func() {
-		errChan <- progress.ServiceProgress(ctx, dockerCli.Client(), serviceID, pipeWriter)
+		errChan <- progress.ServicesProgress(ctx, dockerCli.Client(), serviceIDs, pipeWriter, opts.progress)
 	}()
 
 	if opts.quiet {
@@ -31,9 +58,36 @@ func waitOnService(ctx context.Context, dockerCli *command.DockerCli, serviceID
 		return <-errChan
 	}
 
-	err := jsonmessage.DisplayJSONMessagesToStream(pipeReader, dockerCli.Out(), nil)
-	if err == nil {
-		err = <-errChan
+	var err error
+	if opts.progress != progress.FormatTTY {
+		_, err = io.Copy(dockerCli.Out(), pipeReader)
+	} else {
+		err = jsonmessage.DisplayJSONMessagesToStream(pipeReader, dockerCli.Out(), nil)
+	}
+	if err != nil {
+		errs := make([]error, len(serviceIDs))
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	return <-errChan
+}
+
+// convergenceStatus maps the result of waiting on a service to an exit
+// status that distinguishes rollbacks and timeouts from other failures.
+func convergenceStatus(ctx context.Context, serviceID string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() == context.DeadlineExceeded:
+		return cli.StatusError{
+			Status:     "timed out waiting for service " + serviceID + " to converge",
+			StatusCode: exitCodeTimedOut,
+		}
+	}
+	if _, ok := err.(progress.RolledBackError); ok {
+		return cli.StatusError{Status: err.Error(), StatusCode: exitCodeRolledBack}
 	}
 	return err
 }
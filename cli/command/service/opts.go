@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/cli/cli/command/service/progress"
+	"github.com/docker/cli/cli/envfile"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/client"
@@ -502,11 +504,33 @@ func convertExtraHostsToSwarmHosts(extraHosts []string) []string {
 	return hosts
 }
 
+// waitOptions controls whether and how a command waits for services to
+// converge.
+type waitOptions struct {
+	detach      bool
+	quiet       bool
+	progress    string
+	waitTimeout time.Duration
+}
+
+// validate checks the wait flags, whether or not the command waits, so that
+// they are rejected before any API call.
+func (opts *waitOptions) validate() error {
+	return progress.ValidateFormat(opts.progress)
+}
+
+func addWaitFlags(flags *pflag.FlagSet, opts *waitOptions) {
+	flags.BoolVarP(&opts.detach, "detach", "d", true, "Exit immediately instead of waiting for the service to converge")
+	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Suppress progress output")
+	flags.StringVar(&opts.progress, flagProgress, progress.FormatTTY, `Progress output type ("tty"|"plain"|"json")`)
+	flags.DurationVar(&opts.waitTimeout, flagWaitTimeout, 0, "Maximum time to wait for the service to converge (0 waits indefinitely)")
+}
+
 type serviceOptions struct {
-	detach bool
-	quiet  bool
+	waitOptions
 
 	name            string
+	dryRun          bool
 	labels          opts.ListOpts
 	containerLabels opts.ListOpts
 	image           string
@@ -515,6 +539,7 @@ type serviceOptions struct {
 	hostname        string
 	env             opts.ListOpts
 	envFile         opts.ListOpts
+	envFileFormat   string
 	workdir         string
 	user            string
 	groups          opts.ListOpts
@@ -597,7 +622,7 @@ func (opts *serviceOptions) ToStopGracePeriod(flags *pflag.FlagSet) *time.Durati
 func (opts *serviceOptions) ToService(ctx context.Context, apiClient client.NetworkAPIClient, flags *pflag.FlagSet) (swarm.ServiceSpec, error) {
 	var service swarm.ServiceSpec
 
-	envVariables, err := runconfigopts.ReadKVStrings(opts.envFile.GetAll(), opts.env.GetAll())
+	envVariables, err := envfile.ReadKVStrings(opts.envFile.GetAll(), opts.env.GetAll(), opts.envFileFormat)
 	if err != nil {
 		return service, err
 	}
@@ -745,8 +770,7 @@ func addServiceFlags(flags *pflag.FlagSet, opts *serviceOptions, defaultFlagValu
 		return desc
 	}
 
-	flags.BoolVarP(&opts.detach, "detach", "d", true, "Exit immediately instead of waiting for the service to converge")
-	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Suppress progress output")
+	addWaitFlags(flags, &opts.waitOptions)
 
 	flags.StringVarP(&opts.workdir, flagWorkdir, "w", "", "Working directory inside the container")
 	flags.StringVarP(&opts.user, flagUser, "u", "", "Username or UID (format: <name|uid>[:<group|gid>])")
@@ -829,6 +853,7 @@ const (
 	flagPlacementPref           = "placement-pref"
 	flagPlacementPrefAdd        = "placement-pref-add"
 	flagPlacementPrefRemove     = "placement-pref-rm"
+	flagProgress                = "progress"
 	flagConstraint              = "constraint"
 	flagConstraintRemove        = "constraint-rm"
 	flagConstraintAdd           = "constraint-add"
@@ -844,6 +869,7 @@ const (
 	flagDNSSearch               = "dns-search"
 	flagDNSSearchRemove         = "dns-search-rm"
 	flagDNSSearchAdd            = "dns-search-add"
+	flagDryRun                  = "dry-run"
 	flagEndpointMode            = "endpoint-mode"
 	flagEntrypoint              = "entrypoint"
 	flagHost                    = "host"
@@ -852,8 +878,10 @@ const (
 	flagHostname                = "hostname"
 	flagEnv                     = "env"
 	flagEnvFile                 = "env-file"
+	flagEnvFileFormat           = "env-file-format"
 	flagEnvRemove               = "env-rm"
 	flagEnvAdd                  = "env-add"
+	flagFilter                  = "filter"
 	flagGroup                   = "group"
 	flagGroupAdd                = "group-add"
 	flagGroupRemove             = "group-rm"
@@ -897,7 +925,9 @@ const (
 	flagUpdateOrder             = "update-order"
 	flagUpdateParallelism       = "update-parallelism"
 	flagUser                    = "user"
+	flagWaitTimeout             = "wait-timeout"
 	flagWorkdir                 = "workdir"
+	flagYes                     = "yes"
 	flagRegistryAuth            = "with-registry-auth"
 	flagLogDriver               = "log-driver"
 	flagLogOpt                  = "log-opt"
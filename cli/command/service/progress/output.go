@@ -0,0 +1,230 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/streamformatter"
+)
+
+// Supported progress output formats
+const (
+	FormatTTY   = "tty"
+	FormatPlain = "plain"
+	FormatJSON  = "json"
+)
+
+// Event types emitted by the plain and json progress formats
+const (
+	EventTypeTask     = "task"
+	EventTypeProgress = "progress"
+	EventTypeMessage  = "message"
+)
+
+// Event is a single convergence event written by the plain and json
+// progress formats.
+type Event struct {
+	Type    string
+	Service string
+
+	// Set for task events
+	Task         string          `json:",omitempty"`
+	Slot         int             `json:",omitempty"`
+	Node         string          `json:",omitempty"`
+	State        swarm.TaskState `json:",omitempty"`
+	DesiredState swarm.TaskState `json:",omitempty"`
+	Error        string          `json:",omitempty"`
+
+	// Set for progress events
+	Overall *OverallProgress `json:",omitempty"`
+
+	// Set for message events
+	Message string `json:",omitempty"`
+
+	Rollback bool `json:",omitempty"`
+}
+
+// OverallProgress is the number of running tasks out of the number of tasks
+// the service is converging towards.
+type OverallProgress struct {
+	Current int
+	Total   int
+}
+
+// ValidateFormat checks that format is a supported progress output format.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatTTY, FormatPlain, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid progress format %q: must be one of %q, %q or %q", format, FormatTTY, FormatPlain, FormatJSON)
+	}
+}
+
+// progressSink receives the convergence progress computed by the updaters.
+type progressSink interface {
+	progress.Output
+
+	// task is called for every tracked task on every poll.
+	task(service swarm.Service, task swarm.Task, node swarm.Node, rollback bool)
+	// overall is called whenever the overall progress is recomputed.
+	overall(service swarm.Service, numerator, denominator int, rollback bool)
+}
+
+// newProgressSink returns the sink writing progress to out in format. With
+// FormatTTY, the IDs of the progress bars start with idPrefix.
+func newProgressSink(out io.Writer, format string, idPrefix string) progressSink {
+	switch format {
+	case FormatPlain, FormatJSON:
+		return &eventSink{
+			out:      out,
+			json:     format == FormatJSON,
+			lastTask: make(map[string]Event),
+			lastMsg:  make(map[string]string),
+		}
+	default:
+		output := streamformatter.NewJSONProgressOutput(out, false)
+		if idPrefix != "" {
+			output = prefixedOutput{Output: output, prefix: idPrefix}
+		}
+		return &ttySink{Output: output}
+	}
+}
+
+// prefixedOutput prefixes the IDs of progress bars, so that the bars of
+// several services can be rendered together.
+type prefixedOutput struct {
+	progress.Output
+	prefix string
+}
+
+func (o prefixedOutput) WriteProgress(p progress.Progress) error {
+	p.ID = o.prefix + p.ID
+	return o.Output.WriteProgress(p)
+}
+
+// ttySink renders progress bars through the jsonmessage stream format.
+type ttySink struct {
+	progress.Output
+}
+
+func (s *ttySink) task(swarm.Service, swarm.Task, swarm.Node, bool) {}
+
+func (s *ttySink) overall(_ swarm.Service, numerator, denominator int, rollback bool) {
+	if rollback {
+		s.WriteProgress(progress.Progress{
+			ID:     "overall progress",
+			Action: fmt.Sprintf("rolling back update: %d out of %d tasks", numerator, denominator),
+		})
+		return
+	}
+	s.WriteProgress(progress.Progress{
+		ID:     "overall progress",
+		Action: fmt.Sprintf("%d out of %d tasks", numerator, denominator),
+	})
+}
+
+// eventSink writes one line per change, either as plain text or as
+// newline-delimited JSON.
+type eventSink struct {
+	out     io.Writer
+	json    bool
+	service string
+
+	lastTask    map[string]Event
+	lastOverall *Event
+	lastMsg     map[string]string
+}
+
+// WriteProgress implements progress.Output. Progress bars are dropped, as
+// task state changes are reported through task instead, and so are the
+// blank placeholders drawing them in order.
+func (s *eventSink) WriteProgress(p progress.Progress) error {
+	if p.Total != 0 {
+		return nil
+	}
+	msg := p.Message
+	if msg == "" {
+		msg = p.Action
+	}
+	if strings.TrimSpace(msg) == "" || s.lastMsg[p.ID] == msg {
+		return nil
+	}
+	s.lastMsg[p.ID] = msg
+	return s.write(Event{Type: EventTypeMessage, Service: s.service, Message: msg})
+}
+
+func (s *eventSink) task(service swarm.Service, task swarm.Task, node swarm.Node, rollback bool) {
+	s.service = service.Spec.Name
+	ev := Event{
+		Type:         EventTypeTask,
+		Service:      service.Spec.Name,
+		Task:         task.ID,
+		Slot:         task.Slot,
+		Node:         node.Description.Hostname,
+		State:        task.Status.State,
+		DesiredState: task.DesiredState,
+		Error:        task.Status.Err,
+		Rollback:     rollback,
+	}
+	if ev.Node == "" {
+		ev.Node = task.NodeID
+	}
+	if last, ok := s.lastTask[task.ID]; ok && last.State == ev.State && last.DesiredState == ev.DesiredState && last.Error == ev.Error {
+		return
+	}
+	s.lastTask[task.ID] = ev
+	s.write(ev)
+}
+
+func (s *eventSink) overall(service swarm.Service, numerator, denominator int, rollback bool) {
+	s.service = service.Spec.Name
+	ev := Event{
+		Type:     EventTypeProgress,
+		Service:  service.Spec.Name,
+		Overall:  &OverallProgress{Current: numerator, Total: denominator},
+		Rollback: rollback,
+	}
+	if last := s.lastOverall; last != nil && *last.Overall == *ev.Overall && last.Rollback == ev.Rollback {
+		return
+	}
+	s.lastOverall = &ev
+	s.write(ev)
+}
+
+func (s *eventSink) write(ev Event) error {
+	if s.json {
+		return json.NewEncoder(s.out).Encode(ev)
+	}
+	_, err := fmt.Fprintln(s.out, formatPlainEvent(ev))
+	return err
+}
+
+func formatPlainEvent(ev Event) string {
+	switch ev.Type {
+	case EventTypeTask:
+		name := ev.Service
+		if ev.Slot != 0 {
+			name = fmt.Sprintf("%s.%d", ev.Service, ev.Slot)
+		}
+		line := fmt.Sprintf("%s on %s: %s (desired %s)", name, ev.Node, ev.State, ev.DesiredState)
+		if ev.Error != "" {
+			line += ": " + ev.Error
+		}
+		return line
+	case EventTypeProgress:
+		if ev.Rollback {
+			return fmt.Sprintf("%s: rolling back update: %d out of %d tasks", ev.Service, ev.Overall.Current, ev.Overall.Total)
+		}
+		return fmt.Sprintf("%s: %d out of %d tasks", ev.Service, ev.Overall.Current, ev.Overall.Total)
+	default:
+		if ev.Service == "" {
+			return ev.Message
+		}
+		return fmt.Sprintf("%s: %s", ev.Service, ev.Message)
+	}
+}
@@ -0,0 +1,122 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/testutil/golden"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testService() swarm.Service {
+	return swarm.Service{Spec: swarm.ServiceSpec{Annotations: swarm.Annotations{Name: "web"}}}
+}
+
+func testTask(state swarm.TaskState) swarm.Task {
+	return swarm.Task{
+		ID:           "task1",
+		Slot:         1,
+		NodeID:       "node1",
+		DesiredState: swarm.TaskStateRunning,
+		Status:       swarm.TaskStatus{State: state},
+	}
+}
+
+func TestPlainSinkReportsStateChanges(t *testing.T) {
+	buf := new(bytes.Buffer)
+	sink := newProgressSink(buf, FormatPlain, "")
+	service := testService()
+	node := swarm.Node{Description: swarm.NodeDescription{Hostname: "host1"}}
+
+	sink.overall(service, 0, 1, false)
+	sink.task(service, testTask(swarm.TaskStatePreparing), node, false)
+	sink.task(service, testTask(swarm.TaskStatePreparing), node, false)
+	sink.task(service, testTask(swarm.TaskStateRunning), node, false)
+	sink.overall(service, 1, 1, false)
+	sink.overall(service, 1, 1, false)
+	sink.WriteProgress(progress.Progress{ID: "1/1", Action: "running", Current: 9, Total: 9})
+
+	expected := []string{
+		"web: 0 out of 1 tasks",
+		"web.1 on host1: preparing (desired running)",
+		"web.1 on host1: running (desired running)",
+		"web: 1 out of 1 tasks",
+	}
+	assert.Equal(t, strings.Join(expected, "\n")+"\n", buf.String())
+}
+
+func TestJSONSinkWritesEvents(t *testing.T) {
+	buf := new(bytes.Buffer)
+	sink := newProgressSink(buf, FormatJSON, "")
+	service := testService()
+
+	task := testTask(swarm.TaskStateFailed)
+	task.Status.Err = "exit status 1"
+	sink.task(service, task, swarm.Node{}, true)
+	sink.overall(service, 0, 1, true)
+	sink.WriteProgress(progress.Progress{ID: "rollback", Action: "update rolled back"})
+
+	var events []Event
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var ev Event
+		require.NoError(t, dec.Decode(&ev))
+		events = append(events, ev)
+	}
+	require.Len(t, events, 3)
+
+	assert.Equal(t, EventTypeTask, events[0].Type)
+	assert.Equal(t, "node1", events[0].Node)
+	assert.Equal(t, swarm.TaskStateFailed, events[0].State)
+	assert.Equal(t, "exit status 1", events[0].Error)
+	assert.True(t, events[0].Rollback)
+
+	assert.Equal(t, EventTypeProgress, events[1].Type)
+	assert.Equal(t, &OverallProgress{Current: 0, Total: 1}, events[1].Overall)
+
+	assert.Equal(t, EventTypeMessage, events[2].Type)
+	assert.Equal(t, "web", events[2].Service)
+	assert.Equal(t, "update rolled back", events[2].Message)
+}
+
+func TestReplicatedUpdaterEvents(t *testing.T) {
+	replicas := uint64(3)
+	service := testService()
+	service.Spec.Mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+	nodes := map[string]swarm.Node{"node1": {Description: swarm.NodeDescription{Hostname: "host1"}}}
+
+	for _, format := range []string{FormatPlain, FormatJSON} {
+		buf := new(bytes.Buffer)
+		updater, err := initializeUpdater(service, newProgressSink(buf, format, ""))
+		require.NoError(t, err)
+
+		// A task starts on every poll, so that the events are in order.
+		var tasks []swarm.Task
+		for slot := 1; slot <= int(replicas); slot++ {
+			task := testTask(swarm.TaskStateRunning)
+			task.ID = fmt.Sprintf("task%d", slot)
+			task.Slot = slot
+			tasks = append(tasks, task)
+			_, err := updater.update(service, tasks, nodes, false)
+			require.NoError(t, err)
+		}
+
+		assert.NotContains(t, buf.String(), EventTypeMessage)
+		assert.NotContains(t, buf.String(), "web:  ")
+		expected := golden.Get(t, buf.Bytes(), "replicated-progress."+format+".golden")
+		assert.Equal(t, string(expected), buf.String())
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range []string{FormatTTY, FormatPlain, FormatJSON} {
+		assert.NoError(t, ValidateFormat(format))
+	}
+	assert.Error(t, ValidateFormat("xml"))
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -13,7 +14,6 @@ import (
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/progress"
-	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/docker/pkg/stringid"
 	"golang.org/x/net/context"
 )
@@ -58,13 +58,63 @@ func stateToProgress(state swarm.TaskState, rollback bool) int64 {
 	return int64(len(numberedStates)) - numberedStates[state]
 }
 
+// RolledBackError is returned by ServiceProgress when the service was rolled
+// back instead of converging.
+type RolledBackError struct {
+	Message string
+}
+
+func (e RolledBackError) Error() string {
+	return fmt.Sprintf("service rolled back: %s", e.Message)
+}
+
 // ServiceProgress outputs progress information for convergence of a service.
-// nolint: gocyclo
-func ServiceProgress(ctx context.Context, client client.APIClient, serviceID string, progressWriter io.WriteCloser) error {
+// The format is one of FormatTTY, FormatPlain or FormatJSON. With FormatTTY,
+// progressWriter receives a jsonmessage stream to be rendered by the caller.
+func ServiceProgress(ctx context.Context, client client.APIClient, serviceID string, progressWriter io.WriteCloser, format string) error {
+	return ServicesProgress(ctx, client, []string{serviceID}, progressWriter, format)[0]
+}
+
+// ServicesProgress outputs progress information for convergence of several
+// services at once, combined in progressWriter. With FormatTTY, the IDs of the
+// progress bars of each service start with the service. It returns the result
+// of each service, in the order of serviceIDs.
+func ServicesProgress(ctx context.Context, client client.APIClient, serviceIDs []string, progressWriter io.WriteCloser, format string) []error {
 	defer progressWriter.Close()
 
-	progressOut := streamformatter.NewJSONProgressOutput(progressWriter, false)
+	out := &syncWriter{w: progressWriter}
+	errs := make([]error, len(serviceIDs))
+	var wg sync.WaitGroup
+	for i, serviceID := range serviceIDs {
+		var idPrefix string
+		if len(serviceIDs) > 1 {
+			idPrefix = serviceID + ": "
+		}
+		wg.Add(1)
+		go func(i int, serviceID string, progressOut progressSink) {
+			defer wg.Done()
+			errs[i] = serviceProgress(ctx, client, serviceID, progressOut)
+		}(i, serviceID, newProgressSink(out, format, idPrefix))
+	}
+	wg.Wait()
+	return errs
+}
+
+// syncWriter serializes the writes of the progress of several services, each
+// of which is a whole message.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
 
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// nolint: gocyclo
+func serviceProgress(ctx context.Context, client client.APIClient, serviceID string, progressOut progressSink) error {
 	sigint := make(chan os.Signal, 1)
 	signal.Notify(sigint, os.Interrupt)
 	defer signal.Stop(sigint)
@@ -124,7 +174,7 @@ func ServiceProgress(ctx context.Context, client client.APIClient, serviceID str
 				return fmt.Errorf("service rollback paused: %s", service.UpdateStatus.Message)
 			case swarm.UpdateStateRollbackCompleted:
 				if !converged {
-					return fmt.Errorf("service rolled back: %s", service.UpdateStatus.Message)
+					return RolledBackError{Message: service.UpdateStatus.Message}
 				}
 			}
 		}
@@ -177,6 +227,8 @@ func ServiceProgress(ctx context.Context, client client.APIClient, serviceID str
 
 		select {
 		case <-time.After(200 * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
 		case <-sigint:
 			if !converged {
 				progress.Message(progressOut, "", "Operation continuing in background.")
@@ -202,7 +254,7 @@ func getActiveNodes(ctx context.Context, client client.APIClient) (map[string]sw
 	return activeNodes, nil
 }
 
-func initializeUpdater(service swarm.Service, progressOut progress.Output) (progressUpdater, error) {
+func initializeUpdater(service swarm.Service, progressOut progressSink) (progressUpdater, error) {
 	if service.Spec.Mode.Replicated != nil && service.Spec.Mode.Replicated.Replicas != nil {
 		return &replicatedProgressUpdater{
 			progressOut: progressOut,
@@ -216,22 +268,8 @@ func initializeUpdater(service swarm.Service, progressOut progress.Output) (prog
 	return nil, errors.New("unrecognized service mode")
 }
 
-func writeOverallProgress(progressOut progress.Output, numerator, denominator int, rollback bool) {
-	if rollback {
-		progressOut.WriteProgress(progress.Progress{
-			ID:     "overall progress",
-			Action: fmt.Sprintf("rolling back update: %d out of %d tasks", numerator, denominator),
-		})
-		return
-	}
-	progressOut.WriteProgress(progress.Progress{
-		ID:     "overall progress",
-		Action: fmt.Sprintf("%d out of %d tasks", numerator, denominator),
-	})
-}
-
 type replicatedProgressUpdater struct {
-	progressOut progress.Output
+	progressOut progressSink
 
 	// used for maping slots to a contiguous space
 	// this also causes progress bars to appear in order
@@ -252,7 +290,7 @@ func (u *replicatedProgressUpdater) update(service swarm.Service, tasks []swarm.
 		u.slotMap = make(map[int]int)
 
 		// Draw progress bars in order
-		writeOverallProgress(u.progressOut, 0, int(replicas), rollback)
+		u.progressOut.overall(service, 0, int(replicas), rollback)
 
 		if replicas <= maxProgressBars {
 			for i := uint64(1); i <= replicas; i++ {
@@ -299,6 +337,8 @@ func (u *replicatedProgressUpdater) update(service swarm.Service, tasks []swarm.
 			u.slotMap[task.Slot] = mappedSlot
 		}
 
+		u.progressOut.task(service, task, activeNodes[task.NodeID], rollback)
+
 		if !u.done && replicas <= maxProgressBars && uint64(mappedSlot) <= replicas {
 			u.progressOut.WriteProgress(progress.Progress{
 				ID:         fmt.Sprintf("%d/%d", mappedSlot, replicas),
@@ -314,7 +354,7 @@ func (u *replicatedProgressUpdater) update(service swarm.Service, tasks []swarm.
 	}
 
 	if !u.done {
-		writeOverallProgress(u.progressOut, int(running), int(replicas), rollback)
+		u.progressOut.overall(service, int(running), int(replicas), rollback)
 
 		if running == replicas {
 			u.done = true
@@ -325,7 +365,7 @@ func (u *replicatedProgressUpdater) update(service swarm.Service, tasks []swarm.
 }
 
 type globalProgressUpdater struct {
-	progressOut progress.Output
+	progressOut progressSink
 
 	initialized bool
 	done        bool
@@ -366,7 +406,7 @@ func (u *globalProgressUpdater) update(service swarm.Service, tasks []swarm.Task
 			return false, nil
 		}
 
-		writeOverallProgress(u.progressOut, 0, nodeCount, rollback)
+		u.progressOut.overall(service, 0, nodeCount, rollback)
 		u.initialized = true
 	}
 
@@ -384,6 +424,8 @@ func (u *globalProgressUpdater) update(service swarm.Service, tasks []swarm.Task
 
 	for _, task := range tasksByNode {
 		if node, nodeActive := activeNodes[task.NodeID]; nodeActive {
+			u.progressOut.task(service, task, node, rollback)
+
 			if !u.done && nodeCount <= maxProgressBars {
 				u.progressOut.WriteProgress(progress.Progress{
 					ID:         stringid.TruncateID(node.ID),
@@ -400,7 +442,7 @@ func (u *globalProgressUpdater) update(service swarm.Service, tasks []swarm.Task
 	}
 
 	if !u.done {
-		writeOverallProgress(u.progressOut, running, nodeCount, rollback)
+		u.progressOut.overall(service, running, nodeCount, rollback)
 
 		if running == nodeCount {
 			u.done = true
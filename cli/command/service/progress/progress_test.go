@@ -0,0 +1,88 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+type fakeClient struct {
+	client.APIClient
+	services map[string]swarm.Service
+}
+
+func (c *fakeClient) ServiceInspectWithRaw(_ context.Context, serviceID string, _ types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+	return c.services[serviceID], nil, nil
+}
+
+func (c *fakeClient) TaskList(_ context.Context, options types.TaskListOptions) ([]swarm.Task, error) {
+	serviceID := options.Filters.Get("service")[0]
+	return []swarm.Task{{
+		ID:           serviceID + "-task",
+		ServiceID:    serviceID,
+		Slot:         1,
+		NodeID:       "node1",
+		DesiredState: swarm.TaskStateRunning,
+		Status:       swarm.TaskStatus{State: swarm.TaskStateRunning},
+	}}, nil
+}
+
+func (c *fakeClient) NodeList(context.Context, types.NodeListOptions) ([]swarm.Node, error) {
+	return []swarm.Node{{ID: "node1", Description: swarm.NodeDescription{Hostname: "host1"}}}, nil
+}
+
+func newFakeClient(names ...string) *fakeClient {
+	replicas := uint64(1)
+	c := &fakeClient{services: make(map[string]swarm.Service)}
+	for _, name := range names {
+		c.services[name] = swarm.Service{
+			ID: name,
+			Spec: swarm.ServiceSpec{
+				Annotations:  swarm.Annotations{Name: name},
+				Mode:         swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+				UpdateConfig: &swarm.UpdateConfig{Monitor: time.Millisecond},
+			},
+		}
+	}
+	return c
+}
+
+type nopWriteCloser struct {
+	*bytes.Buffer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestServicesProgressCombined(t *testing.T) {
+	buf := nopWriteCloser{new(bytes.Buffer)}
+	errs := ServicesProgress(context.Background(), newFakeClient("api", "worker"), []string{"api", "worker"}, buf, FormatPlain)
+	assert.Equal(t, []error{nil, nil}, errs)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Contains(t, lines, "api: 1 out of 1 tasks")
+	assert.Contains(t, lines, "api.1 on host1: running (desired running)")
+	assert.Contains(t, lines, "worker: 1 out of 1 tasks")
+	assert.Contains(t, lines, "worker.1 on host1: running (desired running)")
+}
+
+func TestServicesProgressTTYPrefixesIDs(t *testing.T) {
+	buf := nopWriteCloser{new(bytes.Buffer)}
+	errs := ServicesProgress(context.Background(), newFakeClient("api", "worker"), []string{"api", "worker"}, buf, FormatTTY)
+	assert.Equal(t, []error{nil, nil}, errs)
+	assert.Contains(t, buf.String(), `"id":"api: overall progress"`)
+	assert.Contains(t, buf.String(), `"id":"worker: overall progress"`)
+
+	// The progress bars of a single service are not prefixed.
+	buf.Reset()
+	assert.NoError(t, ServiceProgress(context.Background(), newFakeClient("api"), "api", buf, FormatTTY))
+	assert.Contains(t, buf.String(), `"id":"overall progress"`)
+}
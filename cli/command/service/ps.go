@@ -13,6 +13,7 @@ import (
 	"github.com/docker/cli/cli/command/task"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/opts"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -25,6 +26,7 @@ type psOptions struct {
 	noTrunc   bool
 	format    string
 	filter    opts.FilterOpt
+	watch     bool
 }
 
 func newPsCommand(dockerCli command.Cli) *cobra.Command {
@@ -45,6 +47,7 @@ func newPsCommand(dockerCli command.Cli) *cobra.Command {
 	flags.BoolVar(&opts.noResolve, "no-resolve", false, "Do not map IDs to Names")
 	flags.StringVar(&opts.format, "format", "", "Pretty-print tasks using a Go template")
 	flags.VarP(&opts.filter, "filter", "f", "Filter output based on conditions provided")
+	flags.BoolVar(&opts.watch, "watch", false, "Watch the tasks and redraw them as they change")
 
 	return cmd
 }
@@ -105,11 +108,6 @@ func runPS(dockerCli command.Cli, opts psOptions) error {
 		}
 	}
 
-	tasks, err := client.TaskList(ctx, types.TaskListOptions{Filters: filter})
-	if err != nil {
-		return err
-	}
-
 	format := opts.format
 	if len(format) == 0 {
 		if len(dockerCli.ConfigFile().TasksFormat) > 0 && !opts.quiet {
@@ -119,5 +117,22 @@ func runPS(dockerCli command.Cli, opts psOptions) error {
 		}
 	}
 
+	if opts.watch {
+		eventFilter := filters.NewArgs()
+		eventFilter.Add("type", "service")
+		for _, serviceID := range filter.Get("service") {
+			eventFilter.Add("service", serviceID)
+		}
+		listTasks := func(ctx context.Context) ([]swarm.Task, error) {
+			return client.TaskList(ctx, types.TaskListOptions{Filters: filter})
+		}
+		return task.Watch(ctx, dockerCli, listTasks, eventFilter, idresolver.New(client, opts.noResolve), !opts.noTrunc, opts.quiet, format)
+	}
+
+	tasks, err := client.TaskList(ctx, types.TaskListOptions{Filters: filter})
+	if err != nil {
+		return err
+	}
+
 	return task.Print(ctx, dockerCli, tasks, idresolver.New(client, opts.noResolve), !opts.noTrunc, opts.quiet, format)
 }
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
@@ -14,15 +15,30 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func newScaleCommand(dockerCli *command.DockerCli) *cobra.Command {
-	return &cobra.Command{
+type scaleOptions struct {
+	waitOptions
+	atomic bool
+}
+
+func newScaleCommand(dockerCli command.Cli) *cobra.Command {
+	opts := scaleOptions{}
+
+	cmd := &cobra.Command{
 		Use:   "scale SERVICE=REPLICAS [SERVICE=REPLICAS...]",
 		Short: "Scale one or multiple replicated services",
-		Args:  scaleArgs,
+		Long: "Scale one or multiple replicated services.\n\n" +
+			"REPLICAS is either an absolute number of replicas, or a number prefixed\n" +
+			"with '+' or '-' to scale relative to the current number of replicas.",
+		Args: scaleArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runScale(dockerCli, args)
+			return runScale(dockerCli, opts, args)
 		},
 	}
+
+	flags := cmd.Flags()
+	addWaitFlags(flags, &opts.waitOptions)
+	flags.BoolVar(&opts.atomic, "atomic", false, "Revert services that were already scaled if scaling a later service fails")
+	return cmd
 }
 
 func scaleArgs(cmd *cobra.Command, args []string) error {
@@ -43,49 +59,95 @@ func scaleArgs(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runScale(dockerCli *command.DockerCli, args []string) error {
-	var errs []string
+// scaledService records the replica count a service had before it was
+// scaled, so that it can be reverted.
+type scaledService struct {
+	serviceID string
+	previous  uint64
+}
+
+func runScale(dockerCli command.Cli, opts scaleOptions, args []string) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var (
+		errs   []string
+		scaled []scaledService
+	)
 	for _, arg := range args {
 		parts := strings.SplitN(arg, "=", 2)
 		serviceID, scaleStr := parts[0], parts[1]
 
-		// validate input arg scale number
-		scale, err := strconv.ParseUint(scaleStr, 10, 64)
+		previous, err := runServiceScale(ctx, dockerCli, serviceID, scaleStr)
 		if err != nil {
-			errs = append(errs, fmt.Sprintf("%s: invalid replicas value %s: %v", serviceID, scaleStr, err))
+			errs = append(errs, fmt.Sprintf("%s: %v", serviceID, err))
+			if opts.atomic {
+				errs = append(errs, revertScale(ctx, dockerCli, scaled)...)
+				scaled = nil
+				break
+			}
 			continue
 		}
+		scaled = append(scaled, scaledService{serviceID: serviceID, previous: previous})
+	}
 
-		if err := runServiceScale(dockerCli, serviceID, scale); err != nil {
-			errs = append(errs, fmt.Sprintf("%s: %v", serviceID, err))
+	if !opts.detach && len(scaled) > 0 {
+		serviceIDs := make([]string, len(scaled))
+		for i, s := range scaled {
+			serviceIDs[i] = s.serviceID
+		}
+		var waitErrs []error
+		for i, err := range waitOnServices(ctx, dockerCli, serviceIDs, &opts.waitOptions) {
+			if err != nil {
+				waitErrs = append(waitErrs, err)
+				errs = append(errs, fmt.Sprintf("%s: %v", serviceIDs[i], err))
+			}
+		}
+		// A single service failing to converge keeps its exit status.
+		if len(waitErrs) == 1 && len(errs) == 1 {
+			return waitErrs[0]
 		}
 	}
 
 	if len(errs) == 0 {
 		return nil
 	}
-	return errors.Errorf(strings.Join(errs, "\n"))
+	return errors.New(strings.Join(errs, "\n"))
 }
 
-func runServiceScale(dockerCli *command.DockerCli, serviceID string, scale uint64) error {
+// runServiceScale scales a replicated service and returns the number of
+// replicas the service had before.
+func runServiceScale(ctx context.Context, dockerCli command.Cli, serviceID string, scaleStr string) (uint64, error) {
 	client := dockerCli.Client()
-	ctx := context.Background()
 
 	service, _, err := client.ServiceInspectWithRaw(ctx, serviceID, types.ServiceInspectOptions{})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	serviceMode := &service.Spec.Mode
 	if serviceMode.Replicated == nil {
-		return errors.Errorf("scale can only be used with replicated mode")
+		return 0, errors.Errorf("scale can only be used with replicated mode")
+	}
+
+	var previous uint64
+	if serviceMode.Replicated.Replicas != nil {
+		previous = *serviceMode.Replicated.Replicas
+	}
+
+	// validate input arg scale number
+	scale, err := parseReplicas(previous, scaleStr)
+	if err != nil {
+		return 0, err
 	}
 
 	serviceMode.Replicated.Replicas = &scale
 
 	response, err := client.ServiceUpdate(ctx, service.ID, service.Version, service.Spec, types.ServiceUpdateOptions{})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	for _, warning := range response.Warnings {
@@ -93,5 +155,62 @@ func runServiceScale(dockerCli *command.DockerCli, serviceID string, scale uint6
 	}
 
 	fmt.Fprintf(dockerCli.Out(), "%s scaled to %d\n", serviceID, scale)
-	return nil
+	return previous, nil
+}
+
+// revertScale restores the previous replica count of services, most recently
+// scaled first. It returns the errors of services that could not be reverted.
+func revertScale(ctx context.Context, dockerCli command.Cli, scaled []scaledService) []string {
+	client := dockerCli.Client()
+
+	var errs []string
+	for i := len(scaled) - 1; i >= 0; i-- {
+		s := scaled[i]
+		err := func() error {
+			service, _, err := client.ServiceInspectWithRaw(ctx, s.serviceID, types.ServiceInspectOptions{})
+			if err != nil {
+				return err
+			}
+			if service.Spec.Mode.Replicated == nil {
+				return errors.Errorf("scale can only be used with replicated mode")
+			}
+			previous := s.previous
+			service.Spec.Mode.Replicated.Replicas = &previous
+			_, err = client.ServiceUpdate(ctx, service.ID, service.Version, service.Spec, types.ServiceUpdateOptions{})
+			return err
+		}()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: failed to revert to %d replicas: %v", s.serviceID, s.previous, err))
+			continue
+		}
+		fmt.Fprintf(dockerCli.Out(), "%s reverted to %d\n", s.serviceID, s.previous)
+	}
+	return errs
+}
+
+// parseReplicas parses an absolute ("5") or relative ("+2", "-1") number of
+// replicas, relative to current.
+func parseReplicas(current uint64, value string) (uint64, error) {
+	if !strings.HasPrefix(value, "+") && !strings.HasPrefix(value, "-") {
+		scale, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid replicas value %s: %v", value, err)
+		}
+		return scale, nil
+	}
+
+	delta, err := strconv.ParseUint(value[1:], 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid replicas value %s: %v", value, err)
+	}
+	if value[0] == '+' {
+		if delta > math.MaxUint64-current {
+			return 0, errors.Errorf("cannot scale up by %d: service has %d replicas", delta, current)
+		}
+		return current + delta, nil
+	}
+	if delta > current {
+		return 0, errors.Errorf("cannot scale down by %d: service has %d replicas", delta, current)
+	}
+	return current - delta, nil
 }
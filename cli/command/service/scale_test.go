@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/docker/cli/cli/internal/test"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReplicas(t *testing.T) {
+	testCases := []struct {
+		current  uint64
+		value    string
+		expected uint64
+	}{
+		{current: 3, value: "5", expected: 5},
+		{current: 3, value: "0", expected: 0},
+		{current: 3, value: "+2", expected: 5},
+		{current: 3, value: "-1", expected: 2},
+		{current: 3, value: "-3", expected: 0},
+		{current: 1, value: "+18446744073709551614", expected: math.MaxUint64},
+	}
+	for _, tc := range testCases {
+		scale, err := parseReplicas(tc.current, tc.value)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, scale, tc.value)
+	}
+}
+
+func TestParseReplicasErrors(t *testing.T) {
+	testCases := []struct {
+		current       uint64
+		value         string
+		expectedError string
+	}{
+		{current: 3, value: "foo", expectedError: "invalid replicas value foo"},
+		{current: 3, value: "+", expectedError: "invalid replicas value +"},
+		{current: 3, value: "+-1", expectedError: "invalid replicas value +-1"},
+		{current: 1, value: "-2", expectedError: "cannot scale down by 2: service has 1 replicas"},
+		{current: 2, value: "+18446744073709551614", expectedError: "cannot scale up by 18446744073709551614: service has 2 replicas"},
+	}
+	for _, tc := range testCases {
+		_, err := parseReplicas(tc.current, tc.value)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), tc.expectedError)
+		}
+	}
+}
+
+// scaleClient is a fake client of replicated services, of which the updates
+// of the services in failing return an error.
+func scaleClient(replicas map[string]uint64, failing ...string) *fakeClient {
+	return &fakeClient{
+		serviceInspectWithRawFunc: func(serviceID string) (swarm.Service, []byte, error) {
+			count, ok := replicas[serviceID]
+			if !ok {
+				return swarm.Service{}, nil, errors.Errorf("service %s not found", serviceID)
+			}
+			return swarm.Service{
+				ID:   serviceID,
+				Spec: swarm.ServiceSpec{Mode: swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &count}}},
+			}, nil, nil
+		},
+		serviceUpdateFunc: func(serviceID string, version swarm.Version, service swarm.ServiceSpec, options types.ServiceUpdateOptions) (types.ServiceUpdateResponse, error) {
+			for _, f := range failing {
+				if f == serviceID {
+					return types.ServiceUpdateResponse{}, errors.New("rpc error: update out of sequence")
+				}
+			}
+			replicas[serviceID] = *service.Mode.Replicated.Replicas
+			return types.ServiceUpdateResponse{}, nil
+		},
+	}
+}
+
+func TestScaleAtomicRevert(t *testing.T) {
+	replicas := map[string]uint64{"api": 3, "worker": 2, "cache": 1}
+	buf := new(bytes.Buffer)
+	cmd := newScaleCommand(test.NewFakeCli(scaleClient(replicas, "worker"), buf))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--atomic", "api=5", "worker=+2", "cache=2"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Equal(t, "worker: rpc error: update out of sequence", err.Error())
+
+	assert.Equal(t, map[string]uint64{"api": 3, "worker": 2, "cache": 1}, replicas)
+	assert.Equal(t, "api scaled to 5\napi reverted to 3\n", buf.String())
+}
+
+func TestScaleAtomicRevertFailure(t *testing.T) {
+	replicas := map[string]uint64{"api": 3, "worker": 2}
+	client := scaleClient(replicas, "worker")
+	scaleUpdate := client.serviceUpdateFunc
+	updates := 0
+	client.serviceUpdateFunc = func(serviceID string, version swarm.Version, service swarm.ServiceSpec, options types.ServiceUpdateOptions) (types.ServiceUpdateResponse, error) {
+		if updates++; serviceID == "api" && updates > 1 {
+			return types.ServiceUpdateResponse{}, errors.New("connection refused")
+		}
+		return scaleUpdate(serviceID, version, service, options)
+	}
+	cmd := newScaleCommand(test.NewFakeCli(client, new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--atomic", "api=5", "worker=4"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Equal(t, "worker: rpc error: update out of sequence\napi: failed to revert to 3 replicas: connection refused", err.Error())
+	assert.Equal(t, uint64(5), replicas["api"])
+}
+
+func TestScaleWithoutAtomic(t *testing.T) {
+	replicas := map[string]uint64{"api": 3, "worker": 2, "cache": 1}
+	cmd := newScaleCommand(test.NewFakeCli(scaleClient(replicas, "worker"), new(bytes.Buffer)))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"api=5", "worker=+2", "cache=2"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Equal(t, "worker: rpc error: update out of sequence", err.Error())
+	assert.Equal(t, map[string]uint64{"api": 5, "worker": 2, "cache": 2}, replicas)
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+const (
+	reasonConstraints  = "scheduling constraints not satisfied"
+	reasonResources    = "insufficient resources"
+	nodeLabelPrefix    = "node.labels."
+	engineLabelPrefix  = "engine.labels."
+	constraintEqual    = "=="
+	constraintNotEqual = "!="
+)
+
+// placementConstraint is a parsed placement constraint, such as
+// "node.role==manager".
+type placementConstraint struct {
+	key   string
+	equal bool
+	value string
+}
+
+func parsePlacementConstraints(constraints []string) ([]placementConstraint, error) {
+	parsed := make([]placementConstraint, 0, len(constraints))
+	for _, c := range constraints {
+		operator := constraintEqual
+		idx := strings.Index(c, constraintEqual)
+		if ne := strings.Index(c, constraintNotEqual); ne >= 0 && (idx < 0 || ne < idx) {
+			operator, idx = constraintNotEqual, ne
+		}
+		if idx < 0 {
+			return nil, errors.Errorf("invalid constraint %q: expected key==value or key!=value", c)
+		}
+		pc := placementConstraint{
+			key:   strings.TrimSpace(c[:idx]),
+			equal: operator == constraintEqual,
+			value: strings.TrimSpace(c[idx+len(operator):]),
+		}
+		if _, _, err := nodeAttribute(swarm.Node{}, pc.key); err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, pc)
+	}
+	return parsed, nil
+}
+
+// nodeAttribute returns the value of a constraint or placement preference
+// key for a node, and whether the node has a value for it at all.
+func nodeAttribute(node swarm.Node, key string) (string, bool, error) {
+	lowerKey := strings.ToLower(key)
+	switch {
+	case lowerKey == "node.id":
+		return node.ID, true, nil
+	case lowerKey == "node.hostname":
+		return node.Description.Hostname, true, nil
+	case lowerKey == "node.role":
+		return string(node.Spec.Role), true, nil
+	case lowerKey == "node.platform.os":
+		return node.Description.Platform.OS, true, nil
+	case lowerKey == "node.platform.arch":
+		return node.Description.Platform.Architecture, true, nil
+	case strings.HasPrefix(lowerKey, nodeLabelPrefix) && len(key) > len(nodeLabelPrefix):
+		value, ok := node.Spec.Labels[key[len(nodeLabelPrefix):]]
+		return value, ok, nil
+	case strings.HasPrefix(lowerKey, engineLabelPrefix) && len(key) > len(engineLabelPrefix):
+		value, ok := node.Description.Engine.Labels[key[len(engineLabelPrefix):]]
+		return value, ok, nil
+	}
+	return "", false, errors.Errorf("invalid constraint key %q", key)
+}
+
+func (c placementConstraint) match(node swarm.Node) bool {
+	value, ok, _ := nodeAttribute(node, c.key)
+	if !ok {
+		return !c.equal
+	}
+	return strings.EqualFold(value, c.value) == c.equal
+}
+
+// simulatedNode tracks a node while simulating the scheduling of a service.
+type simulatedNode struct {
+	node swarm.Node
+
+	// reason is set if the node can never receive tasks of the service
+	reason string
+
+	availableCPU    int64
+	availableMemory int64
+	totalTasks      int
+	serviceTasks    int
+	spreadGroups    []string
+}
+
+func (n *simulatedNode) name() string {
+	if n.node.Description.Hostname != "" {
+		return n.node.Description.Hostname
+	}
+	return n.node.ID
+}
+
+func (n *simulatedNode) fits(reservation swarm.Resources) bool {
+	return n.availableCPU >= reservation.NanoCPUs && n.availableMemory >= reservation.MemoryBytes
+}
+
+// pendingTask is a task that could not be placed on any node.
+type pendingTask struct {
+	name   string
+	reason string
+}
+
+// schedulingSimulation is the expected outcome of scheduling a service.
+type schedulingSimulation struct {
+	nodes   []*simulatedNode
+	pending []pendingTask
+}
+
+// simulateScheduling estimates where the scheduler would place the tasks of
+// a new service, given the nodes of the swarm and the tasks already running
+// on them. It approximates the swarm scheduler: nodes must be ready, active,
+// satisfy the placement constraints and have enough unreserved resources;
+// tasks are spread over the groups of each placement preference in turn,
+// then over the nodes with the fewest tasks of the service, then with the
+// fewest tasks overall.
+// nolint: gocyclo
+func simulateScheduling(spec swarm.ServiceSpec, nodes []swarm.Node, tasks []swarm.Task) (*schedulingSimulation, error) {
+	var (
+		constraints []placementConstraint
+		preferences []string
+		reservation swarm.Resources
+		err         error
+	)
+	if placement := spec.TaskTemplate.Placement; placement != nil {
+		constraints, err = parsePlacementConstraints(placement.Constraints)
+		if err != nil {
+			return nil, err
+		}
+		for _, pref := range placement.Preferences {
+			if pref.Spread == nil {
+				continue
+			}
+			key := pref.Spread.SpreadDescriptor
+			if _, _, err := nodeAttribute(swarm.Node{}, key); err != nil {
+				return nil, err
+			}
+			preferences = append(preferences, key)
+		}
+	}
+	if res := spec.TaskTemplate.Resources; res != nil && res.Reservations != nil {
+		reservation = *res.Reservations
+	}
+
+	sim := &schedulingSimulation{}
+	byID := make(map[string]*simulatedNode)
+	for _, node := range nodes {
+		n := &simulatedNode{
+			node:            node,
+			availableCPU:    node.Description.Resources.NanoCPUs,
+			availableMemory: node.Description.Resources.MemoryBytes,
+		}
+		switch {
+		case node.Status.State != swarm.NodeStateReady:
+			n.reason = fmt.Sprintf("node is %s", node.Status.State)
+		case node.Spec.Availability != swarm.NodeAvailabilityActive:
+			n.reason = fmt.Sprintf("node availability is %s", node.Spec.Availability)
+		}
+		for _, c := range constraints {
+			if n.reason == "" && !c.match(node) {
+				n.reason = reasonConstraints
+			}
+		}
+		group := ""
+		for _, key := range preferences {
+			value, _, _ := nodeAttribute(node, key)
+			group += "/" + key + "=" + value
+			n.spreadGroups = append(n.spreadGroups, group)
+		}
+		sim.nodes = append(sim.nodes, n)
+		byID[node.ID] = n
+	}
+	sort.Slice(sim.nodes, func(i, j int) bool {
+		return sim.nodes[i].name() < sim.nodes[j].name()
+	})
+
+	for _, task := range tasks {
+		n, ok := byID[task.NodeID]
+		if !ok || task.DesiredState != swarm.TaskStateRunning {
+			continue
+		}
+		n.totalTasks++
+		if res := task.Spec.Resources; res != nil && res.Reservations != nil {
+			n.availableCPU -= res.Reservations.NanoCPUs
+			n.availableMemory -= res.Reservations.MemoryBytes
+		}
+	}
+
+	name := spec.Name
+	if name == "" {
+		name = "service"
+	}
+
+	place := func(n *simulatedNode) {
+		n.availableCPU -= reservation.NanoCPUs
+		n.availableMemory -= reservation.MemoryBytes
+		n.totalTasks++
+		n.serviceTasks++
+	}
+
+	if spec.Mode.Global != nil {
+		for _, n := range sim.nodes {
+			if n.reason != "" {
+				continue
+			}
+			if !n.fits(reservation) {
+				sim.pending = append(sim.pending, pendingTask{
+					name:   fmt.Sprintf("%s.%s", name, n.name()),
+					reason: reasonResources + " on node " + n.name(),
+				})
+				continue
+			}
+			place(n)
+		}
+		return sim, nil
+	}
+
+	replicas := uint64(1)
+	if spec.Mode.Replicated != nil && spec.Mode.Replicated.Replicas != nil {
+		replicas = *spec.Mode.Replicated.Replicas
+	}
+
+	groupTasks := make(map[string]int)
+	less := func(a, b *simulatedNode) bool {
+		for i := range a.spreadGroups {
+			if ca, cb := groupTasks[a.spreadGroups[i]], groupTasks[b.spreadGroups[i]]; ca != cb {
+				return ca < cb
+			}
+		}
+		if a.serviceTasks != b.serviceTasks {
+			return a.serviceTasks < b.serviceTasks
+		}
+		return a.totalTasks < b.totalTasks
+	}
+
+	for slot := uint64(1); slot <= replicas; slot++ {
+		var best *simulatedNode
+		for _, n := range sim.nodes {
+			if n.reason != "" || !n.fits(reservation) {
+				continue
+			}
+			if best == nil || less(n, best) {
+				best = n
+			}
+		}
+		if best == nil {
+			sim.pending = append(sim.pending, pendingTask{
+				name:   fmt.Sprintf("%s.%d", name, slot),
+				reason: noSuitableNodeReason(sim.nodes, reservation),
+			})
+			continue
+		}
+		place(best)
+		for _, group := range best.spreadGroups {
+			groupTasks[group]++
+		}
+	}
+	return sim, nil
+}
+
+// noSuitableNodeReason summarizes why none of the nodes can receive a task,
+// in the same form as the swarm scheduler.
+func noSuitableNodeReason(nodes []*simulatedNode, reservation swarm.Resources) string {
+	if len(nodes) == 0 {
+		return "no nodes available"
+	}
+
+	counts := make(map[string]int)
+	var reasons []string
+	for _, n := range nodes {
+		reason := n.reason
+		if reason == "" {
+			reason = reasonResources
+		}
+		if counts[reason] == 0 {
+			reasons = append(reasons, reason)
+		}
+		counts[reason]++
+	}
+
+	explanations := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		unit := "nodes"
+		if counts[reason] == 1 {
+			unit = "node"
+		}
+		explanations = append(explanations, fmt.Sprintf("%s on %d %s", reason, counts[reason], unit))
+	}
+	return fmt.Sprintf("no suitable node (%s)", strings.Join(explanations, "; "))
+}
+
+// runDryRun prints where the tasks of service would be scheduled, without
+// creating it.
+func runDryRun(ctx context.Context, dockerCli command.Cli, service swarm.ServiceSpec) error {
+	client := dockerCli.Client()
+
+	nodes, err := client.NodeList(ctx, types.NodeListOptions{})
+	if err != nil {
+		return err
+	}
+
+	taskFilter := filters.NewArgs()
+	taskFilter.Add("desired-state", string(swarm.TaskStateRunning))
+	tasks, err := client.TaskList(ctx, types.TaskListOptions{Filters: taskFilter})
+	if err != nil {
+		return err
+	}
+
+	sim, err := simulateScheduling(service, nodes, tasks)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(dockerCli.Out(), 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tTASKS\tREASON")
+	for _, n := range sim.nodes {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", n.name(), n.serviceTasks, n.reason)
+	}
+	w.Flush()
+
+	if len(sim.pending) > 0 {
+		fmt.Fprintf(dockerCli.Out(), "\n%d task(s) would remain pending:\n", len(sim.pending))
+		for _, task := range sim.pending {
+			fmt.Fprintf(dockerCli.Out(), "%s: %s\n", task.name, task.reason)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func simNode(id string, labels map[string]string, cpus, memory int64) swarm.Node {
+	return swarm.Node{
+		ID: id,
+		Spec: swarm.NodeSpec{
+			Annotations:  swarm.Annotations{Labels: labels},
+			Role:         swarm.NodeRoleWorker,
+			Availability: swarm.NodeAvailabilityActive,
+		},
+		Description: swarm.NodeDescription{
+			Hostname:  id,
+			Resources: swarm.Resources{NanoCPUs: cpus, MemoryBytes: memory},
+		},
+		Status: swarm.NodeStatus{State: swarm.NodeStateReady},
+	}
+}
+
+func simSpec(replicas uint64, placement *swarm.Placement, reservation *swarm.Resources) swarm.ServiceSpec {
+	return swarm.ServiceSpec{
+		Annotations: swarm.Annotations{Name: "web"},
+		TaskTemplate: swarm.TaskSpec{
+			Placement: placement,
+			Resources: &swarm.ResourceRequirements{Reservations: reservation},
+		},
+		Mode: swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+	}
+}
+
+func serviceTasksByNode(sim *schedulingSimulation) map[string]int {
+	tasks := make(map[string]int)
+	for _, n := range sim.nodes {
+		tasks[n.name()] = n.serviceTasks
+	}
+	return tasks
+}
+
+func TestSimulateSchedulingSpreadsEvenly(t *testing.T) {
+	nodes := []swarm.Node{
+		simNode("node1", nil, 4e9, 4e9),
+		simNode("node2", nil, 4e9, 4e9),
+		simNode("node3", nil, 4e9, 4e9),
+	}
+	tasks := []swarm.Task{
+		{NodeID: "node1", DesiredState: swarm.TaskStateRunning},
+		{NodeID: "node1", DesiredState: swarm.TaskStateShutdown},
+	}
+
+	sim, err := simulateScheduling(simSpec(4, nil, nil), nodes, tasks)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"node1": 1, "node2": 2, "node3": 1}, serviceTasksByNode(sim))
+	assert.Len(t, sim.pending, 0)
+}
+
+func TestSimulateSchedulingConstraintsAndAvailability(t *testing.T) {
+	drained := simNode("node3", map[string]string{"type": "web"}, 4e9, 4e9)
+	drained.Spec.Availability = swarm.NodeAvailabilityDrain
+	down := simNode("node4", map[string]string{"type": "web"}, 4e9, 4e9)
+	down.Status.State = swarm.NodeStateDown
+	nodes := []swarm.Node{
+		simNode("node1", map[string]string{"type": "web"}, 4e9, 4e9),
+		simNode("node2", map[string]string{"type": "db"}, 4e9, 4e9),
+		drained,
+		down,
+	}
+
+	placement := &swarm.Placement{Constraints: []string{"node.labels.type == web"}}
+	sim, err := simulateScheduling(simSpec(2, placement, nil), nodes, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"node1": 2, "node2": 0, "node3": 0, "node4": 0}, serviceTasksByNode(sim))
+	assert.Equal(t, reasonConstraints, sim.nodes[1].reason)
+	assert.Equal(t, "node availability is drain", sim.nodes[2].reason)
+	assert.Equal(t, "node is down", sim.nodes[3].reason)
+}
+
+func TestSimulateSchedulingInsufficientResources(t *testing.T) {
+	nodes := []swarm.Node{
+		simNode("node1", nil, 2e9, 4e9),
+		simNode("node2", nil, 1e9, 4e9),
+		simNode("node3", nil, 4e9, 4e9),
+	}
+	tasks := []swarm.Task{
+		{
+			NodeID:       "node3",
+			DesiredState: swarm.TaskStateRunning,
+			Spec: swarm.TaskSpec{Resources: &swarm.ResourceRequirements{
+				Reservations: &swarm.Resources{NanoCPUs: 3e9},
+			}},
+		},
+	}
+	placement := &swarm.Placement{Constraints: []string{"node.hostname!=node2"}}
+
+	sim, err := simulateScheduling(simSpec(3, placement, &swarm.Resources{NanoCPUs: 1e9}), nodes, tasks)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"node1": 2, "node2": 0, "node3": 1}, serviceTasksByNode(sim))
+	assert.Len(t, sim.pending, 0)
+
+	sim, err = simulateScheduling(simSpec(5, placement, &swarm.Resources{NanoCPUs: 1e9}), nodes, tasks)
+	require.NoError(t, err)
+	require.Len(t, sim.pending, 2)
+	assert.Equal(t, pendingTask{
+		name:   "web.4",
+		reason: "no suitable node (insufficient resources on 2 nodes; scheduling constraints not satisfied on 1 node)",
+	}, sim.pending[0])
+}
+
+func TestSimulateSchedulingPlacementPreferences(t *testing.T) {
+	nodes := []swarm.Node{
+		simNode("node1", map[string]string{"az": "a"}, 4e9, 4e9),
+		simNode("node2", map[string]string{"az": "a"}, 4e9, 4e9),
+		simNode("node3", map[string]string{"az": "a"}, 4e9, 4e9),
+		simNode("node4", map[string]string{"az": "b"}, 4e9, 4e9),
+	}
+	placement := &swarm.Placement{Preferences: []swarm.PlacementPreference{
+		{Spread: &swarm.SpreadOver{SpreadDescriptor: "node.labels.az"}},
+	}}
+
+	sim, err := simulateScheduling(simSpec(4, placement, nil), nodes, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"node1": 1, "node2": 1, "node3": 0, "node4": 2}, serviceTasksByNode(sim))
+}
+
+func TestSimulateSchedulingGlobal(t *testing.T) {
+	nodes := []swarm.Node{
+		simNode("node1", nil, 2e9, 4e9),
+		simNode("node2", nil, 1e9, 4e9),
+	}
+	spec := simSpec(0, nil, &swarm.Resources{NanoCPUs: 2e9})
+	spec.Mode = swarm.ServiceMode{Global: &swarm.GlobalService{}}
+
+	sim, err := simulateScheduling(spec, nodes, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"node1": 1, "node2": 0}, serviceTasksByNode(sim))
+	assert.Equal(t, []pendingTask{{name: "web.node2", reason: "insufficient resources on node node2"}}, sim.pending)
+}
+
+func TestParsePlacementConstraintsErrors(t *testing.T) {
+	_, err := parsePlacementConstraints([]string{"node.role"})
+	assert.EqualError(t, err, `invalid constraint "node.role": expected key==value or key!=value`)
+
+	_, err = parsePlacementConstraints([]string{"node.color==red"})
+	assert.EqualError(t, err, `invalid constraint key "node.color"`)
+}
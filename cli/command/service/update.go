@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/docker/cli/cli"
@@ -23,19 +24,37 @@ import (
 	"golang.org/x/net/context"
 )
 
-func newUpdateCommand(dockerCli *command.DockerCli) *cobra.Command {
+// bulkUpdateOptions selects the services updated by a single
+// `docker service update --filter` invocation.
+type bulkUpdateOptions struct {
+	filter opts.FilterOpt
+	yes    bool
+}
+
+func newUpdateCommand(dockerCli command.Cli) *cobra.Command {
 	serviceOpts := newServiceOptions()
+	bulkOpts := bulkUpdateOptions{filter: opts.NewFilterOpt()}
 
 	cmd := &cobra.Command{
 		Use:   "update [OPTIONS] SERVICE",
 		Short: "Update a service",
-		Args:  cli.ExactArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed(flagFilter) {
+				return cli.NoArgs(cmd, args)
+			}
+			return cli.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed(flagFilter) {
+				return runBulkUpdate(dockerCli, cmd.Flags(), serviceOpts, bulkOpts)
+			}
 			return runUpdate(dockerCli, cmd.Flags(), serviceOpts, args[0])
 		},
 	}
 
 	flags := cmd.Flags()
+	flags.VarP(&bulkOpts.filter, flagFilter, "f", "Update all services matching the filter instead of a single service")
+	flags.BoolVarP(&bulkOpts.yes, flagYes, "y", false, "Do not prompt for confirmation when updating services matching --filter")
 	flags.String("image", "", "Service image tag")
 	flags.Var(&ShlexOpt{}, "args", "Service command args")
 	flags.Bool("rollback", false, "Rollback to previous specification")
@@ -103,10 +122,101 @@ func newListOptsVar() *opts.ListOpts {
 	return opts.NewListOptsRef(&[]string{}, nil)
 }
 
+func runUpdate(dockerCli command.Cli, flags *pflag.FlagSet, opts *serviceOptions, serviceID string) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if err := updateServiceFromFlags(ctx, dockerCli, flags, serviceID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(dockerCli.Out(), "%s\n", serviceID)
+
+	if opts.detach {
+		if !flags.Changed("detach") {
+			fmt.Fprintln(dockerCli.Err(), "Since --detach=false was not specified, tasks will be updated in the background.\n"+
+				"In a future release, --detach=false will become the default.")
+		}
+		return nil
+	}
+
+	return waitOnService(ctx, dockerCli, serviceID, &opts.waitOptions)
+}
+
+// runBulkUpdate applies the same flag-driven update to every service matching
+// the filter, one after the other, and prints a summary of the results.
+func runBulkUpdate(dockerCli command.Cli, flags *pflag.FlagSet, opts *serviceOptions, bulkOpts bulkUpdateOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	services, err := dockerCli.Client().ServiceList(ctx, types.ServiceListOptions{Filters: bulkOpts.filter.Value()})
+	if err != nil {
+		return err
+	}
+	if len(services) == 0 {
+		return errors.New("no services match the filter")
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Spec.Name < services[j].Spec.Name
+	})
+
+	if !bulkOpts.yes {
+		message := fmt.Sprintf("The following %d service(s) will be updated:\n", len(services))
+		for _, service := range services {
+			message += "  " + service.Spec.Name + "\n"
+		}
+		message += "Are you sure you want to continue?"
+		if !command.PromptForConfirmation(dockerCli.In(), dockerCli.Out(), message) {
+			return nil
+		}
+	}
+
+	if opts.detach && !flags.Changed("detach") {
+		fmt.Fprintln(dockerCli.Err(), "Since --detach=false was not specified, tasks will be updated in the background.\n"+
+			"In a future release, --detach=false will become the default.")
+	}
+
+	results := make([]string, len(services))
+	var failed int
+	for i, service := range services {
+		err := updateServiceFromFlags(ctx, dockerCli, flags, service.ID)
+		if err == nil && !opts.detach {
+			err = waitOnService(ctx, dockerCli, service.ID, &opts.waitOptions)
+		}
+		switch {
+		case err != nil:
+			failed++
+			results[i] = "failed\t" + strings.Replace(err.Error(), "\n", " ", -1)
+		case opts.detach:
+			results[i] = "updated\t"
+		default:
+			results[i] = "converged\t"
+		}
+	}
+
+	fmt.Fprintln(dockerCli.Out())
+	w := tabwriter.NewWriter(dockerCli.Out(), 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tRESULT\tERROR")
+	for i, service := range services {
+		fmt.Fprintf(w, "%s\t%s\n", service.Spec.Name, results[i])
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return errors.Errorf("%d of %d services failed to update", failed, len(services))
+	}
+	return nil
+}
+
+// updateServiceFromFlags applies the changes requested by the update flags
+// to a single service.
 // nolint: gocyclo
-func runUpdate(dockerCli *command.DockerCli, flags *pflag.FlagSet, opts *serviceOptions, serviceID string) error {
+func updateServiceFromFlags(ctx context.Context, dockerCli command.Cli, flags *pflag.FlagSet, serviceID string) error {
 	apiClient := dockerCli.Client()
-	ctx := context.Background()
 
 	service, _, err := apiClient.ServiceInspectWithRaw(ctx, serviceID, types.ServiceInspectOptions{})
 	if err != nil {
@@ -128,10 +238,12 @@ func runUpdate(dockerCli *command.DockerCli, flags *pflag.FlagSet, opts *service
 
 	spec := &service.Spec
 	if rollback {
-		// Rollback can't be combined with other flags.
+		// Rollback can't be combined with other flags, except the flags
+		// to select the services and to wait for the rollback.
 		otherFlagsPassed := false
 		flags.VisitAll(func(f *pflag.Flag) {
-			if f.Name == "rollback" {
+			switch f.Name {
+			case "rollback", flagFilter, flagYes, "detach", "quiet", flagProgress, flagWaitTimeout:
 				return
 			}
 			if flags.Changed(f.Name) {
@@ -211,18 +323,7 @@ func runUpdate(dockerCli *command.DockerCli, flags *pflag.FlagSet, opts *service
 	for _, warning := range response.Warnings {
 		fmt.Fprintln(dockerCli.Err(), warning)
 	}
-
-	fmt.Fprintf(dockerCli.Out(), "%s\n", serviceID)
-
-	if opts.detach {
-		if !flags.Changed("detach") {
-			fmt.Fprintln(dockerCli.Err(), "Since --detach=false was not specified, tasks will be updated in the background.\n"+
-				"In a future release, --detach=false will become the default.")
-		}
-		return nil
-	}
-
-	return waitOnService(ctx, dockerCli, serviceID, opts)
+	return nil
 }
 
 // nolint: gocyclo
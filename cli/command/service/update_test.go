@@ -1,15 +1,24 @@
 package service
 
 import (
+	"bytes"
+	"io/ioutil"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/internal/test"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	mounttypes "github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/pkg/testutil"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
@@ -494,3 +503,143 @@ func TestUpdateStopSignal(t *testing.T) {
 	updateService(nil, nil, flags, spec)
 	assert.Equal(t, "SIGWINCH", cspec.StopSignal)
 }
+
+func TestUpdateArgsWithFilter(t *testing.T) {
+	testCases := []struct {
+		args          []string
+		expectedError string
+	}{
+		{args: []string{}, expectedError: "requires exactly 1 argument"},
+		{args: []string{"--filter", "label=team=payments", "web"}, expectedError: "accepts no argument"},
+	}
+	for _, tc := range testCases {
+		cmd := newUpdateCommand(nil)
+		cmd.SetArgs(tc.args)
+		cmd.SetOutput(ioutil.Discard)
+		err := cmd.Execute()
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), tc.expectedError)
+		}
+	}
+}
+
+// bulkUpdateClient is a fake client listing services web and api, of which
+// the updates of the services in failing return an error.
+func bulkUpdateClient(filter *filters.Args, updated *[]string, failing ...string) *fakeClient {
+	services := map[string]swarm.Service{}
+	for _, name := range []string{"web", "api"} {
+		services[name+"-id"] = swarm.Service{
+			ID: name + "-id",
+			Spec: swarm.ServiceSpec{
+				Annotations:  swarm.Annotations{Name: name},
+				TaskTemplate: swarm.TaskSpec{ContainerSpec: swarm.ContainerSpec{}},
+			},
+		}
+	}
+	return &fakeClient{
+		serviceListFunc: func(options types.ServiceListOptions) ([]swarm.Service, error) {
+			*filter = options.Filters
+			return []swarm.Service{services["web-id"], services["api-id"]}, nil
+		},
+		serviceInspectWithRawFunc: func(serviceID string) (swarm.Service, []byte, error) {
+			return services[serviceID], nil, nil
+		},
+		serviceUpdateFunc: func(serviceID string, version swarm.Version, service swarm.ServiceSpec, options types.ServiceUpdateOptions) (types.ServiceUpdateResponse, error) {
+			for _, f := range failing {
+				if f == service.Name {
+					return types.ServiceUpdateResponse{}, errors.New("update out of sequence")
+				}
+			}
+			*updated = append(*updated, service.Name+" "+strings.Join(service.TaskTemplate.ContainerSpec.Env, ","))
+			return types.ServiceUpdateResponse{}, nil
+		},
+	}
+}
+
+func TestBulkUpdate(t *testing.T) {
+	var (
+		filter  filters.Args
+		updated []string
+	)
+	buf := new(bytes.Buffer)
+	cmd := newUpdateCommand(test.NewFakeCli(bulkUpdateClient(&filter, &updated), buf))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--filter", "label=team=payments", "--yes", "--env-add", "LEVEL=debug"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, []string{"team=payments"}, filter.Get("label"))
+	assert.Equal(t, []string{"api LEVEL=debug", "web LEVEL=debug"}, updated)
+	testutil.EqualNormalizedString(t, testutil.RemoveSpace, buf.String(), `
+SERVICE   RESULT    ERROR
+api       updated
+web       updated
+`)
+}
+
+func TestBulkUpdatePartialFailure(t *testing.T) {
+	var (
+		filter  filters.Args
+		updated []string
+	)
+	buf := new(bytes.Buffer)
+	cmd := newUpdateCommand(test.NewFakeCli(bulkUpdateClient(&filter, &updated, "api"), buf))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--filter", "label=team=payments", "--yes", "--env-add", "LEVEL=debug"})
+	err := cmd.Execute()
+	testutil.ErrorContains(t, err, "1 of 2 services failed to update")
+
+	assert.Equal(t, []string{"web LEVEL=debug"}, updated)
+	testutil.EqualNormalizedString(t, testutil.RemoveSpace, buf.String(), `
+SERVICE   RESULT    ERROR
+api       failed    update out of sequence
+web       updated
+`)
+}
+
+func TestBulkUpdatePromptDeclined(t *testing.T) {
+	var (
+		filter  filters.Args
+		updated []string
+	)
+	buf := new(bytes.Buffer)
+	cli := test.NewFakeCli(bulkUpdateClient(&filter, &updated), buf)
+	cli.SetIn(command.NewInStream(ioutil.NopCloser(strings.NewReader("n\n"))))
+	cmd := newUpdateCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--filter", "label=team=payments", "--env-add", "LEVEL=debug"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Empty(t, updated)
+	assert.Contains(t, buf.String(), "The following 2 service(s) will be updated:\n  api\n  web\n")
+	assert.NotContains(t, buf.String(), "SERVICE")
+}
+
+func TestUpdateRollbackWait(t *testing.T) {
+	var rolledBackTo swarm.ServiceSpec
+	replicas := uint64(1)
+	mode := swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}}
+	buf := new(bytes.Buffer)
+	cmd := newUpdateCommand(test.NewFakeCli(&fakeClient{
+		serviceInspectWithRawFunc: func(serviceID string) (swarm.Service, []byte, error) {
+			return swarm.Service{
+				ID:           serviceID,
+				Spec:         swarm.ServiceSpec{Annotations: swarm.Annotations{Name: "web", Labels: map[string]string{"version": "2"}}, Mode: mode},
+				PreviousSpec: &swarm.ServiceSpec{Annotations: swarm.Annotations{Name: "web", Labels: map[string]string{"version": "1"}}, Mode: mode},
+				UpdateStatus: &swarm.UpdateStatus{State: swarm.UpdateStateRollbackCompleted, Message: "rollback completed"},
+			}, nil, nil
+		},
+		serviceUpdateFunc: func(serviceID string, version swarm.Version, service swarm.ServiceSpec, options types.ServiceUpdateOptions) (types.ServiceUpdateResponse, error) {
+			rolledBackTo = service
+			return types.ServiceUpdateResponse{}, nil
+		},
+	}, buf))
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--rollback", "--detach=false", "--progress", "json", "--wait-timeout", "1m", "web-id"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	status, ok := err.(cli.StatusError)
+	require.True(t, ok, "unexpected error: %v", err)
+	assert.Equal(t, exitCodeRolledBack, status.StatusCode)
+	assert.Equal(t, "1", rolledBackTo.Labels["version"])
+	assert.Equal(t, "web-id\n", buf.String())
+}
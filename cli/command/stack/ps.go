@@ -11,6 +11,7 @@ import (
 	"github.com/docker/cli/cli/command/idresolver"
 	"github.com/docker/cli/cli/command/task"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/opts"
 	"github.com/spf13/cobra"
 )
@@ -22,6 +23,7 @@ type psOptions struct {
 	noResolve bool
 	quiet     bool
 	format    string
+	watch     bool
 }
 
 func newPsCommand(dockerCli command.Cli) *cobra.Command {
@@ -42,6 +44,7 @@ func newPsCommand(dockerCli command.Cli) *cobra.Command {
 	flags.VarP(&opts.filter, "filter", "f", "Filter output based on conditions provided")
 	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Only display task IDs")
 	flags.StringVar(&opts.format, "format", "", "Pretty-print tasks using a Go template")
+	flags.BoolVar(&opts.watch, "watch", false, "Watch the tasks and redraw them as they change")
 
 	return cmd
 }
@@ -53,6 +56,24 @@ func runPS(dockerCli command.Cli, opts psOptions) error {
 
 	filter := getStackFilterFromOpt(opts.namespace, opts.filter)
 
+	format := opts.format
+	if len(format) == 0 {
+		if len(dockerCli.ConfigFile().TasksFormat) > 0 && !opts.quiet {
+			format = dockerCli.ConfigFile().TasksFormat
+		} else {
+			format = formatter.TableFormatKey
+		}
+	}
+
+	if opts.watch {
+		eventFilter := getStackFilter(namespace)
+		eventFilter.Add("type", "service")
+		listTasks := func(ctx context.Context) ([]swarm.Task, error) {
+			return client.TaskList(ctx, types.TaskListOptions{Filters: filter})
+		}
+		return task.Watch(ctx, dockerCli, listTasks, eventFilter, idresolver.New(client, opts.noResolve), !opts.noTrunc, opts.quiet, format)
+	}
+
 	tasks, err := client.TaskList(ctx, types.TaskListOptions{Filters: filter})
 	if err != nil {
 		return err
@@ -63,14 +84,5 @@ func runPS(dockerCli command.Cli, opts psOptions) error {
 		return nil
 	}
 
-	format := opts.format
-	if len(format) == 0 {
-		if len(dockerCli.ConfigFile().TasksFormat) > 0 && !opts.quiet {
-			format = dockerCli.ConfigFile().TasksFormat
-		} else {
-			format = formatter.TableFormatKey
-		}
-	}
-
 	return task.Print(ctx, dockerCli, tasks, idresolver.New(client, opts.noResolve), !opts.noTrunc, opts.quiet, format)
 }
@@ -2,6 +2,7 @@ package task
 
 import (
 	"fmt"
+	"io"
 	"sort"
 
 	"golang.org/x/net/context"
@@ -36,13 +37,18 @@ func (t tasksBySlot) Less(i, j int) bool {
 // Besides this, command `docker node ps <node>`
 // and `docker stack ps` will call this, too.
 func Print(ctx context.Context, dockerCli command.Cli, tasks []swarm.Task, resolver *idresolver.IDResolver, trunc, quiet bool, format string) error {
+	return write(ctx, dockerCli.Out(), tasks, resolver, trunc, quiet, format)
+}
+
+// write sorts tasks in place and writes them to out in the given format.
+func write(ctx context.Context, out io.Writer, tasks []swarm.Task, resolver *idresolver.IDResolver, trunc, quiet bool, format string) error {
 	sort.Stable(tasksBySlot(tasks))
 
 	names := map[string]string{}
 	nodes := map[string]string{}
 
 	tasksCtx := formatter.Context{
-		Output: dockerCli.Out(),
+		Output: out,
 		Format: formatter.NewTaskFormat(format, quiet),
 		Trunc:  trunc,
 	}
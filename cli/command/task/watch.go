@@ -0,0 +1,130 @@
+package task
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/idresolver"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// watchInterval is how often tasks are listed again while watching. Task
+// state changes are not reported as events, so events alone are not enough
+// to keep the list up to date.
+const watchInterval = time.Second
+
+const (
+	clearScreen     = "\033[2J\033[H"
+	highlightChange = "\033[1m"
+	highlightError  = "\033[31m"
+	resetHighlight  = "\033[0m"
+)
+
+// ListFunc returns the tasks to display.
+type ListFunc func(ctx context.Context) ([]swarm.Task, error)
+
+// Watch prints the tasks returned by listTasks, and prints them again each
+// time they change until ctx is cancelled. Events matching eventFilter cause
+// the tasks to be listed again immediately. On a terminal the list is
+// redrawn in place, with tasks that changed state highlighted, and tasks
+// that reported a new error highlighted in red.
+func Watch(ctx context.Context, dockerCli command.Cli, listTasks ListFunc, eventFilter filters.Args, resolver *idresolver.IDResolver, trunc, quiet bool, format string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, errs := dockerCli.Client().Events(ctx, types.EventsOptions{Filters: eventFilter})
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	isTerminal := dockerCli.Out().IsTerminal()
+
+	var previous map[string]swarm.Task
+	for {
+		tasks, err := listTasks(ctx)
+		if err != nil {
+			return err
+		}
+
+		if previous == nil || tasksChanged(previous, tasks) {
+			buf := new(bytes.Buffer)
+			if err := write(ctx, buf, tasks, resolver, trunc, quiet, format); err != nil {
+				return err
+			}
+			if isTerminal {
+				fmt.Fprint(dockerCli.Out(), clearScreen)
+				fmt.Fprint(dockerCli.Out(), highlight(buf.String(), tasks, previous))
+			} else {
+				if previous != nil {
+					fmt.Fprintln(dockerCli.Out())
+				}
+				buf.WriteTo(dockerCli.Out())
+			}
+			previous = indexTasks(tasks)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-events:
+		case <-errs:
+			// The daemon may not support events for the requested
+			// types; keep refreshing on the ticker only.
+			events, errs = nil, nil
+		}
+	}
+}
+
+func indexTasks(tasks []swarm.Task) map[string]swarm.Task {
+	index := make(map[string]swarm.Task, len(tasks))
+	for _, task := range tasks {
+		index[task.ID] = task
+	}
+	return index
+}
+
+func tasksChanged(previous map[string]swarm.Task, tasks []swarm.Task) bool {
+	if len(previous) != len(tasks) {
+		return true
+	}
+	for _, task := range tasks {
+		prev, ok := previous[task.ID]
+		if !ok || !prev.Meta.UpdatedAt.Equal(task.Meta.UpdatedAt) || prev.Status.State != task.Status.State || prev.Status.Err != task.Status.Err {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight marks the lines of output belonging to tasks that changed state
+// or reported a new error since previous. The output holds one line per
+// task, in the order of tasks, optionally preceded by a table header; if it
+// does not, it is returned unchanged.
+func highlight(output string, tasks []swarm.Task, previous map[string]swarm.Task) string {
+	if previous == nil {
+		return output
+	}
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	offset := len(lines) - len(tasks)
+	if offset != 0 && offset != 1 {
+		return output
+	}
+	for i, task := range tasks {
+		prev, ok := previous[task.ID]
+		switch {
+		case task.Status.Err != "" && (!ok || prev.Status.Err != task.Status.Err):
+			lines[i+offset] = highlightError + lines[i+offset] + resetHighlight
+		case !ok || prev.Status.State != task.Status.State:
+			lines[i+offset] = highlightChange + lines[i+offset] + resetHighlight
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
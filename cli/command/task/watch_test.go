@@ -0,0 +1,64 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTask(id string, state swarm.TaskState, err string) swarm.Task {
+	return swarm.Task{ID: id, Status: swarm.TaskStatus{State: state, Err: err}}
+}
+
+func TestTasksChanged(t *testing.T) {
+	previous := indexTasks([]swarm.Task{
+		newTask("a", swarm.TaskStateRunning, ""),
+		newTask("b", swarm.TaskStatePreparing, ""),
+	})
+
+	assert.False(t, tasksChanged(previous, []swarm.Task{
+		newTask("b", swarm.TaskStatePreparing, ""),
+		newTask("a", swarm.TaskStateRunning, ""),
+	}))
+	assert.True(t, tasksChanged(previous, []swarm.Task{
+		newTask("a", swarm.TaskStateRunning, ""),
+		newTask("b", swarm.TaskStateRunning, ""),
+	}))
+	assert.True(t, tasksChanged(previous, []swarm.Task{
+		newTask("a", swarm.TaskStateRunning, ""),
+		newTask("c", swarm.TaskStatePreparing, ""),
+	}))
+	assert.True(t, tasksChanged(previous, []swarm.Task{
+		newTask("a", swarm.TaskStateRunning, ""),
+	}))
+}
+
+func TestHighlight(t *testing.T) {
+	previous := indexTasks([]swarm.Task{
+		newTask("a", swarm.TaskStateRunning, ""),
+		newTask("b", swarm.TaskStatePreparing, ""),
+		newTask("c", swarm.TaskStateRunning, ""),
+	})
+	tasks := []swarm.Task{
+		newTask("a", swarm.TaskStateRunning, ""),
+		newTask("b", swarm.TaskStateRunning, ""),
+		newTask("c", swarm.TaskStateFailed, "task: non-zero exit (1)"),
+		newTask("d", swarm.TaskStateNew, ""),
+	}
+	output := "ID  STATE\na   running\nb   running\nc   failed\nd   new\n"
+
+	expected := "ID  STATE\na   running\n" +
+		highlightChange + "b   running" + resetHighlight + "\n" +
+		highlightError + "c   failed" + resetHighlight + "\n" +
+		highlightChange + "d   new" + resetHighlight + "\n"
+	assert.Equal(t, expected, highlight(output, tasks, previous))
+}
+
+func TestHighlightUnknownLayout(t *testing.T) {
+	tasks := []swarm.Task{newTask("a", swarm.TaskStateFailed, "")}
+	output := "id: a\nstate: failed\nerror:\n"
+
+	assert.Equal(t, output, highlight(output, tasks, map[string]swarm.Task{}))
+	assert.Equal(t, output, highlight(output, tasks, nil))
+}
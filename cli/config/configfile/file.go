@@ -41,8 +41,14 @@ type ConfigFile struct {
 	ConfigFormat         string                      `json:"configFormat,omitempty"`
 	NodesFormat          string                      `json:"nodesFormat,omitempty"`
 	PruneFilters         []string                    `json:"pruneFilters,omitempty"`
+	PullPolicy           string                      `json:"pullPolicy,omitempty"`
+	Profiles             map[string]RunProfile       `json:"profiles,omitempty"`
 }
 
+// RunProfile holds the options applied by `docker run --profile`, as the
+// values of each flag by flag name
+type RunProfile map[string][]string
+
 // LegacyLoadFromReader reads the non-nested configuration data given and sets up the
 // auth config information with given directory and populates the receiver object
 func (configFile *ConfigFile) LegacyLoadFromReader(configData io.Reader) error {
@@ -0,0 +1,395 @@
+// Package envfile reads the files of environment variables given with
+// --env-file.
+//
+// Two formats are supported. The plain format has one KEY=VALUE per line,
+// where the value is taken verbatim. The extended format, used when
+// requested or when the first line of the file is "# env-file: extended",
+// additionally supports:
+//
+//	export KEY=VALUE               an optional "export " prefix
+//	KEY='single quoted'            literal values, possibly over several lines
+//	KEY="double quoted\n"          escapes (\n, \t, \r, \", \\, \$) and expansion
+//	KEY=${OTHER}/bin:$PATH         expansion of earlier entries and of the host environment
+//	KEY=value # comment            comments after unquoted and quoted values
+package envfile
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	runconfigopts "github.com/docker/docker/runconfig/opts"
+	"github.com/pkg/errors"
+)
+
+const (
+	// FormatPlain is the format of KEY=VALUE lines taken verbatim.
+	FormatPlain = "plain"
+	// FormatExtended is the format supporting quoting, escapes, multi-line
+	// values and variable expansion.
+	FormatExtended = "extended"
+
+	// extendedHeader selects the extended format when it is the first line
+	// of a file.
+	extendedHeader = "# env-file: extended"
+)
+
+var utf8bom = []byte{0xEF, 0xBB, 0xBF}
+
+// ValidateFormat checks the format given with --env-file-format. An empty
+// format detects the format of each file from its header.
+func ValidateFormat(format string) error {
+	switch format {
+	case "", FormatPlain, FormatExtended:
+		return nil
+	default:
+		return errors.Errorf("invalid env file format %q: must be %q or %q", format, FormatPlain, FormatExtended)
+	}
+}
+
+// ReadKVStrings reads the variables of the env files, in the given format,
+// followed by override.
+func ReadKVStrings(files []string, override []string, format string) ([]string, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+	variables := []string{}
+	for _, file := range files {
+		parsed, err := ParseFile(file, format)
+		if err != nil {
+			return nil, err
+		}
+		variables = append(variables, parsed...)
+	}
+	return append(variables, override...), nil
+}
+
+// ParseFile reads the variables of an env file, in the given format or, if
+// empty, in the format selected by its header.
+func ParseFile(filename, format string) ([]string, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	content = bytes.TrimPrefix(content, utf8bom)
+
+	if format == "" {
+		format = FormatPlain
+		firstLine := content
+		if i := bytes.IndexByte(content, '\n'); i >= 0 {
+			firstLine = content[:i]
+		}
+		if strings.TrimSpace(string(firstLine)) == extendedHeader {
+			format = FormatExtended
+		}
+	}
+	if format == FormatPlain {
+		return runconfigopts.ParseEnvFile(filename)
+	}
+	return Parse(bytes.NewReader(content), filename, os.LookupEnv)
+}
+
+// Parse reads variables in the extended format from r. References to
+// variables not defined earlier in r, and variables without value, are
+// looked up with lookupEnv. Errors mention filename and the line they occur
+// on.
+func Parse(r io.Reader, filename string, lookupEnv func(string) (string, bool)) ([]string, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{
+		filename:  filename,
+		input:     string(bytes.TrimPrefix(content, utf8bom)),
+		line:      1,
+		lookupEnv: lookupEnv,
+		defined:   make(map[string]string),
+	}
+	if !utf8.ValidString(p.input) {
+		return nil, p.invalidUTF8()
+	}
+	return p.parse()
+}
+
+type parser struct {
+	filename  string
+	input     string
+	pos       int
+	line      int
+	lookupEnv func(string) (string, bool)
+	defined   map[string]string
+	variables []string
+}
+
+func (p *parser) errorf(line int, format string, args ...interface{}) error {
+	return errors.Errorf("%s:%d: "+format, append([]interface{}{p.filename, line}, args...)...)
+}
+
+func (p *parser) invalidUTF8() error {
+	for i, line := range strings.Split(p.input, "\n") {
+		if !utf8.ValidString(line) {
+			return p.errorf(i+1, "invalid utf8 bytes")
+		}
+	}
+	return p.errorf(1, "invalid utf8 bytes")
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	return p.input[p.pos]
+}
+
+func (p *parser) next() byte {
+	c := p.input[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+func (p *parser) skipBlanks() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\r') {
+		p.next()
+	}
+}
+
+// skipLine skips the rest of the line, and its newline.
+func (p *parser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+func (p *parser) parse() ([]string, error) {
+	for !p.eof() {
+		p.skipBlanks()
+		if p.eof() {
+			break
+		}
+		switch p.peek() {
+		case '\n':
+			p.next()
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+		if err := p.parseEntry(); err != nil {
+			return nil, err
+		}
+	}
+	return p.variables, nil
+}
+
+func (p *parser) parseEntry() error {
+	line, start := p.line, p.pos
+	name := p.readName()
+	if name == "export" && !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipBlanks()
+		name = p.readName()
+	}
+	if name == "" {
+		return p.errorf(line, "invalid variable name")
+	}
+	p.skipBlanks()
+
+	if p.eof() || p.peek() == '\n' || p.peek() == '#' {
+		// A variable without value is taken from the host environment.
+		p.skipLine()
+		value, _ := p.lookup(name)
+		p.define(name, value)
+		return nil
+	}
+	if p.peek() != '=' {
+		p.pos = start
+		return p.errorf(line, "invalid variable name %q", p.restOfLine())
+	}
+	p.next()
+	p.skipBlanks()
+
+	value, err := p.readValue()
+	if err != nil {
+		return err
+	}
+	p.skipBlanks()
+	if !p.eof() && p.peek() != '\n' && p.peek() != '#' {
+		return p.errorf(p.line, "unexpected characters after the value of %s", name)
+	}
+	p.skipLine()
+	p.define(name, value)
+	return nil
+}
+
+func (p *parser) define(name, value string) {
+	p.defined[name] = value
+	p.variables = append(p.variables, name+"="+value)
+}
+
+func (p *parser) lookup(name string) (string, bool) {
+	if value, ok := p.defined[name]; ok {
+		return value, true
+	}
+	return p.lookupEnv(name)
+}
+
+func (p *parser) restOfLine() string {
+	rest := p.input[p.pos:]
+	if end := strings.IndexByte(rest, '\n'); end >= 0 {
+		rest = rest[:end]
+	}
+	return strings.TrimSpace(rest)
+}
+
+func isNameChar(c byte, first bool) bool {
+	switch {
+	case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		return true
+	case '0' <= c && c <= '9', c == '.', c == '-':
+		return !first
+	}
+	return false
+}
+
+func (p *parser) readName() string {
+	start := p.pos
+	for !p.eof() && isNameChar(p.peek(), p.pos == start) {
+		p.next()
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *parser) readValue() (string, error) {
+	if p.eof() {
+		return "", nil
+	}
+	switch p.peek() {
+	case '\'':
+		return p.readSingleQuoted()
+	case '"':
+		return p.readDoubleQuoted()
+	default:
+		return p.readUnquoted()
+	}
+}
+
+func (p *parser) readSingleQuoted() (string, error) {
+	line := p.line
+	p.next()
+	start := p.pos
+	for !p.eof() {
+		if p.peek() == '\'' {
+			value := p.input[start:p.pos]
+			p.next()
+			return value, nil
+		}
+		p.next()
+	}
+	return "", p.errorf(line, "unterminated single-quoted value")
+}
+
+func (p *parser) readDoubleQuoted() (string, error) {
+	line := p.line
+	p.next()
+	var value bytes.Buffer
+	for !p.eof() {
+		c := p.next()
+		switch c {
+		case '"':
+			return value.String(), nil
+		case '\\':
+			if p.eof() {
+				break
+			}
+			switch e := p.next(); e {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'r':
+				value.WriteByte('\r')
+			case '"', '\\', '$':
+				value.WriteByte(e)
+			case '\n':
+				// A backslash before a newline continues the line.
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(e)
+			}
+		case '$':
+			if err := p.expand(&value); err != nil {
+				return "", err
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", p.errorf(line, "unterminated double-quoted value")
+}
+
+func (p *parser) readUnquoted() (string, error) {
+	var value bytes.Buffer
+	for !p.eof() && p.peek() != '\n' {
+		c := p.peek()
+		if c == '#' && (value.Len() == 0 || isBlank(value.Bytes()[value.Len()-1])) {
+			break
+		}
+		p.next()
+		switch c {
+		case '\\':
+			if !p.eof() && p.peek() != '\n' {
+				value.WriteByte(p.next())
+			} else {
+				value.WriteByte(c)
+			}
+		case '$':
+			if err := p.expand(&value); err != nil {
+				return "", err
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return strings.TrimRight(value.String(), " \t\r"), nil
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// expand writes the value of the variable referenced after a '$' to value.
+// A '$' not followed by a variable name is kept as is.
+func (p *parser) expand(value *bytes.Buffer) error {
+	line := p.line
+	if !p.eof() && p.peek() == '{' {
+		p.next()
+		name := p.readName()
+		if p.eof() || p.peek() != '}' {
+			return p.errorf(line, "invalid variable reference ${%s", name)
+		}
+		p.next()
+		if name == "" {
+			return p.errorf(line, "invalid variable reference ${}")
+		}
+		v, _ := p.lookup(name)
+		value.WriteString(v)
+		return nil
+	}
+	start := p.pos
+	for !p.eof() && isNameChar(p.peek(), p.pos == start) && p.peek() != '.' && p.peek() != '-' {
+		p.next()
+	}
+	name := p.input[start:p.pos]
+	if name == "" {
+		value.WriteByte('$')
+		return nil
+	}
+	v, _ := p.lookup(name)
+	value.WriteString(v)
+	return nil
+}
@@ -0,0 +1,99 @@
+package envfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lookupEnv(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestParse(t *testing.T) {
+	input := `# env-file: extended
+PLAIN=value
+export EXPORTED=yes
+  SPACED = trimmed value   # comment
+HASH=a#b
+SINGLE='no $EXPANSION \n here'
+DOUBLE="tab\there \"quoted\" \$literal"
+MULTI="first line
+second line"
+MULTI_SINGLE='one
+two'
+HOME_BIN=${HOME}/bin
+REF="$PLAIN-$UNDEFINED-${EXPORTED}"
+ESCAPED=a\$b
+EMPTY=
+PASSED
+DOLLAR=$
+`
+	vars, err := Parse(strings.NewReader(input), "test.env", lookupEnv(map[string]string{"HOME": "/home/user", "PASSED": "from host"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"PLAIN=value",
+		"EXPORTED=yes",
+		"SPACED=trimmed value",
+		"HASH=a#b",
+		`SINGLE=no $EXPANSION \n here`,
+		"DOUBLE=tab\there \"quoted\" $literal",
+		"MULTI=first line\nsecond line",
+		"MULTI_SINGLE=one\ntwo",
+		"HOME_BIN=/home/user/bin",
+		"REF=value--yes",
+		"ESCAPED=a$b",
+		"EMPTY=",
+		"PASSED=from host",
+		"DOLLAR=$",
+	}, vars)
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"A=1\nB='unterminated\n\n", "test.env:2: unterminated single-quoted value"},
+		{"A=1\n\nB=\"unterminated\n", "test.env:3: unterminated double-quoted value"},
+		{"A=1\n=value\n", "test.env:2: invalid variable name"},
+		{"BAD NAME=value\n", `test.env:1: invalid variable name "BAD NAME=value"`},
+		{"A='x' y\n", "test.env:1: unexpected characters after the value of A"},
+		{"A=${B\n", "test.env:1: invalid variable reference ${B"},
+		{"A=1\nB=\xff\n", "test.env:2: invalid utf8 bytes"},
+	}
+	for _, tc := range testCases {
+		_, err := Parse(strings.NewReader(tc.input), "test.env", lookupEnv(nil))
+		assert.EqualError(t, err, tc.expected)
+	}
+}
+
+func TestReadKVStrings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envfile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	plain := filepath.Join(dir, "plain.env")
+	require.NoError(t, ioutil.WriteFile(plain, []byte("A='quoted'\n"), 0644))
+	extended := filepath.Join(dir, "extended.env")
+	require.NoError(t, ioutil.WriteFile(extended, []byte(extendedHeader+"\nB='quoted'\n"), 0644))
+
+	vars, err := ReadKVStrings([]string{plain, extended}, []string{"C=override"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A='quoted'", "B=quoted", "C=override"}, vars)
+
+	vars, err = ReadKVStrings([]string{plain}, nil, FormatExtended)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A=quoted"}, vars)
+
+	_, err = ReadKVStrings([]string{plain}, nil, "yaml")
+	assert.EqualError(t, err, `invalid env file format "yaml": must be "plain" or "extended"`)
+}
@@ -8,18 +8,22 @@ import (
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/cli/cli/config/credentials"
+	manifeststore "github.com/docker/cli/cli/manifest/store"
+	registryclient "github.com/docker/cli/cli/registry/client"
 	"github.com/docker/docker/client"
 )
 
 // FakeCli emulates the default DockerCli
 type FakeCli struct {
 	command.DockerCli
-	client     client.APIClient
-	configfile *configfile.ConfigFile
-	out        *command.OutStream
-	err        io.Writer
-	in         *command.InStream
-	store      credentials.Store
+	client         client.APIClient
+	configfile     *configfile.ConfigFile
+	out            *command.OutStream
+	err            io.Writer
+	in             *command.InStream
+	store          credentials.Store
+	manifestStore  manifeststore.Store
+	registryClient registryclient.RegistryClient
 }
 
 // NewFakeCli returns a Cli backed by the fakeCli
@@ -79,3 +83,23 @@ func (c *FakeCli) CredentialsStore(serverAddress string) credentials.Store {
 	}
 	return c.store
 }
+
+// SetManifestStore sets the manifest store the cli will use
+func (c *FakeCli) SetManifestStore(store manifeststore.Store) {
+	c.manifestStore = store
+}
+
+// ManifestStore returns the manifest store the cli will use
+func (c *FakeCli) ManifestStore() manifeststore.Store {
+	return c.manifestStore
+}
+
+// SetRegistryClient sets the registry client the cli will use
+func (c *FakeCli) SetRegistryClient(client registryclient.RegistryClient) {
+	c.registryClient = client
+}
+
+// RegistryClient returns the registry client the cli will use
+func (c *FakeCli) RegistryClient(insecure bool) registryclient.RegistryClient {
+	return c.registryClient
+}
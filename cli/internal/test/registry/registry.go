@@ -0,0 +1,248 @@
+package registry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+)
+
+var (
+	manifestPath = regexp.MustCompile(`^/v2/(.+)/manifests/([^/]+)$`)
+	blobPath     = regexp.MustCompile(`^/v2/(.+)/blobs/([^/]+)$`)
+	uploadPath   = regexp.MustCompile(`^/v2/(.+)/blobs/uploads/$`)
+	tagsPath     = regexp.MustCompile(`^/v2/(.+)/tags/list$`)
+)
+
+type fakeManifest struct {
+	mediaType string
+	raw       []byte
+}
+
+// FakeRegistry is a registry serving manifests, blobs, tags and its catalog
+// from memory, over plain HTTP
+type FakeRegistry struct {
+	server    *httptest.Server
+	mu        sync.Mutex
+	manifests map[string]fakeManifest
+	blobs     map[string][]byte
+}
+
+// NewFakeRegistry starts a fake registry
+func NewFakeRegistry() *FakeRegistry {
+	r := &FakeRegistry{
+		manifests: make(map[string]fakeManifest),
+		blobs:     make(map[string][]byte),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	return r
+}
+
+// Close stops the registry
+func (r *FakeRegistry) Close() {
+	r.server.Close()
+}
+
+// Host returns the address of the registry, to use in image references
+func (r *FakeRegistry) Host() string {
+	u, _ := url.Parse(r.server.URL)
+	return u.Host
+}
+
+// AddBlob adds a blob to a repository, and returns its digest
+func (r *FakeRegistry) AddBlob(repo string, content []byte) digest.Digest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dgst := digest.FromBytes(content)
+	r.blobs[repo+"@"+dgst.String()] = content
+	return dgst
+}
+
+// HasBlob returns whether a repository has a blob
+func (r *FakeRegistry) HasBlob(repo string, dgst digest.Digest) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.blobs[repo+"@"+dgst.String()]
+	return ok
+}
+
+// AddManifest adds a manifest to a repository, by digest and by tag if one
+// is given, and returns its digest
+func (r *FakeRegistry) AddManifest(repo, tag, mediaType string, raw []byte) digest.Digest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.addManifest(repo, tag, mediaType, raw)
+}
+
+func (r *FakeRegistry) addManifest(repo, tag, mediaType string, raw []byte) digest.Digest {
+	dgst := digest.FromBytes(raw)
+	m := fakeManifest{mediaType: mediaType, raw: raw}
+	r.manifests[repo+"@"+dgst.String()] = m
+	if tag != "" {
+		r.manifests[repo+":"+tag] = m
+	}
+	return dgst
+}
+
+// Manifest returns the media type and the content of a manifest of a
+// repository, by tag or digest
+func (r *FakeRegistry) Manifest(repo, ref string) (string, []byte, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.manifests[manifestKey(repo, ref)]
+	return m.mediaType, m.raw, ok
+}
+
+// tags returns the sorted tags of the repositories of the registry
+func (r *FakeRegistry) tags() map[string][]string {
+	tags := make(map[string][]string)
+	for key := range r.manifests {
+		if i := strings.Index(key, "@"); i >= 0 {
+			if _, ok := tags[key[:i]]; !ok {
+				tags[key[:i]] = nil
+			}
+			continue
+		}
+		i := strings.LastIndex(key, ":")
+		tags[key[:i]] = append(tags[key[:i]], key[i+1:])
+	}
+	for _, repoTags := range tags {
+		sort.Strings(repoTags)
+	}
+	return tags
+}
+
+// defaultPageSize is the number of items of the pages of the lists, which is
+// small for the clients to follow the links to the next pages.
+const defaultPageSize = 2
+
+// servePage writes a page of a sorted list of the registry API, of n items
+// after last, with a link to the next page.
+func servePage(w http.ResponseWriter, req *http.Request, key string, items []string) {
+	n, err := strconv.Atoi(req.URL.Query().Get("n"))
+	if err != nil || n <= 0 {
+		n = defaultPageSize
+	}
+	last := req.URL.Query().Get("last")
+	start := sort.SearchStrings(items, last)
+	if last != "" && start < len(items) && items[start] == last {
+		start++
+	}
+	page := items[start:]
+	if len(page) > n {
+		page = page[:n]
+		next := url.URL{Path: req.URL.Path, RawQuery: url.Values{"n": {strconv.Itoa(n)}, "last": {page[n-1]}}.Encode()}
+		w.Header().Set("Link", "<"+next.String()+`>; rel="next"`)
+	}
+	if page == nil {
+		page = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{key: page})
+}
+
+func manifestKey(repo, ref string) string {
+	if _, err := digest.Parse(ref); err == nil {
+		return repo + "@" + ref
+	}
+	return repo + ":" + ref
+}
+
+func (r *FakeRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	if req.URL.Path == "/v2/" {
+		return
+	}
+
+	if match := uploadPath.FindStringSubmatch(req.URL.Path); match != nil && req.Method == "POST" {
+		repo, from, mount := match[1], req.URL.Query().Get("from"), req.URL.Query().Get("mount")
+		content, ok := r.blobs[from+"@"+mount]
+		if !ok {
+			w.Header().Set("Location", req.URL.Path+"fake-upload")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		r.blobs[repo+"@"+mount] = content
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	if req.URL.Path == "/v2/_catalog" && req.Method == "GET" {
+		var repos []string
+		for repo := range r.tags() {
+			repos = append(repos, repo)
+		}
+		sort.Strings(repos)
+		servePage(w, req, "repositories", repos)
+		return
+	}
+
+	if match := tagsPath.FindStringSubmatch(req.URL.Path); match != nil && req.Method == "GET" {
+		tags, ok := r.tags()[match[1]]
+		if !ok {
+			http.Error(w, `{"errors":[{"code":"NAME_UNKNOWN","message":"repository name not known to registry"}]}`, http.StatusNotFound)
+			return
+		}
+		servePage(w, req, "tags", tags)
+		return
+	}
+
+	if match := blobPath.FindStringSubmatch(req.URL.Path); match != nil && req.Method == "GET" {
+		content, ok := r.blobs[match[1]+"@"+match[2]]
+		if !ok {
+			http.Error(w, `{"errors":[{"code":"BLOB_UNKNOWN","message":"blob unknown to registry"}]}`, http.StatusNotFound)
+			return
+		}
+		w.Write(content)
+		return
+	}
+
+	match := manifestPath.FindStringSubmatch(req.URL.Path)
+	if match == nil {
+		http.NotFound(w, req)
+		return
+	}
+	repo, ref := match[1], match[2]
+	switch req.Method {
+	case "GET":
+		m, ok := r.manifests[manifestKey(repo, ref)]
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`))
+			return
+		}
+		w.Header().Set("Content-Type", m.mediaType)
+		w.Write(m.raw)
+	case "PUT":
+		raw, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tag := ref
+		if _, err := digest.Parse(ref); err == nil {
+			if digest.FromBytes(raw).String() != ref {
+				http.Error(w, "digest mismatch", http.StatusBadRequest)
+				return
+			}
+			tag = ""
+		}
+		dgst := r.addManifest(repo, tag, req.Header.Get("Content-Type"), raw)
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
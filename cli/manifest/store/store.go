@@ -0,0 +1,128 @@
+package store
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+)
+
+// Store manages the local draft manifest lists and the image manifests they
+// reference
+type Store interface {
+	Remove(listRef reference.Reference) error
+	Get(listRef reference.Reference, manifest reference.Reference) (types.ImageManifest, error)
+	GetList(listRef reference.Reference) ([]types.ImageManifest, error)
+	Save(listRef reference.Reference, manifest reference.Reference, image types.ImageManifest) error
+}
+
+// fsStore stores each manifest list as a directory holding one file per
+// image manifest
+type fsStore struct {
+	root string
+}
+
+// NewStore returns a new store for a local file path
+func NewStore(root string) Store {
+	return &fsStore{root: root}
+}
+
+// Remove removes a manifest list from the store
+func (s *fsStore) Remove(listRef reference.Reference) error {
+	return os.RemoveAll(filepath.Join(s.root, makeFilesafeName(listRef.String())))
+}
+
+// Get returns an image manifest of a manifest list
+func (s *fsStore) Get(listRef reference.Reference, manifest reference.Reference) (types.ImageManifest, error) {
+	filename := manifestToFilename(s.root, listRef.String(), manifest.String())
+	return s.getFromFilename(manifest, filename)
+}
+
+func (s *fsStore) getFromFilename(ref reference.Reference, filename string) (types.ImageManifest, error) {
+	var image types.ImageManifest
+	content, err := ioutil.ReadFile(filename)
+	switch {
+	case os.IsNotExist(err):
+		return image, newNotFoundError(ref.String())
+	case err != nil:
+		return image, err
+	}
+	if err := json.Unmarshal(content, &image); err != nil {
+		return image, errors.Wrapf(err, "invalid manifest %s in the store", filename)
+	}
+	return image, nil
+}
+
+// GetList returns all the image manifests of a manifest list
+func (s *fsStore) GetList(listRef reference.Reference) ([]types.ImageManifest, error) {
+	dir := filepath.Join(s.root, makeFilesafeName(listRef.String()))
+	fileInfos, err := ioutil.ReadDir(dir)
+	switch {
+	case os.IsNotExist(err):
+		return nil, newNotFoundError(listRef.String())
+	case err != nil:
+		return nil, err
+	}
+
+	var images []types.ImageManifest
+	for _, info := range fileInfos {
+		image, err := s.getFromFilename(listRef, filepath.Join(dir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+// Save stores an image manifest of a manifest list, replacing any image
+// manifest with the same reference
+func (s *fsStore) Save(listRef reference.Reference, manifest reference.Reference, image types.ImageManifest) error {
+	if err := os.MkdirAll(filepath.Join(s.root, makeFilesafeName(listRef.String())), 0755); err != nil {
+		return err
+	}
+	content, err := json.Marshal(image)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(manifestToFilename(s.root, listRef.String(), manifest.String()), content, 0644)
+}
+
+func manifestToFilename(root, manifestList, manifest string) string {
+	return filepath.Join(root, makeFilesafeName(manifestList), makeFilesafeName(manifest))
+}
+
+func makeFilesafeName(ref string) string {
+	fileName := strings.Replace(ref, ":", "-", -1)
+	return strings.Replace(fileName, "/", "_", -1)
+}
+
+type notFoundError struct {
+	object string
+}
+
+func newNotFoundError(ref string) *notFoundError {
+	return &notFoundError{object: ref}
+}
+
+func (n *notFoundError) Error() string {
+	return "No such manifest: " + n.object
+}
+
+// NotFound interface
+func (n *notFoundError) NotFound() {}
+
+// IsNotFound returns true if the error is a not found error
+func IsNotFound(err error) bool {
+	_, ok := err.(notFound)
+	return ok
+}
+
+type notFound interface {
+	NotFound()
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/distribution/reference"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ref(t *testing.T, name string) reference.Named {
+	named, err := reference.ParseNormalizedNamed(name)
+	require.NoError(t, err)
+	return named
+}
+
+func TestStoreSaveGetRemove(t *testing.T) {
+	root, err := ioutil.TempDir("", "manifest-store")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	store := NewStore(root)
+
+	listRef := ref(t, "example.com/app:1.0")
+	amd64 := types.ImageManifest{Ref: "example.com/app-amd64@sha256:0123"}
+	arm64 := types.ImageManifest{Ref: "example.com/app-arm64@sha256:4567"}
+	require.NoError(t, store.Save(listRef, ref(t, "example.com/app-amd64:1.0"), amd64))
+	require.NoError(t, store.Save(listRef, ref(t, "example.com/app-arm64:1.0"), arm64))
+
+	image, err := store.Get(listRef, ref(t, "example.com/app-arm64:1.0"))
+	require.NoError(t, err)
+	assert.Equal(t, arm64, image)
+
+	images, err := store.GetList(listRef)
+	require.NoError(t, err)
+	assert.Equal(t, []types.ImageManifest{amd64, arm64}, images)
+
+	_, err = store.Get(listRef, ref(t, "example.com/app-s390x:1.0"))
+	assert.True(t, IsNotFound(err))
+
+	require.NoError(t, store.Remove(listRef))
+	_, err = store.GetList(listRef)
+	assert.True(t, IsNotFound(err))
+	assert.EqualError(t, err, "No such manifest: example.com/app:1.0")
+}
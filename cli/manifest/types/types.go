@@ -0,0 +1,25 @@
+package types
+
+import (
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/opencontainers/go-digest"
+)
+
+// Media types of the manifests of images that can be added to a manifest
+// list.
+const (
+	MediaTypeManifest    = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeOCIManifest = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex    = "application/vnd.oci.image.index.v1+json"
+)
+
+// ImageManifest is the manifest of an image of a manifest list.
+type ImageManifest struct {
+	// Ref is the reference of the image, by digest.
+	Ref        string
+	Descriptor manifestlist.ManifestDescriptor
+	// Blobs are the digests of the configuration and the layers of the
+	// image, which are mounted into the repository of the manifest list
+	// when it is pushed.
+	Blobs []digest.Digest
+}
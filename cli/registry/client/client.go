@@ -0,0 +1,425 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/distribution/registry/api/v2"
+	distclient "github.com/docker/distribution/registry/client"
+	"github.com/docker/distribution/registry/client/auth"
+	"github.com/docker/distribution/registry/client/transport"
+	apitypes "github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/registry"
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// Manifest is a manifest, or a manifest list, as stored in a registry.
+type Manifest struct {
+	MediaType string
+	Digest    digest.Digest
+	Raw       []byte
+}
+
+// RegistryClient is a client used to communicate with a Docker distribution
+// registry
+type RegistryClient interface {
+	GetManifest(ctx context.Context, ref reference.Named) (Manifest, error)
+	GetBlob(ctx context.Context, repo reference.Named, dgst digest.Digest) ([]byte, error)
+	PutManifest(ctx context.Context, ref reference.Named, manifest Manifest) (digest.Digest, error)
+	MountBlob(ctx context.Context, repo reference.Named, dgst digest.Digest, from reference.Named) error
+	GetRepositories(ctx context.Context, index *registrytypes.IndexInfo) ([]string, error)
+	GetTags(ctx context.Context, repo reference.Named) ([]string, error)
+}
+
+// AuthConfigResolver returns the credentials for a registry
+type AuthConfigResolver func(ctx context.Context, index *registrytypes.IndexInfo) apitypes.AuthConfig
+
+// NewRegistryClient returns a new RegistryClient with a resolver. If
+// insecure is set, registries are accessed over plain HTTP, or without
+// verifying their certificate, when HTTPS fails.
+func NewRegistryClient(resolver AuthConfigResolver, userAgent string, insecure bool) RegistryClient {
+	return &client{
+		authConfigResolver: resolver,
+		userAgent:          userAgent,
+		insecureRegistry:   insecure,
+	}
+}
+
+type client struct {
+	authConfigResolver AuthConfigResolver
+	userAgent          string
+	insecureRegistry   bool
+}
+
+var manifestMediaTypes = []string{
+	types.MediaTypeManifest,
+	types.MediaTypeOCIManifest,
+	manifestlist.MediaTypeManifestList,
+	types.MediaTypeOCIIndex,
+}
+
+// GetManifest returns the manifest, or the manifest list, of ref, which is
+// tagged or referenced by digest
+func (c *client) GetManifest(ctx context.Context, ref reference.Named) (Manifest, error) {
+	repo, err := c.repository(ctx, ref, "pull")
+	if err != nil {
+		return Manifest{}, err
+	}
+	manifestURL, err := repo.urls.BuildManifestURL(repo.localRef(ref))
+	if err != nil {
+		return Manifest{}, err
+	}
+	req, err := http.NewRequest("GET", manifestURL, nil)
+	if err != nil {
+		return Manifest{}, err
+	}
+	for _, mediaType := range manifestMediaTypes {
+		req.Header.Add("Accept", mediaType)
+	}
+	resp, err := repo.httpClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer resp.Body.Close()
+	if !distclient.SuccessStatus(resp.StatusCode) {
+		return Manifest{}, errors.Wrapf(distclient.HandleErrorResponse(resp), "failed to get manifest %s", reference.FamiliarString(ref))
+	}
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	manifest := Manifest{
+		MediaType: mediaType(resp.Header.Get("Content-Type")),
+		Digest:    digest.FromBytes(raw),
+		Raw:       raw,
+	}
+	if canonical, ok := ref.(reference.Canonical); ok && canonical.Digest() != manifest.Digest {
+		return Manifest{}, errors.Errorf("manifest %s does not match its digest", reference.FamiliarString(ref))
+	}
+	return manifest, nil
+}
+
+// GetBlob returns the content of a blob of repo, which is verified
+func (c *client) GetBlob(ctx context.Context, repo reference.Named, dgst digest.Digest) ([]byte, error) {
+	r, err := c.repository(ctx, repo, "pull")
+	if err != nil {
+		return nil, err
+	}
+	blobRef, err := reference.WithDigest(r.localRef(repo), dgst)
+	if err != nil {
+		return nil, err
+	}
+	blobURL, err := r.urls.BuildBlobURL(blobRef)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", blobURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.httpClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if !distclient.SuccessStatus(resp.StatusCode) {
+		return nil, errors.Wrapf(distclient.HandleErrorResponse(resp), "failed to get blob %s", dgst)
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if digest.FromBytes(content) != dgst {
+		return nil, errors.Errorf("blob %s does not match its digest", dgst)
+	}
+	return content, nil
+}
+
+// PutManifest pushes a manifest, or a manifest list, to ref, which is tagged
+// or referenced by digest, and returns its digest
+func (c *client) PutManifest(ctx context.Context, ref reference.Named, manifest Manifest) (digest.Digest, error) {
+	repo, err := c.repository(ctx, ref, "pull", "push")
+	if err != nil {
+		return "", err
+	}
+	manifestURL, err := repo.urls.BuildManifestURL(repo.localRef(ref))
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest("PUT", manifestURL, bytes.NewReader(manifest.Raw))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", manifest.MediaType)
+	resp, err := repo.httpClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if !distclient.SuccessStatus(resp.StatusCode) {
+		return "", errors.Wrapf(distclient.HandleErrorResponse(resp), "failed to put manifest %s", reference.FamiliarString(ref))
+	}
+	return digest.FromBytes(manifest.Raw), nil
+}
+
+// MountBlob mounts a blob of the repository from into repo, both being in
+// the same registry
+func (c *client) MountBlob(ctx context.Context, repo reference.Named, dgst digest.Digest, from reference.Named) error {
+	r, err := c.repository(ctx, repo, "pull", "push")
+	if err != nil {
+		return err
+	}
+	r.scopes = append(r.scopes, auth.RepositoryScope{Repository: reference.Path(from), Actions: []string{"pull"}})
+	values := url.Values{}
+	values.Set("mount", dgst.String())
+	values.Set("from", reference.Path(from))
+	uploadURL, err := r.urls.BuildBlobUploadURL(r.localRef(repo), values)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", uploadURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.httpClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return nil
+	case http.StatusAccepted:
+		// The registry started an upload instead of mounting the blob.
+		r.cancelUpload(ctx, resp.Header.Get("Location"))
+		return errors.Errorf("failed to mount blob %s from %s", dgst, reference.FamiliarName(from))
+	}
+	return errors.Wrapf(distclient.HandleErrorResponse(resp), "failed to mount blob %s from %s", dgst, reference.FamiliarName(from))
+}
+
+// GetRepositories returns the names of the repositories of a registry, from
+// its catalog
+func (c *client) GetRepositories(ctx context.Context, index *registrytypes.IndexInfo) ([]string, error) {
+	r, err := c.endpoint(ctx, index, []auth.Scope{auth.RegistryScope{Name: "catalog", Actions: []string{"*"}}})
+	if err != nil {
+		return nil, err
+	}
+	catalogURL, err := r.urls.BuildCatalogURL()
+	if err != nil {
+		return nil, err
+	}
+	var repositories []string
+	err = r.getPages(ctx, catalogURL, func(body io.Reader) error {
+		var page struct {
+			Repositories []string `json:"repositories"`
+		}
+		if err := json.NewDecoder(body).Decode(&page); err != nil {
+			return err
+		}
+		repositories = append(repositories, page.Repositories...)
+		return nil
+	})
+	return repositories, errors.Wrapf(err, "failed to list the repositories of %s", index.Name)
+}
+
+// GetTags returns the tags of a repository
+func (c *client) GetTags(ctx context.Context, repo reference.Named) ([]string, error) {
+	r, err := c.repository(ctx, repo, "pull")
+	if err != nil {
+		return nil, err
+	}
+	tagsURL, err := r.urls.BuildTagsURL(r.localRef(repo))
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	err = r.getPages(ctx, tagsURL, func(body io.Reader) error {
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		if err := json.NewDecoder(body).Decode(&page); err != nil {
+			return err
+		}
+		tags = append(tags, page.Tags...)
+		return nil
+	})
+	return tags, errors.Wrapf(err, "failed to list the tags of %s", reference.FamiliarName(repo))
+}
+
+// mediaType returns the media type of a Content-Type header, without its
+// parameters.
+func mediaType(contentType string) string {
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.TrimSpace(contentType)
+}
+
+// repositoryEndpoint is a repository of a registry, with a client
+// authenticated for some actions on it.
+type repositoryEndpoint struct {
+	base   *url.URL
+	urls   *v2.URLBuilder
+	scopes []auth.Scope
+	setup  func(scopes []auth.Scope) *http.Client
+}
+
+// localRef returns ref without the domain of the registry, as used in the
+// URLs of the registry API.
+func (r *repositoryEndpoint) localRef(ref reference.Named) reference.Named {
+	name, _ := reference.WithName(reference.Path(ref))
+	switch v := ref.(type) {
+	case reference.Canonical:
+		if canonical, err := reference.WithDigest(name, v.Digest()); err == nil {
+			return canonical
+		}
+	case reference.NamedTagged:
+		if tagged, err := reference.WithTag(name, v.Tag()); err == nil {
+			return tagged
+		}
+	}
+	return name
+}
+
+func (r *repositoryEndpoint) cancelUpload(ctx context.Context, location string) {
+	u, err := r.base.Parse(location)
+	if location == "" || err != nil {
+		return
+	}
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return
+	}
+	if resp, err := r.httpClient().Do(req.WithContext(ctx)); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// getPages gets the pages of a paginated list of the registry API, starting
+// from u, following the links to the next pages.
+func (r *repositoryEndpoint) getPages(ctx context.Context, u string, decode func(body io.Reader) error) error {
+	for u != "" {
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := r.httpClient().Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		if !distclient.SuccessStatus(resp.StatusCode) {
+			err = distclient.HandleErrorResponse(resp)
+		} else {
+			err = decode(resp.Body)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		u, err = r.nextPage(resp.Header.Get("Link"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextPage returns the URL of the next page from the Link header of a page,
+// or "" on the last page.
+func (r *repositoryEndpoint) nextPage(link string) (string, error) {
+	if !strings.Contains(link, `rel="next"`) {
+		return "", nil
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start < 0 || end < start {
+		return "", errors.Errorf("invalid Link header: %s", link)
+	}
+	next, err := r.base.Parse(link[start+1 : end])
+	if err != nil {
+		return "", err
+	}
+	return next.String(), nil
+}
+
+// httpClient returns a client authenticated for the current scopes.
+func (r *repositoryEndpoint) httpClient() *http.Client {
+	return r.setup(r.scopes)
+}
+
+func (c *client) repository(ctx context.Context, ref reference.Named, actions ...string) (*repositoryEndpoint, error) {
+	repoInfo, err := registry.ParseRepositoryInfo(ref)
+	if err != nil {
+		return nil, err
+	}
+	scopes := []auth.Scope{auth.RepositoryScope{
+		Repository: reference.Path(repoInfo.Name),
+		Actions:    actions,
+		Class:      repoInfo.Class,
+	}}
+	return c.endpoint(ctx, repoInfo.Index, scopes)
+}
+
+// endpoint returns an endpoint of a registry, with a client authenticated
+// for scopes.
+func (c *client) endpoint(ctx context.Context, index *registrytypes.IndexInfo, scopes []auth.Scope) (*repositoryEndpoint, error) {
+	insecure := c.insecureRegistry || !index.Secure
+
+	hostname := index.Name
+	base := &url.URL{Scheme: "https", Host: hostname}
+	if index.Official {
+		base = registry.DefaultV2Registry
+		hostname = base.Host
+	}
+
+	tlsConfig := tlsconfig.ClientDefault()
+	tlsConfig.InsecureSkipVerify = insecure
+	if err := registry.ReadCertsDirectory(tlsConfig, filepath.Join(registry.CertsDir, hostname)); err != nil {
+		return nil, err
+	}
+	baseTransport := registry.NewTransport(tlsConfig)
+	modifiers := registry.DockerHeaders(c.userAgent, http.Header{})
+
+	challengeManager, _, err := registry.PingV2Registry(base, transport.NewTransport(baseTransport, modifiers...))
+	if err != nil && insecure && base.Scheme == "https" {
+		base = &url.URL{Scheme: "http", Host: hostname}
+		challengeManager, _, err = registry.PingV2Registry(base, transport.NewTransport(baseTransport, modifiers...))
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to reach registry %s", hostname)
+	}
+
+	authConfig := c.authConfigResolver(ctx, index)
+	creds := registry.NewStaticCredentialStore(&authConfig)
+	setup := func(scopes []auth.Scope) *http.Client {
+		tokenHandler := auth.NewTokenHandlerWithOptions(auth.TokenHandlerOptions{
+			Transport:   transport.NewTransport(baseTransport, modifiers...),
+			Credentials: creds,
+			Scopes:      scopes,
+			ClientID:    registry.AuthClientID,
+		})
+		authorizer := auth.NewAuthorizer(challengeManager, tokenHandler, auth.NewBasicHandler(creds))
+		return registry.HTTPClient(transport.NewTransport(baseTransport, append(modifiers, authorizer)...))
+	}
+
+	return &repositoryEndpoint{
+		base:   base,
+		urls:   v2.NewURLBuilder(base, false),
+		scopes: scopes,
+		setup:  setup,
+	}, nil
+}
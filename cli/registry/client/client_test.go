@@ -0,0 +1,101 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/internal/test/registry"
+	"github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/distribution/reference"
+	apitypes "github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func newTestClient() RegistryClient {
+	resolver := func(ctx context.Context, index *registrytypes.IndexInfo) apitypes.AuthConfig {
+		return apitypes.AuthConfig{}
+	}
+	return NewRegistryClient(resolver, "test", false)
+}
+
+func parseRef(t *testing.T, ref string) reference.Named {
+	named, err := reference.ParseNormalizedNamed(ref)
+	require.NoError(t, err)
+	return named
+}
+
+func TestGetAndPutManifest(t *testing.T) {
+	fakeRegistry := registry.NewFakeRegistry()
+	defer fakeRegistry.Close()
+	raw := []byte(`{"schemaVersion":2}`)
+	fakeRegistry.AddManifest("app", "1.0", types.MediaTypeManifest, raw)
+
+	client := newTestClient()
+	ctx := context.Background()
+	manifest, err := client.GetManifest(ctx, parseRef(t, fakeRegistry.Host()+"/app:1.0"))
+	require.NoError(t, err)
+	assert.Equal(t, Manifest{MediaType: types.MediaTypeManifest, Digest: digest.FromBytes(raw), Raw: raw}, manifest)
+
+	// By digest
+	_, err = client.GetManifest(ctx, parseRef(t, fakeRegistry.Host()+"/app@"+digest.FromBytes(raw).String()))
+	require.NoError(t, err)
+
+	dgst, err := client.PutManifest(ctx, parseRef(t, fakeRegistry.Host()+"/other:latest"), manifest)
+	require.NoError(t, err)
+	assert.Equal(t, digest.FromBytes(raw), dgst)
+	mediaType, content, ok := fakeRegistry.Manifest("other", "latest")
+	require.True(t, ok)
+	assert.Equal(t, types.MediaTypeManifest, mediaType)
+	assert.Equal(t, raw, content)
+
+	_, err = client.GetManifest(ctx, parseRef(t, fakeRegistry.Host()+"/app:missing"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "manifest unknown")
+}
+
+func TestGetAndMountBlob(t *testing.T) {
+	fakeRegistry := registry.NewFakeRegistry()
+	defer fakeRegistry.Close()
+	dgst := fakeRegistry.AddBlob("app", []byte("config"))
+
+	client := newTestClient()
+	ctx := context.Background()
+	content, err := client.GetBlob(ctx, parseRef(t, fakeRegistry.Host()+"/app"), dgst)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("config"), content)
+
+	require.NoError(t, client.MountBlob(ctx, parseRef(t, fakeRegistry.Host()+"/other"), dgst, parseRef(t, fakeRegistry.Host()+"/app")))
+	assert.True(t, fakeRegistry.HasBlob("other", dgst))
+
+	err = client.MountBlob(ctx, parseRef(t, fakeRegistry.Host()+"/other"), digest.FromString("missing"), parseRef(t, fakeRegistry.Host()+"/app"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to mount blob")
+}
+
+func TestGetRepositoriesAndTags(t *testing.T) {
+	fakeRegistry := registry.NewFakeRegistry()
+	defer fakeRegistry.Close()
+	for _, ref := range []string{"app:1.0", "app:1.1", "app:latest", "db:9.6", "team/web:2"} {
+		i := strings.LastIndex(ref, ":")
+		fakeRegistry.AddManifest(ref[:i], ref[i+1:], types.MediaTypeManifest, []byte(`{"schemaVersion":2,"tag":"`+ref+`"}`))
+	}
+
+	client := newTestClient()
+	ctx := context.Background()
+	index := &registrytypes.IndexInfo{Name: fakeRegistry.Host()}
+	repositories, err := client.GetRepositories(ctx, index)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "db", "team/web"}, repositories)
+
+	tags, err := client.GetTags(ctx, parseRef(t, fakeRegistry.Host()+"/app"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0", "1.1", "latest"}, tags)
+
+	_, err = client.GetTags(ctx, parseRef(t, fakeRegistry.Host()+"/missing"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list the tags of "+fakeRegistry.Host()+"/missing")
+}